@@ -1,18 +1,25 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"fmt"
 	"log"
 	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"down-nexus-api/internal/api"
 	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/metrics"
 	"down-nexus-api/internal/models"
 	"down-nexus-api/pkg/clients"
 	"down-nexus-api/pkg/clients/qbittorrent"
 	"down-nexus-api/pkg/clients/transmission"
 	"down-nexus-api/pkg/database"
+	"down-nexus-api/pkg/secrets"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -51,24 +58,141 @@ func main() {
 	}
 	fmt.Println("   ✨ PostgreSQL 数据库连接成功")
 
+	// 加载客户端凭据的主密钥
+	secretCipher, err := loadCipher("MASTER_KEY", "MASTER_KEY_FILE")
+	if err != nil {
+		log.Fatalf("❌ 主密钥加载失败: %v", err)
+	}
+
+	// rotate-key 子命令：使用新主密钥重新加密所有客户端凭据后退出
+	if len(os.Args) > 1 && os.Args[1] == "rotate-key" {
+		if err := rotateKey(db, secretCipher); err != nil {
+			log.Fatalf("❌ 主密钥轮换失败: %v", err)
+		}
+		return
+	}
+
 	// 检查数据库配置
-	if err := checkDatabaseConfig(db); err != nil {
+	if err := checkDatabaseConfig(db, secretCipher); err != nil {
 		log.Fatalf("❌ 数据库配置检查失败: %v", err)
 	}
 
+	// 加密仍以明文存储的客户端凭据
+	if secretCipher == nil {
+		fmt.Println("   ⚠️  未设置 MASTER_KEY 或 MASTER_KEY_FILE，客户端凭据将以明文存储")
+	} else {
+		encrypted, err := database.ReencryptClientSecrets(db, secretCipher, secretCipher)
+		if err != nil {
+			log.Fatalf("❌ 客户端凭据加密失败: %v", err)
+		}
+		if encrypted > 0 {
+			fmt.Printf("   🔒 已加密 %d 个以明文存储的客户端凭据\n", encrypted)
+		}
+	}
+
+	// 初始化指标，单个种子的指标需显式开启
+	maxTorrentSeries, err := strconv.Atoi(getEnv("METRICS_MAX_TORRENT_SERIES", "500"))
+	if err != nil || maxTorrentSeries < 0 {
+		log.Fatalf("❌ METRICS_MAX_TORRENT_SERIES 配置无效: %s", getEnv("METRICS_MAX_TORRENT_SERIES", "500"))
+	}
+	m := metrics.New(metrics.Options{
+		PerTorrent:       getEnv("METRICS_PER_TORRENT", "false") == "true",
+		MaxTorrentSeries: maxTorrentSeries,
+	})
+
 	// 从数据库加载客户端配置
 	fmt.Println("🔧 正在从数据库加载客户端配置...")
-	adapters, err := loadClientsFromDB(db)
+	adapters, err := loadClientsFromDB(db, secretCipher, m)
 	if err != nil {
 		log.Fatalf("❌ 客户端加载失败: %v", err)
 	}
 
 	// 创建核心服务
 	torrentService := core.NewTorrentService(adapters, db)
+	m.RegisterTorrentCollector(torrentService)
+	torrentService.RestrictToOwnTorrents(getEnv("RESTRICT_TO_OWN_TORRENTS", "false") == "true")
+	switch policy := models.DuplicatePolicy(getEnv("DUPLICATE_POLICY", "warn")); policy {
+	case models.DuplicateAllow, models.DuplicateWarn, models.DuplicateRefuse:
+		torrentService.SetDuplicatePolicy(policy)
+	default:
+		log.Fatalf("❌ DUPLICATE_POLICY 配置无效: %s", policy)
+	}
+	torrentService.SetCrossSeedDir(getEnv("CROSS_SEED_DIR", ""))
 	fmt.Println("🎯 核心服务初始化完成")
 
+	// 启动种子列表后台同步
+	syncInterval, err := time.ParseDuration(getEnv("SYNC_INTERVAL", "2s"))
+	if err != nil || syncInterval <= 0 {
+		log.Fatalf("❌ SYNC_INTERVAL 配置无效: %s", getEnv("SYNC_INTERVAL", "2s"))
+	}
+	torrentService.StartSync(context.Background(), syncInterval)
+	fmt.Printf("🔄 种子同步已启动，间隔 %s\n", syncInterval)
+
+	// 启动带宽调度器
+	scheduler := core.NewScheduler(torrentService, db)
+	go scheduler.Run(context.Background())
+	fmt.Println("⏰ 带宽调度器已启动")
+
+	// 启动分享目标执行器
+	enforcer := core.NewShareLimitEnforcer(torrentService, db)
+	go enforcer.Run(context.Background())
+	fmt.Println("🎯 分享目标执行器已启动")
+
+	// 启动传输统计采集器
+	statsInterval, err := time.ParseDuration(getEnv("STATS_INTERVAL", "1m"))
+	if err != nil || statsInterval <= 0 {
+		log.Fatalf("❌ STATS_INTERVAL 配置无效: %s", getEnv("STATS_INTERVAL", "1m"))
+	}
+	collector := core.NewStatsCollector(torrentService, db, statsInterval)
+	go collector.Run(context.Background())
+	fmt.Printf("📈 传输统计采集器已启动，间隔 %s\n", statsInterval)
+
+	// 启动监视目录
+	watchPollInterval, err := time.ParseDuration(getEnv("WATCH_POLL_INTERVAL", "30s"))
+	if err != nil || watchPollInterval <= 0 {
+		log.Fatalf("❌ WATCH_POLL_INTERVAL 配置无效: %s", getEnv("WATCH_POLL_INTERVAL", "30s"))
+	}
+	watcher := core.NewFolderWatcher(torrentService, db, watchPollInterval)
+	go watcher.Run(context.Background())
+	fmt.Printf("📂 监视目录已启动，轮询间隔 %s\n", watchPollInterval)
+
+	// 启动 RSS 订阅抓取器
+	rssInterval, err := time.ParseDuration(getEnv("RSS_INTERVAL", "15m"))
+	if err != nil || rssInterval <= 0 {
+		log.Fatalf("❌ RSS_INTERVAL 配置无效: %s", getEnv("RSS_INTERVAL", "15m"))
+	}
+	rssPoller := core.NewRSSPoller(torrentService, db, nil, rssInterval)
+	go rssPoller.Run(context.Background())
+	fmt.Printf("📡 RSS 订阅抓取器已启动，默认间隔 %s\n", rssInterval)
+
+	// 启动种子整理规则引擎
+	housekeepingInterval, err := time.ParseDuration(getEnv("HOUSEKEEPING_INTERVAL", "5m"))
+	if err != nil || housekeepingInterval <= 0 {
+		log.Fatalf("❌ HOUSEKEEPING_INTERVAL 配置无效: %s", getEnv("HOUSEKEEPING_INTERVAL", "5m"))
+	}
+	housekeeping := core.NewHousekeepingEngine(torrentService, db, housekeepingInterval)
+	go housekeeping.Run(context.Background())
+	fmt.Printf("🧹 种子整理规则引擎已启动，间隔 %s\n", housekeepingInterval)
+
+	// 初始化认证服务
+	authService, err := newAuthService(db)
+	if err != nil {
+		log.Fatalf("❌ 认证服务初始化失败: %v", err)
+	}
+	fmt.Println("🔐 认证服务初始化完成")
+
 	// 设置路由器
-	router := api.SetupRouter(torrentService)
+	qbitOptions, err := qbitFacadeOptions()
+	if err != nil {
+		log.Fatalf("❌ qBittorrent 兼容接口配置无效: %v", err)
+	}
+	facades := api.FacadeOptions{
+		Qbit: qbitOptions,
+		Transmission: api.TransmissionFacadeOptions{
+			DefaultClient: getEnv("TRANSMISSION_FACADE_DEFAULT_CLIENT", ""),
+		},
+	}
+	router := api.SetupRouter(torrentService, authService, scheduler, enforcer, collector, watcher, rssPoller, housekeeping, m, facades)
 	fmt.Println("🌐 API 路由配置完成")
 
 	// 启动服务器
@@ -85,7 +209,8 @@ func main() {
 }
 
 // checkDatabaseConfig 检查数据库配置
-func checkDatabaseConfig(db *gorm.DB) error {
+// 创建默认配置时使用 secretCipher 加密密码
+func checkDatabaseConfig(db *gorm.DB, secretCipher *secrets.Cipher) error {
 	var count int64
 	db.Model(&models.ClientConfig{}).Count(&count)
 	
@@ -113,6 +238,11 @@ func checkDatabaseConfig(db *gorm.DB) error {
 		}
 
 		for _, config := range defaultConfigs {
+			password, err := secretCipher.Encrypt(config.Password, config.ClientID)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt password for %s: %w", config.ClientID, err)
+			}
+			config.Password = password
 			if err := db.Create(&config).Error; err != nil {
 				return fmt.Errorf("failed to create default config %s: %w", config.ClientID, err)
 			}
@@ -128,8 +258,97 @@ func checkDatabaseConfig(db *gorm.DB) error {
 	return nil
 }
 
+// loadCipher 从环境变量 keyEnv 或 fileEnv 指定的文件加载主密钥，均未设置时返回 nil
+func loadCipher(keyEnv, fileEnv string) (*secrets.Cipher, error) {
+	key, err := secrets.LoadKey(getEnv(keyEnv, ""), getEnv(fileEnv, ""))
+	if err != nil || key == nil {
+		return nil, err
+	}
+	return secrets.NewCipher(key)
+}
+
+// rotateKey 使用 NEW_MASTER_KEY（或 NEW_MASTER_KEY_FILE）重新加密所有客户端凭据
+// 当前主密钥为空时视为凭据均为明文，完成后需将 MASTER_KEY 替换为新密钥
+func rotateKey(db *gorm.DB, current *secrets.Cipher) error {
+	next, err := loadCipher("NEW_MASTER_KEY", "NEW_MASTER_KEY_FILE")
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		return fmt.Errorf("NEW_MASTER_KEY or NEW_MASTER_KEY_FILE is required")
+	}
+
+	updated, err := database.ReencryptClientSecrets(db, current, next)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🔑 已使用新主密钥重新加密 %d 个客户端凭据\n", updated)
+	fmt.Println("   💡 请将 MASTER_KEY 更新为新密钥后重启服务")
+	return nil
+}
+
+// qbitFacadeOptions 从环境变量读取 qBittorrent 兼容接口的目标客户端路由
+// QBIT_FACADE_CATEGORY_CLIENTS 格式为 category=clientID，多个以逗号分隔
+func qbitFacadeOptions() (api.QbitFacadeOptions, error) {
+	options := api.QbitFacadeOptions{
+		DefaultClient:   getEnv("QBIT_FACADE_DEFAULT_CLIENT", ""),
+		CategoryClients: make(map[string]string),
+	}
+
+	for _, route := range strings.Split(getEnv("QBIT_FACADE_CATEGORY_CLIENTS", ""), ",") {
+		route = strings.TrimSpace(route)
+		if route == "" {
+			continue
+		}
+		category, clientID, ok := strings.Cut(route, "=")
+		category, clientID = strings.TrimSpace(category), strings.TrimSpace(clientID)
+		if !ok || category == "" || clientID == "" {
+			return options, fmt.Errorf("invalid QBIT_FACADE_CATEGORY_CLIENTS entry: %q", route)
+		}
+		options.CategoryClients[category] = clientID
+	}
+	return options, nil
+}
+
+// newAuthService 根据环境变量创建认证服务，并在没有任何用户时创建初始管理员
+func newAuthService(db *gorm.DB) (*core.AuthService, error) {
+	secret := []byte(getEnv("JWT_SECRET", ""))
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		fmt.Println("   ⚠️  未设置 JWT_SECRET，已生成随机密钥，重启后已签发的令牌将失效")
+	}
+
+	accessTTL, err := time.ParseDuration(getEnv("JWT_ACCESS_TTL", "15m"))
+	if err != nil || accessTTL <= 0 {
+		return nil, fmt.Errorf("invalid JWT_ACCESS_TTL: %s", getEnv("JWT_ACCESS_TTL", "15m"))
+	}
+	refreshTTL, err := time.ParseDuration(getEnv("JWT_REFRESH_TTL", "168h"))
+	if err != nil || refreshTTL <= 0 {
+		return nil, fmt.Errorf("invalid JWT_REFRESH_TTL: %s", getEnv("JWT_REFRESH_TTL", "168h"))
+	}
+
+	authService := core.NewAuthService(db, secret, accessTTL, refreshTTL)
+
+	username := getEnv("ADMIN_USERNAME", "admin")
+	password, err := authService.EnsureAdmin(username, getEnv("ADMIN_PASSWORD", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admin user: %w", err)
+	}
+	if password != "" && getEnv("ADMIN_PASSWORD", "") == "" {
+		fmt.Printf("   ✨ 已创建管理员 %s，初始密码: %s\n", username, password)
+		fmt.Println("   💡 请登录后立即修改密码")
+	} else if password != "" {
+		fmt.Printf("   ✨ 已创建管理员 %s\n", username)
+	}
+	return authService, nil
+}
+
 // loadClientsFromDB 从数据库加载客户端配置并创建适配器
-func loadClientsFromDB(db *gorm.DB) ([]clients.DownloaderClient, error) {
+// 密码使用 secretCipher 解密，适配器经过指标包装，记录每次调用的耗时与错误
+func loadClientsFromDB(db *gorm.DB, secretCipher *secrets.Cipher, m *metrics.Metrics) ([]clients.DownloaderClient, error) {
 	var configs []models.ClientConfig
 	
 	// 查询所有启用的配置
@@ -142,13 +361,18 @@ func loadClientsFromDB(db *gorm.DB) ([]clients.DownloaderClient, error) {
 	// 遍历配置创建客户端适配器
 	for _, config := range configs {
 		var client clients.DownloaderClient
-		var err error
+
+		password, err := secretCipher.Decrypt(config.Password, config.ClientID)
+		if err != nil {
+			log.Printf("❌ 解密客户端凭据失败 [%s]: %v", config.ClientID, err)
+			continue
+		}
 		
 		switch config.Type {
 		case "qbittorrent":
-			client, err = qbittorrent.NewQbitClient(config.Host, config.Username, config.Password, config.ClientID)
+			client, err = qbittorrent.NewQbitClient(config.Host, config.Username, password, config.ClientID)
 		case "transmission":
-			client, err = transmission.NewTransmissionClient(config.Host, config.Username, config.Password, config.ClientID)
+			client, err = transmission.NewTransmissionClient(config.Host, config.Username, password, config.ClientID)
 		default:
 			log.Printf("⚠️  未知的客户端类型: %s (ID: %s)", config.Type, config.ClientID)
 			continue
@@ -159,7 +383,7 @@ func loadClientsFromDB(db *gorm.DB) ([]clients.DownloaderClient, error) {
 			continue
 		}
 		
-		adapters = append(adapters, client)
+		adapters = append(adapters, m.InstrumentClient(client, config.Type))
 		fmt.Printf("   ✨ %s (%s) 已连接\n", config.Type, config.ClientID)
 	}
 	
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultAuditLimit 查询审计日志默认返回的条数
+	defaultAuditLimit = 100
+	// maxAuditLimit 查询审计日志单页的最大条数，导出不受此限制
+	maxAuditLimit = 1000
+)
+
+// GetAuditLogs 查询审计日志的处理器
+// 支持 from/to（RFC3339）、user、action、clientID、hash、success 过滤，limit 与 offset 分页
+func (h *TorrentHandler) GetAuditLogs(c *gin.Context) {
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid query: " + err.Error(),
+		})
+		return
+	}
+
+	query.Limit = defaultAuditLimit
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > maxAuditLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid query: limit must be between 1 and " + strconv.Itoa(maxAuditLimit),
+			})
+			return
+		}
+		query.Limit = n
+	}
+	if offset := c.Query("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid query: offset must be a non-negative integer",
+			})
+			return
+		}
+		query.Offset = n
+	}
+
+	logs, total, err := h.scoped(c).ListAuditLogs(query)
+	if err != nil {
+		respondError(c, "Failed to get audit logs: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    logs,
+		"count":   len(logs),
+		"total":   total,
+	})
+}
+
+// ExportAuditLogs 导出审计日志的处理器，format 可选 csv（默认）或 json
+// 过滤条件与 GetAuditLogs 相同，导出所有符合条件的记录
+func (h *TorrentHandler) ExportAuditLogs(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid query: format must be csv or json",
+		})
+		return
+	}
+
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid query: " + err.Error(),
+		})
+		return
+	}
+
+	logs, _, err := h.scoped(c).ListAuditLogs(query)
+	if err != nil {
+		respondError(c, "Failed to export audit logs: ", err)
+		return
+	}
+
+	filename := "audit-" + time.Now().Format("20060102-150405") + "." + format
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	if format == "json" {
+		if logs == nil {
+			logs = []models.AuditLog{}
+		}
+		c.JSON(http.StatusOK, logs)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"id", "created_at", "user_id", "username", "source_ip", "action", "client_id", "hash", "params", "success", "error"})
+	for _, entry := range logs {
+		writer.Write([]string{
+			strconv.FormatUint(uint64(entry.ID), 10),
+			entry.CreatedAt.Format(time.RFC3339),
+			strconv.FormatUint(uint64(entry.UserID), 10),
+			entry.Username,
+			entry.SourceIP,
+			entry.Action,
+			entry.ClientID,
+			entry.Hash,
+			entry.Params,
+			strconv.FormatBool(entry.Success),
+			entry.Error,
+		})
+	}
+	writer.Flush()
+}
+
+// parseAuditQuery 解析审计日志的过滤条件
+func parseAuditQuery(c *gin.Context) (core.AuditQuery, error) {
+	query := core.AuditQuery{
+		Username: c.Query("user"),
+		Action:   c.Query("action"),
+		ClientID: c.Query("clientID"),
+		Hash:     c.Query("hash"),
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return query, errors.New("from must be an RFC3339 time")
+		}
+		query.From = t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return query, errors.New("to must be an RFC3339 time")
+		}
+		query.To = t
+	}
+	if success := c.Query("success"); success != "" {
+		b, err := strconv.ParseBool(success)
+		if err != nil {
+			return query, errors.New("success must be true or false")
+		}
+		query.Success = &b
+	}
+
+	return query, nil
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestExportAuditLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	core.Audit(db, nil, models.AuditActionPause, "qb-1", "ABC", nil, nil)
+	core.Audit(db, nil, models.AuditActionDelete, "qb-1", "abc", map[string]interface{}{"deleteFiles": true}, errors.New("failed, \"quoted\""))
+
+	handler := NewTorrentHandler(core.NewTorrentService(nil, db))
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set(accessContextKey, (*core.Access)(nil)) })
+	router.GET("/audit/export", handler.ExportAuditLogs)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantRows   int
+	}{
+		{"csv by default", "", http.StatusOK, 2},
+		{"json", "?format=json", http.StatusOK, 2},
+		{"filtered", "?format=json&success=false", http.StatusOK, 1},
+		{"unknown format", "?format=xml", http.StatusBadRequest, 0},
+		{"invalid time", "?from=yesterday", http.StatusBadRequest, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/audit/export"+test.query, nil))
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, test.wantStatus, recorder.Body)
+			}
+			if test.wantStatus != http.StatusOK {
+				return
+			}
+
+			var rows int
+			if strings.Contains(test.query, "json") {
+				var logs []models.AuditLog
+				if err := json.Unmarshal(recorder.Body.Bytes(), &logs); err != nil {
+					t.Fatalf("decode json: %v", err)
+				}
+				rows = len(logs)
+			} else {
+				records, err := csv.NewReader(recorder.Body).ReadAll()
+				if err != nil {
+					t.Fatalf("decode csv: %v", err)
+				}
+				if records[0][0] != "id" || records[1][5] != models.AuditActionDelete || records[1][10] != `failed, "quoted"` {
+					t.Errorf("csv = %v", records)
+				}
+				rows = len(records) - 1
+			}
+			if rows != test.wantRows {
+				t.Errorf("rows = %d, want %d", rows, test.wantRows)
+			}
+			if disposition := recorder.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, "attachment;") {
+				t.Errorf("Content-Disposition = %q, want attachment", disposition)
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// 当前用户、访问权限及查询参数中的访问令牌在请求上下文中的键
+const (
+	userContextKey       = "user"
+	accessContextKey     = "access"
+	queryTokenContextKey = "queryToken"
+)
+
+type AuthHandler struct {
+	auth *core.AuthService
+}
+
+func NewAuthHandler(auth *core.AuthService) *AuthHandler {
+	return &AuthHandler{
+		auth: auth,
+	}
+}
+
+// LoginRequest 登录请求结构
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest 刷新或注销请求结构
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// ChangePasswordRequest 修改密码请求结构
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+// CreateAPIKeyRequest 创建 API 密钥请求结构，expiresAt 为空表示永不过期
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// RequireAuth 认证中间件
+// 支持 Authorization: Bearer <token> 与 X-API-Key 请求头；
+// 浏览器的 EventSource 与 WebSocket 无法设置请求头，订阅接口额外接受 access_token 查询参数，
+// 查询参数可能被代理或浏览器记录，因此只接受短期访问令牌，不接受 API 密钥
+func (h *AuthHandler) RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c)
+		authenticate := h.auth.Authenticate
+		if token == "" && (strings.HasSuffix(c.FullPath(), "/stream") || strings.HasSuffix(c.FullPath(), "/ws")) {
+			token = c.GetString(queryTokenContextKey)
+			authenticate = h.auth.AuthenticateAccessToken
+		}
+		if token == "" {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Unauthorized: missing access token or API key",
+			})
+			return
+		}
+
+		user, err := authenticate(token)
+		if err != nil {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(errorStatus(err), gin.H{
+				"success": false,
+				"error":   "Unauthorized: " + err.Error(),
+			})
+			return
+		}
+
+		setCurrentUser(c, user)
+		c.Next()
+	}
+}
+
+// bearerToken 从请求头中提取访问令牌或 API 密钥
+func bearerToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return c.GetHeader("X-API-Key")
+}
+
+// RedactQueryToken 将 access_token 查询参数移入请求上下文，并在 URL 中替换为 REDACTED，
+// 避免令牌写入访问日志，需在 Logger 之前使用
+func RedactQueryToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.Contains(c.Request.URL.RawQuery, "access_token") {
+			c.Next()
+			return
+		}
+
+		query := c.Request.URL.Query()
+		if token := query.Get("access_token"); token != "" {
+			c.Set(queryTokenContextKey, token)
+			query.Set("access_token", "REDACTED")
+			c.Request.URL.RawQuery = query.Encode()
+		}
+		c.Next()
+	}
+}
+
+// setCurrentUser 将认证通过的用户及其访问权限写入请求上下文
+func setCurrentUser(c *gin.Context, user *models.User) {
+	c.Set(userContextKey, user)
+	access := core.NewAccess(user)
+	access.SourceIP = c.ClientIP()
+	c.Set(accessContextKey, access)
+}
+
+// currentUser 获取认证中间件写入的当前用户
+func currentUser(c *gin.Context) *models.User {
+	user, _ := c.MustGet(userContextKey).(*models.User)
+	return user
+}
+
+// currentAccess 获取当前用户的访问权限
+func currentAccess(c *gin.Context) *core.Access {
+	access, _ := c.MustGet(accessContextKey).(*core.Access)
+	return access
+}
+
+// authorizeClient 检查当前用户能否在客户端上执行操作，无权限时直接写入 403 响应
+func authorizeClient(c *gin.Context, clientID, action string) bool {
+	if !currentAccess(c).Can(clientID, action) {
+		respondError(c, "Forbidden: ", &core.PermissionDeniedError{ClientID: clientID, Action: action})
+		return false
+	}
+	return true
+}
+
+// RequireAdmin 仅允许管理员访问的中间件，需在 RequireAuth 之后使用
+func (h *AuthHandler) RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !currentAccess(c).IsAdmin() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "Forbidden: admin role required",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Login 登录的处理器
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	tokens, err := h.auth.Login(req.Username, req.Password)
+	if err != nil {
+		respondError(c, "Failed to login: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tokens,
+	})
+}
+
+// Refresh 刷新令牌的处理器
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	tokens, err := h.auth.Refresh(req.RefreshToken)
+	if err != nil {
+		respondError(c, "Failed to refresh token: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tokens,
+	})
+}
+
+// Logout 注销的处理器，吊销刷新令牌
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.auth.Logout(req.RefreshToken); err != nil {
+		respondError(c, "Failed to logout: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Logged out successfully",
+	})
+}
+
+// Me 获取当前用户的处理器
+func (h *AuthHandler) Me(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    currentUser(c),
+	})
+}
+
+// ChangePassword 修改当前用户密码的处理器
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.auth.ChangePassword(currentAccess(c), currentUser(c).ID, req.OldPassword, req.NewPassword); err != nil {
+		respondError(c, "Failed to change password: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password changed successfully",
+	})
+}
+
+// ListAPIKeys 获取当前用户 API 密钥的处理器
+func (h *AuthHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.auth.ListAPIKeys(currentUser(c).ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get api keys: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    keys,
+		"count":   len(keys),
+	})
+}
+
+// CreateAPIKey 创建 API 密钥的处理器，明文密钥只在响应中返回一次
+func (h *AuthHandler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	key, apiKey, err := h.auth.CreateAPIKey(currentAccess(c), currentUser(c).ID, req.Name, req.ExpiresAt)
+	if err != nil {
+		respondError(c, "Failed to create api key: ", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "API key created successfully, store it now as it will not be shown again",
+		"key":     key,
+		"data":    apiKey,
+	})
+}
+
+// DeleteAPIKey 删除 API 密钥的处理器
+func (h *AuthHandler) DeleteAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid api key id: " + c.Param("id"),
+		})
+		return
+	}
+
+	if err := h.auth.DeleteAPIKey(currentAccess(c), currentUser(c).ID, uint(id)); err != nil {
+		respondError(c, "Failed to delete api key: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "API key deleted successfully",
+	})
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// newTestAuth 创建认证服务与一个管理员，返回访问令牌和 API 密钥
+func newTestAuth(t *testing.T) (*core.AuthService, string, string) {
+	t.Helper()
+	db := newTestDB(t)
+
+	auth := core.NewAuthService(db, []byte("secret"), time.Minute, time.Hour)
+	user, err := auth.CreateUser(nil, "alice", "correct horse", models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	pair, err := auth.Login("alice", "correct horse")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	key, _, err := auth.CreateAPIKey(nil, user.ID, "script", nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+	return auth, pair.AccessToken, key
+}
+
+func TestRequireAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auth, accessToken, apiKey := newTestAuth(t)
+
+	var loggedQuery string
+	router := gin.New()
+	router.Use(RedactQueryToken(), func(c *gin.Context) {
+		loggedQuery = c.Request.URL.RawQuery
+		c.Next()
+	})
+	protected := router.Group("/", NewAuthHandler(auth).RequireAuth())
+	protected.GET("/torrents", func(c *gin.Context) { c.Status(http.StatusOK) })
+	protected.GET("/torrents/stream", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{"bearer access token", "/torrents", "Authorization", "Bearer " + accessToken, http.StatusOK},
+		{"bearer api key", "/torrents", "Authorization", "Bearer " + apiKey, http.StatusOK},
+		{"x-api-key header", "/torrents", "X-API-Key", apiKey, http.StatusOK},
+		{"missing credentials", "/torrents", "", "", http.StatusUnauthorized},
+		{"invalid token", "/torrents", "Authorization", "Bearer invalid", http.StatusUnauthorized},
+		{"query token on stream", "/torrents/stream?access_token=" + accessToken, "", "", http.StatusOK},
+		{"query token on other routes", "/torrents?access_token=" + accessToken, "", "", http.StatusUnauthorized},
+		{"api key in query string", "/torrents/stream?access_token=" + apiKey, "", "", http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, test.path, nil)
+			if test.header != "" {
+				request.Header.Set(test.header, test.value)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+			if loggedQuery != "" && loggedQuery != "access_token=REDACTED" {
+				t.Errorf("logged query = %q, want access_token redacted", loggedQuery)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CategorySavePathRequest 分类在某个客户端上的默认保存路径
+type CategorySavePathRequest struct {
+	ClientID string `json:"clientID" binding:"required"`
+	SavePath string `json:"savePath" binding:"required"`
+}
+
+// CreateCategoryRequest 创建分类的请求结构
+type CreateCategoryRequest struct {
+	Name      string                    `json:"name" binding:"required"`
+	SavePaths []CategorySavePathRequest `json:"savePaths" binding:"dive"`
+}
+
+// UpdateCategoryRequest 更新分类保存路径的请求结构
+type UpdateCategoryRequest struct {
+	SavePaths []CategorySavePathRequest `json:"savePaths" binding:"dive"`
+}
+
+// AssignCategoryRequest 批量设置分类的请求结构，category 为空表示取消分类
+type AssignCategoryRequest struct {
+	Torrents []TorrentControlRequest `json:"torrents" binding:"required,min=1,dive"`
+	Category string                  `json:"category"`
+}
+
+// TagsRequest 批量添加或移除标签的请求结构
+type TagsRequest struct {
+	Torrents []TorrentControlRequest `json:"torrents" binding:"required,min=1,dive"`
+	Tags     []string                `json:"tags" binding:"required,min=1,dive,required"`
+}
+
+// ListCategories 获取所有分类的处理器
+func (h *TorrentHandler) ListCategories(c *gin.Context) {
+	categories, err := h.scoped(c).ListCategories()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get categories: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    categories,
+		"count":   len(categories),
+	})
+}
+
+// CreateCategory 创建分类的处理器
+func (h *TorrentHandler) CreateCategory(c *gin.Context) {
+	// 解析请求体
+	var req CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	if strings.ContainsAny(req.Name, ",/") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: category name must not contain ',' or '/'",
+		})
+		return
+	}
+
+	category := &models.Category{
+		Name:      req.Name,
+		SavePaths: toSavePaths(req.SavePaths),
+	}
+	if err := h.scoped(c).CreateCategory(category); err != nil {
+		respondError(c, "Failed to create category: ", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Category created successfully",
+		"data":    category,
+	})
+}
+
+// UpdateCategory 更新分类保存路径的处理器
+func (h *TorrentHandler) UpdateCategory(c *gin.Context) {
+	// 解析请求体
+	var req UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	category, err := h.scoped(c).UpdateCategory(c.Param("name"), toSavePaths(req.SavePaths))
+	if err != nil {
+		respondError(c, "Failed to update category: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Category updated successfully",
+		"data":    category,
+	})
+}
+
+// DeleteCategory 删除分类的处理器
+func (h *TorrentHandler) DeleteCategory(c *gin.Context) {
+	if err := h.scoped(c).DeleteCategory(c.Param("name")); err != nil {
+		respondError(c, "Failed to delete category: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Category deleted successfully",
+	})
+}
+
+// AssignCategory 批量设置种子分类的处理器
+func (h *TorrentHandler) AssignCategory(c *gin.Context) {
+	// 解析请求体
+	var req AssignCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.scoped(c).AssignCategory(toTorrentRefs(req.Torrents), req.Category); err != nil {
+		respondError(c, "Failed to assign category: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Category assigned successfully",
+	})
+}
+
+// AddTags 批量添加标签的处理器
+func (h *TorrentHandler) AddTags(c *gin.Context) {
+	h.updateTags(c, h.scoped(c).AddTags, "Tags added successfully", "Failed to add tags: ")
+}
+
+// RemoveTags 批量移除标签的处理器
+func (h *TorrentHandler) RemoveTags(c *gin.Context) {
+	h.updateTags(c, h.scoped(c).RemoveTags, "Tags removed successfully", "Failed to remove tags: ")
+}
+
+// updateTags 解析标签请求并执行添加或移除操作
+func (h *TorrentHandler) updateTags(c *gin.Context, action func([]models.TorrentRef, []string) error, message, errorPrefix string) {
+	// 解析请求体
+	var req TagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	// qBittorrent 以逗号分隔标签，标签内不能包含逗号
+	for _, tag := range req.Tags {
+		if strings.Contains(tag, ",") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid request format: tag must not contain ',': " + tag,
+			})
+			return
+		}
+	}
+
+	if err := action(toTorrentRefs(req.Torrents), req.Tags); err != nil {
+		respondError(c, errorPrefix, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+	})
+}
+
+func toSavePaths(reqs []CategorySavePathRequest) []models.CategorySavePath {
+	savePaths := make([]models.CategorySavePath, 0, len(reqs))
+	for _, req := range reqs {
+		savePaths = append(savePaths, models.CategorySavePath{
+			ClientID: req.ClientID,
+			SavePath: req.SavePath,
+		})
+	}
+	return savePaths
+}
+
+func toTorrentRefs(reqs []TorrentControlRequest) []models.TorrentRef {
+	refs := make([]models.TorrentRef, 0, len(reqs))
+	for _, req := range reqs {
+		refs = append(refs, models.TorrentRef{
+			ClientID: req.ClientID,
+			Hash:     req.Hash,
+		})
+	}
+	return refs
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// SelectionPolicyRequest 设置目标客户端选择策略的请求结构
+// strategy 为 round_robin、least_active、most_free_space、category 或 tracker；
+// category 与 tracker 策略按顺序匹配 rules，未命中时使用 fallback
+type SelectionPolicyRequest struct {
+	Strategy string                 `json:"strategy" binding:"required"`
+	Fallback string                 `json:"fallback"`
+	Rules    []SelectionRuleRequest `json:"rules"`
+}
+
+// SelectionRuleRequest 选择规则，match 为分类名称或 tracker 域名
+type SelectionRuleRequest struct {
+	Match    string `json:"match" binding:"required"`
+	ClientID string `json:"clientID" binding:"required"`
+}
+
+// GetSelectionPolicy 获取目标客户端选择策略的处理器
+func (h *TorrentHandler) GetSelectionPolicy(c *gin.Context) {
+	policy, err := h.scoped(c).GetSelectionPolicy()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get selection policy: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    policy,
+	})
+}
+
+// SetSelectionPolicy 替换目标客户端选择策略的处理器
+func (h *TorrentHandler) SetSelectionPolicy(c *gin.Context) {
+	var req SelectionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	policy := models.ClientSelectionPolicy{
+		Strategy: models.ClientSelectionStrategy(req.Strategy),
+		Fallback: models.ClientSelectionStrategy(req.Fallback),
+		Rules:    make([]models.ClientSelectionRule, 0, len(req.Rules)),
+	}
+	for _, rule := range req.Rules {
+		policy.Rules = append(policy.Rules, models.ClientSelectionRule{Match: rule.Match, ClientID: rule.ClientID})
+	}
+	if err := policy.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	policy, err := h.scoped(c).SetSelectionPolicy(policy)
+	if err != nil {
+		respondError(c, "Failed to set selection policy: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Selection policy updated successfully",
+		"data":    policy,
+	})
+}
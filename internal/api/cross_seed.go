@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CrossSeedRequest 辅种扫描的请求结构，请求体可省略
+type CrossSeedRequest struct {
+	DryRun bool `json:"dryRun"`
+}
+
+// CrossSeed 扫描辅种目录并注入匹配种子的处理器，返回每个文件的匹配与跳过原因
+func (h *TorrentHandler) CrossSeed(c *gin.Context) {
+	var req CrossSeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	report, err := h.scoped(c).CrossSeed(req.DryRun)
+	if err != nil {
+		respondError(c, "Failed to cross-seed: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"down-nexus-api/internal/core"
+	"github.com/gin-gonic/gin"
+)
+
+// errorStatus 将核心服务返回的错误映射为 HTTP 状态码
+func errorStatus(err error) int {
+	var clientNotFound *core.ClientNotFoundError
+	var ruleNotFound *core.ScheduleRuleNotFoundError
+	var categoryNotFound *core.CategoryNotFoundError
+	var categoryExists *core.CategoryExistsError
+	var policyNotFound *core.SharePolicyNotFoundError
+	var invalidPath *core.InvalidPathError
+	var unknownHash *core.UnknownHashError
+	var torrentDownload *core.TorrentDownloadError
+	var invalidCredentials *core.InvalidCredentialsError
+	var invalidPassword *core.InvalidPasswordError
+	var incorrectPassword *core.IncorrectPasswordError
+	var apiKeyNotFound *core.APIKeyNotFoundError
+	var userNotFound *core.UserNotFoundError
+	var invalidRole *core.InvalidRoleError
+	var lastAdmin *core.LastAdminError
+	var permissionDenied *core.PermissionDeniedError
+	var quotaExceeded *core.QuotaExceededError
+	var duplicateTorrent *core.DuplicateTorrentError
+	var invalidMigration *core.InvalidMigrationError
+	var migrationNotFound *core.MigrationNotFoundError
+	var crossSeedDisabled *core.CrossSeedDisabledError
+	var watchFolderNotFound *core.WatchFolderNotFoundError
+	var invalidWatchFolder *core.InvalidWatchFolderError
+	var rssFeedNotFound *core.RSSFeedNotFoundError
+	var rssRuleNotFound *core.RSSRuleNotFoundError
+	var invalidRSSFeed *core.InvalidRSSFeedError
+	var invalidRSSRule *core.InvalidRSSRuleError
+	var housekeepingRuleNotFound *core.HousekeepingRuleNotFoundError
+	var invalidHousekeepingRule *core.InvalidHousekeepingRuleError
+
+	switch {
+	case errors.As(err, &invalidPath),
+		errors.As(err, &unknownHash),
+		errors.As(err, &torrentDownload),
+		errors.As(err, &invalidPassword),
+		errors.As(err, &incorrectPassword),
+		errors.As(err, &invalidRole),
+		errors.As(err, &invalidMigration),
+		errors.As(err, &crossSeedDisabled),
+		errors.As(err, &invalidWatchFolder),
+		errors.As(err, &invalidRSSFeed),
+		errors.As(err, &invalidRSSRule),
+		errors.As(err, &invalidHousekeepingRule):
+		return http.StatusBadRequest
+	case errors.As(err, &invalidCredentials):
+		return http.StatusUnauthorized
+	case errors.As(err, &permissionDenied):
+		return http.StatusForbidden
+	case errors.As(err, &quotaExceeded):
+		return http.StatusTooManyRequests
+	case errors.As(err, &lastAdmin),
+		errors.As(err, &duplicateTorrent),
+		errors.As(err, &categoryExists):
+		return http.StatusConflict
+	case errors.As(err, &clientNotFound),
+		errors.As(err, &ruleNotFound),
+		errors.As(err, &categoryNotFound),
+		errors.As(err, &policyNotFound),
+		errors.As(err, &apiKeyNotFound),
+		errors.As(err, &userNotFound),
+		errors.As(err, &migrationNotFound),
+		errors.As(err, &watchFolderNotFound),
+		errors.As(err, &rssFeedNotFound),
+		errors.As(err, &rssRuleNotFound),
+		errors.As(err, &housekeepingRuleNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondError 根据错误类型返回对应状态码的错误响应
+func respondError(c *gin.Context, prefix string, err error) {
+	c.JSON(errorStatus(err), gin.H{
+		"success": false,
+		"error":   prefix + err.Error(),
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,10 +18,25 @@ func NewTorrentHandler(s *core.TorrentService) *TorrentHandler {
 	}
 }
 
+// scoped 返回按当前用户权限执行操作的服务视图
+func (h *TorrentHandler) scoped(c *gin.Context) *core.TorrentService {
+	return h.service.WithAccess(currentAccess(c))
+}
+
 // GetTorrents 获取所有种子的处理器
 func (h *TorrentHandler) GetTorrents(c *gin.Context) {
 	// 调用核心服务获取所有种子
-	torrents := h.service.GetAllTorrents()
+	torrents, freshness := h.scoped(c).GetTorrentsWithFreshness()
+
+	// 按查询参数过滤，与订阅接口使用相同的过滤条件
+	filter := parseTorrentFilter(c)
+	filtered := make([]models.UnifiedTorrent, 0, len(torrents))
+	for _, torrent := range torrents {
+		if filter.Match(torrent) {
+			filtered = append(filtered, torrent)
+		}
+	}
+	torrents = filtered
 
 	// 构建响应数据
 	response := gin.H{
@@ -28,15 +44,30 @@ func (h *TorrentHandler) GetTorrents(c *gin.Context) {
 		"data":    torrents,
 		"count":   len(torrents),
 	}
+	if freshness != nil {
+		response["clients"] = freshness
+	}
 
 	// 返回 JSON 响应
 	c.JSON(http.StatusOK, response)
 }
 
+// GetDuplicates 获取存在于多个客户端上的种子的处理器
+func (h *TorrentHandler) GetDuplicates(c *gin.Context) {
+	duplicates := h.scoped(c).ListDuplicates()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    duplicates,
+		"count":   len(duplicates),
+	})
+}
+
 // AddTorrentRequest 添加种子的请求结构
+// clientID 为空时按目标客户端选择策略自动选择；category 不为空时添加后设置分类，并用于按分类选择客户端
 type AddTorrentRequest struct {
 	MagnetURL string `json:"magnetURL" binding:"required"`
-	ClientID  string `json:"clientID" binding:"required"`
+	ClientID  string `json:"clientID"`
+	Category  string `json:"category"`
 }
 
 // AddTorrent 添加种子的处理器
@@ -51,27 +82,47 @@ func (h *TorrentHandler) AddTorrent(c *gin.Context) {
 		return
 	}
 
-	// 调用核心服务添加种子
-	err := h.service.AddTorrent(req.MagnetURL, req.ClientID)
+	service := h.scoped(c)
+
+	// 调用核心服务添加种子，未指定客户端时按选择策略选择
+	result, err := service.AddWithOptions(core.AddOptions{
+		Link:     req.MagnetURL,
+		ClientID: req.ClientID,
+		Category: req.Category,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to add torrent: " + err.Error(),
-		})
+		respondError(c, "Failed to add torrent: ", err)
 		return
 	}
+	clientID, hash := result.ClientID, result.Hash
 
-	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
+	// 设置分类失败不影响已添加的种子
+	response := gin.H{
 		"success": true,
 		"message": "Torrent added successfully",
-	})
+		"data": gin.H{
+			"clientID": clientID,
+			"hash":     hash,
+		},
+	}
+	if result.CategoryError != nil {
+		response["message"] = "Torrent added, but failed to assign category: " + result.CategoryError.Error()
+	}
+	if service.DuplicatePolicy() == models.DuplicateWarn {
+		if duplicates := service.FindDuplicates(hash, clientID); len(duplicates) > 0 {
+			response["warning"] = "Torrent already exists on other clients"
+			response["duplicates"] = duplicates
+		}
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, response)
 }
 
 // GetClients 获取所有客户端信息的处理器
 func (h *TorrentHandler) GetClients(c *gin.Context) {
 	// 直接从数据库获取客户端配置
-	clientConfigs, err := h.service.GetClientConfigs()
+	clientConfigs, err := h.scoped(c).GetClientConfigs()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -128,12 +179,9 @@ func (h *TorrentHandler) PauseTorrent(c *gin.Context) {
 	}
 
 	// 调用核心服务暂停种子
-	err := h.service.PauseTorrent(req.ClientID, req.Hash)
+	err := h.scoped(c).PauseTorrent(req.ClientID, req.Hash)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to pause torrent: " + err.Error(),
-		})
+		respondError(c, "Failed to pause torrent: ", err)
 		return
 	}
 
@@ -157,12 +205,9 @@ func (h *TorrentHandler) ResumeTorrent(c *gin.Context) {
 	}
 
 	// 调用核心服务恢复种子
-	err := h.service.ResumeTorrent(req.ClientID, req.Hash)
+	err := h.scoped(c).ResumeTorrent(req.ClientID, req.Hash)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to resume torrent: " + err.Error(),
-		})
+		respondError(c, "Failed to resume torrent: ", err)
 		return
 	}
 
@@ -186,12 +231,9 @@ func (h *TorrentHandler) DeleteTorrent(c *gin.Context) {
 	}
 
 	// 调用核心服务删除种子
-	err := h.service.DeleteTorrent(req.ClientID, req.Hash, req.DeleteFiles)
+	err := h.scoped(c).DeleteTorrent(req.ClientID, req.Hash, req.DeleteFiles)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to delete torrent: " + err.Error(),
-		})
+		respondError(c, "Failed to delete torrent: ", err)
 		return
 	}
 
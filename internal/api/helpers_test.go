@@ -0,0 +1,32 @@
+package api
+
+import (
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 创建迁移好全部模型的内存 SQLite 数据库
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	// 内存数据库按连接隔离，只使用一个连接
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	err = db.AutoMigrate(&models.User{}, &models.APIKey{}, &models.RefreshToken{}, &models.UserGrant{}, &models.AuditLog{})
+	if err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+	return db
+}
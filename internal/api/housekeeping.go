@@ -0,0 +1,285 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultExecutionLimit 查询执行历史时默认返回的条数
+const defaultExecutionLimit = 100
+
+type HousekeepingHandler struct {
+	engine *core.HousekeepingEngine
+}
+
+func NewHousekeepingHandler(e *core.HousekeepingEngine) *HousekeepingHandler {
+	return &HousekeepingHandler{
+		engine: e,
+	}
+}
+
+// HousekeepingRuleRequest 创建、更新或预演整理规则的请求结构
+// 条件字段为空表示不限制，至少需要一个条件；category 为空字符串表示未分类
+type HousekeepingRuleRequest struct {
+	Name           string                    `json:"name" binding:"required"`
+	ClientID       string                    `json:"clientID"`
+	MinRatio       *float64                  `json:"minRatio"`
+	MinSeedingTime *int64                    `json:"minSeedingTime"`
+	States         string                    `json:"states"`
+	Category       *string                   `json:"category"`
+	Tracker        string                    `json:"tracker"`
+	MinSize        *int64                    `json:"minSize"`
+	MaxSize        *int64                    `json:"maxSize"`
+	MinAge         *int64                    `json:"minAge"`
+	MaxFreeSpace   *int64                    `json:"maxFreeSpace"`
+	Action         models.HousekeepingAction `json:"action" binding:"required"`
+	Location       string                    `json:"location"`
+	TargetCategory string                    `json:"targetCategory"`
+	DownloadLimit  *int64                    `json:"downloadLimit"`
+	UploadLimit    *int64                    `json:"uploadLimit"`
+	WebhookURL     string                    `json:"webhookURL"`
+	DryRun         bool                      `json:"dryRun"`
+	Enabled        *bool                     `json:"enabled"`
+}
+
+// toModel 将请求转换为整理规则模型，未指定 enabled 时默认启用
+func (r *HousekeepingRuleRequest) toModel() *models.HousekeepingRule {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return &models.HousekeepingRule{
+		Name:           r.Name,
+		ClientID:       r.ClientID,
+		MinRatio:       r.MinRatio,
+		MinSeedingTime: r.MinSeedingTime,
+		States:         r.States,
+		Category:       r.Category,
+		Tracker:        r.Tracker,
+		MinSize:        r.MinSize,
+		MaxSize:        r.MaxSize,
+		MinAge:         r.MinAge,
+		MaxFreeSpace:   r.MaxFreeSpace,
+		Action:         r.Action,
+		Location:       r.Location,
+		TargetCategory: r.TargetCategory,
+		DownloadLimit:  r.DownloadLimit,
+		UploadLimit:    r.UploadLimit,
+		WebhookURL:     r.WebhookURL,
+		DryRun:         r.DryRun,
+		Enabled:        enabled,
+	}
+}
+
+// ListRules 获取所有整理规则的处理器
+func (h *HousekeepingHandler) ListRules(c *gin.Context) {
+	rules, err := h.engine.ListRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get housekeeping rules: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+		"count":   len(rules),
+	})
+}
+
+// GetRule 获取单条整理规则的处理器
+func (h *HousekeepingHandler) GetRule(c *gin.Context) {
+	id, ok := parseHousekeepingRuleID(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.engine.GetRule(id)
+	if err != nil {
+		respondError(c, "Failed to get housekeeping rule: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// CreateRule 创建整理规则的处理器
+func (h *HousekeepingHandler) CreateRule(c *gin.Context) {
+	var req HousekeepingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	rule := req.toModel()
+	if err := h.engine.CreateRule(currentAccess(c), rule); err != nil {
+		respondError(c, "Failed to create housekeeping rule: ", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Housekeeping rule created successfully",
+		"data":    rule,
+	})
+}
+
+// UpdateRule 更新整理规则的处理器
+func (h *HousekeepingHandler) UpdateRule(c *gin.Context) {
+	id, ok := parseHousekeepingRuleID(c)
+	if !ok {
+		return
+	}
+
+	var req HousekeepingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	rule := req.toModel()
+	if err := h.engine.UpdateRule(currentAccess(c), id, rule); err != nil {
+		respondError(c, "Failed to update housekeeping rule: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Housekeeping rule updated successfully",
+		"data":    rule,
+	})
+}
+
+// DeleteRule 删除整理规则的处理器
+func (h *HousekeepingHandler) DeleteRule(c *gin.Context) {
+	id, ok := parseHousekeepingRuleID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.engine.DeleteRule(currentAccess(c), id); err != nil {
+		respondError(c, "Failed to delete housekeeping rule: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Housekeeping rule deleted successfully",
+	})
+}
+
+// PreviewRule 预演已保存规则的处理器，返回当前会被执行动作的种子
+func (h *HousekeepingHandler) PreviewRule(c *gin.Context) {
+	id, ok := parseHousekeepingRuleID(c)
+	if !ok {
+		return
+	}
+
+	matches, err := h.engine.Preview(id)
+	if err != nil {
+		respondError(c, "Failed to preview housekeeping rule: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    matches,
+		"count":   len(matches),
+	})
+}
+
+// PreviewDraft 预演未保存规则的处理器，请求体与创建规则相同
+func (h *HousekeepingHandler) PreviewDraft(c *gin.Context) {
+	var req HousekeepingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	matches, err := h.engine.PreviewRule(req.toModel())
+	if err != nil {
+		respondError(c, "Failed to preview housekeeping rule: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    matches,
+		"count":   len(matches),
+	})
+}
+
+// ListExecutions 获取执行历史的处理器，可按 ruleID 过滤，limit 默认 100
+func (h *HousekeepingHandler) ListExecutions(c *gin.Context) {
+	var ruleID uint
+	if value := c.Query("ruleID"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid ruleID: " + value,
+			})
+			return
+		}
+		ruleID = uint(parsed)
+	}
+
+	limit := defaultExecutionLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid limit: " + value,
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	executions, err := h.engine.ListExecutions(ruleID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get housekeeping history: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    executions,
+		"count":   len(executions),
+	})
+}
+
+// parseHousekeepingRuleID 解析路径中的整理规则 ID，失败时直接写入 400 响应
+func parseHousekeepingRuleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid housekeeping rule id: " + c.Param("id"),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
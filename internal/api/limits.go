@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateLimitsRequest 更新客户端全局速度限制的请求结构
+// 未提供的字段保持客户端当前值不变
+type UpdateLimitsRequest struct {
+	DownloadLimit   *int64 `json:"downloadLimit"`
+	UploadLimit     *int64 `json:"uploadLimit"`
+	AltSpeedEnabled *bool  `json:"altSpeedEnabled"`
+}
+
+// TorrentLimitsRequest 设置单个种子速度限制的请求结构
+type TorrentLimitsRequest struct {
+	DownloadLimit int64 `json:"downloadLimit"`
+	UploadLimit   int64 `json:"uploadLimit"`
+}
+
+// GetClientLimits 获取客户端全局速度限制的处理器
+func (h *TorrentHandler) GetClientLimits(c *gin.Context) {
+	limits, err := h.scoped(c).GetClientLimits(c.Param("id"))
+	if err != nil {
+		respondError(c, "Failed to get client limits: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    limits,
+	})
+}
+
+// SetClientLimits 设置客户端全局速度限制的处理器
+func (h *TorrentHandler) SetClientLimits(c *gin.Context) {
+	// 解析请求体
+	var req UpdateLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	if (req.DownloadLimit != nil && *req.DownloadLimit < 0) || (req.UploadLimit != nil && *req.UploadLimit < 0) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: limits must not be negative",
+		})
+		return
+	}
+
+	clientID := c.Param("id")
+
+	// 以客户端当前值为基础合并请求中的字段
+	limits, err := h.scoped(c).GetClientLimits(clientID)
+	if err != nil {
+		respondError(c, "Failed to get client limits: ", err)
+		return
+	}
+	if req.DownloadLimit != nil {
+		limits.DownloadLimit = *req.DownloadLimit
+	}
+	if req.UploadLimit != nil {
+		limits.UploadLimit = *req.UploadLimit
+	}
+	if req.AltSpeedEnabled != nil {
+		limits.AltSpeedEnabled = *req.AltSpeedEnabled
+	}
+
+	if err := h.scoped(c).SetClientLimits(clientID, limits); err != nil {
+		respondError(c, "Failed to set client limits: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Client limits updated successfully",
+		"data":    limits,
+	})
+}
+
+// SetTorrentLimits 设置单个种子速度限制的处理器
+func (h *TorrentHandler) SetTorrentLimits(c *gin.Context) {
+	// 解析请求体
+	var req TorrentLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	if req.DownloadLimit < 0 || req.UploadLimit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: limits must not be negative",
+		})
+		return
+	}
+
+	err := h.scoped(c).SetTorrentLimits(c.Param("clientID"), c.Param("hash"), req.DownloadLimit, req.UploadLimit)
+	if err != nil {
+		respondError(c, "Failed to set torrent limits: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Torrent limits updated successfully",
+	})
+}
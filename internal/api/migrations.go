@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"down-nexus-api/internal/core"
+	"github.com/gin-gonic/gin"
+)
+
+// MigrationRequest 迁移种子的请求结构
+// hashes 为空表示迁移源客户端上的全部种子；pathMap 将源客户端的保存路径前缀映射为目标客户端的路径；
+// verifyTimeout 为等待目标客户端开始做种的秒数，默认 1800
+type MigrationRequest struct {
+	SourceClient  string            `json:"sourceClient" binding:"required"`
+	TargetClient  string            `json:"targetClient" binding:"required"`
+	Hashes        []string          `json:"hashes"`
+	PathMap       map[string]string `json:"pathMap"`
+	SkipHashCheck bool              `json:"skipHashCheck"`
+	DryRun        bool              `json:"dryRun"`
+	VerifyTimeout int               `json:"verifyTimeout" binding:"min=0"`
+}
+
+// StartMigration 创建迁移任务的处理器，任务在后台执行，通过 GetMigration 查询进度
+func (h *TorrentHandler) StartMigration(c *gin.Context) {
+	var req MigrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	job, err := h.scoped(c).StartMigration(core.MigrationRequest{
+		SourceClient:  req.SourceClient,
+		TargetClient:  req.TargetClient,
+		Hashes:        req.Hashes,
+		PathMap:       req.PathMap,
+		SkipHashCheck: req.SkipHashCheck,
+		DryRun:        req.DryRun,
+		VerifyTimeout: time.Duration(req.VerifyTimeout) * time.Second,
+	})
+	if err != nil {
+		respondError(c, "Failed to start migration: ", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Migration started",
+		"data":    job,
+	})
+}
+
+// ListMigrations 获取迁移任务列表的处理器
+func (h *TorrentHandler) ListMigrations(c *gin.Context) {
+	jobs, err := h.scoped(c).ListMigrations()
+	if err != nil {
+		respondError(c, "Failed to list migrations: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    jobs,
+	})
+}
+
+// GetMigration 获取迁移任务及每个种子进度的处理器
+func (h *TorrentHandler) GetMigration(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid migration id: " + c.Param("id"),
+		})
+		return
+	}
+
+	job, err := h.scoped(c).GetMigration(uint(id))
+	if err != nil {
+		respondError(c, "Failed to get migration: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
@@ -0,0 +1,599 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// qbitSessionCookie qBittorrent Web API 的会话 Cookie 名称
+	qbitSessionCookie = "SID"
+	// 对外报告的 qBittorrent 与 Web API 版本
+	qbitVersion       = "v4.6.7"
+	qbitWebAPIVersion = "2.9.3"
+	// qbitInfiniteETA qBittorrent 表示无法估计剩余时间的 ETA
+	qbitInfiniteETA = 8640000
+	// maxTorrentFileSize 上传种子文件的大小上限
+	maxTorrentFileSize = 10 << 20
+)
+
+// QbitFacadeOptions qBittorrent 兼容接口添加种子时的目标客户端路由
+type QbitFacadeOptions struct {
+	// DefaultClient 未匹配分类路由时的目标客户端，为空时使用目标客户端选择策略
+	DefaultClient string
+	// CategoryClients 按分类指定目标客户端
+	CategoryClients map[string]string
+}
+
+// QbitFacadeHandler 实现 qBittorrent Web API（/api/v2）中 Sonarr、Radarr、autobrr 等工具使用的子集，
+// 请求转换为 TorrentService 调用，因此同样经过权限检查、配额与审计
+type QbitFacadeHandler struct {
+	service *core.TorrentService
+	auth    *core.AuthService
+	options QbitFacadeOptions
+}
+
+func NewQbitFacadeHandler(service *core.TorrentService, auth *core.AuthService, options QbitFacadeOptions) *QbitFacadeHandler {
+	return &QbitFacadeHandler{
+		service: service,
+		auth:    auth,
+		options: options,
+	}
+}
+
+// qbitTorrent qBittorrent torrents/info 返回的种子结构
+// save_path 与 content_path 为下载器上报的实际路径，下载器未上报时为空
+type qbitTorrent struct {
+	Hash             string  `json:"hash"`
+	Name             string  `json:"name"`
+	Size             int64   `json:"size"`
+	TotalSize        int64   `json:"total_size"`
+	Progress         float64 `json:"progress"`
+	DlSpeed          int64   `json:"dlspeed"`
+	UpSpeed          int64   `json:"upspeed"`
+	Downloaded       int64   `json:"downloaded"`
+	Uploaded         int64   `json:"uploaded"`
+	AmountLeft       int64   `json:"amount_left"`
+	Completed        int64   `json:"completed"`
+	ETA              int64   `json:"eta"`
+	State            string  `json:"state"`
+	Category         string  `json:"category"`
+	Tags             string  `json:"tags"`
+	Ratio            float64 `json:"ratio"`
+	RatioLimit       float64 `json:"ratio_limit"`
+	SeedingTime      int64   `json:"seeding_time"`
+	SeedingTimeLimit int64   `json:"seeding_time_limit"`
+	SavePath         string  `json:"save_path"`
+	ContentPath      string  `json:"content_path"`
+}
+
+// qbitCategory qBittorrent torrents/categories 返回的分类结构
+type qbitCategory struct {
+	Name     string `json:"name"`
+	SavePath string `json:"savePath"`
+}
+
+// RequireSession qBittorrent 会话认证中间件
+// 接受 auth/login 设置的 SID Cookie，也兼容 Authorization: Bearer 与 X-API-Key 请求头；
+// 未认证时与 qBittorrent 一样返回 403，客户端会据此重新登录
+func (h *QbitFacadeHandler) RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, _ := c.Cookie(qbitSessionCookie)
+		if token == "" {
+			token = bearerToken(c)
+		}
+		if token == "" {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		user, err := h.auth.Authenticate(token)
+		if err != nil {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		setCurrentUser(c, user)
+		c.Next()
+	}
+}
+
+// scoped 返回按当前用户权限执行操作的服务视图
+func (h *QbitFacadeHandler) scoped(c *gin.Context) *core.TorrentService {
+	return h.service.WithAccess(currentAccess(c))
+}
+
+// Login 会话登录，username/password 为 Down-Nexus 用户名与密码，密码也可以是该用户的 API 密钥
+func (h *QbitFacadeHandler) Login(c *gin.Context) {
+	token, expiresAt, err := h.auth.SessionToken(c.PostForm("username"), c.PostForm("password"))
+	if err != nil {
+		var invalid *core.InvalidCredentialsError
+		if errors.As(err, &invalid) {
+			c.String(http.StatusOK, "Fails.")
+			return
+		}
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(qbitSessionCookie, token, int(time.Until(expiresAt).Seconds()), "/", "", false, true)
+	c.String(http.StatusOK, "Ok.")
+}
+
+// Logout 注销会话，访问令牌无状态，只清除 Cookie
+func (h *QbitFacadeHandler) Logout(c *gin.Context) {
+	c.SetCookie(qbitSessionCookie, "", -1, "/", "", false, true)
+	c.Status(http.StatusOK)
+}
+
+// Version 返回兼容的 qBittorrent 版本
+func (h *QbitFacadeHandler) Version(c *gin.Context) {
+	c.String(http.StatusOK, qbitVersion)
+}
+
+// WebAPIVersion 返回兼容的 Web API 版本
+func (h *QbitFacadeHandler) WebAPIVersion(c *gin.Context) {
+	c.String(http.StatusOK, qbitWebAPIVersion)
+}
+
+// Preferences 返回 Sonarr、Radarr 检查的偏好设置，分享限制与队列由各客户端或 Down-Nexus 管理，此处均为关闭
+func (h *QbitFacadeHandler) Preferences(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"save_path":                "",
+		"max_ratio_enabled":        false,
+		"max_ratio":                -1,
+		"max_seeding_time_enabled": false,
+		"max_seeding_time":         -1,
+		"max_ratio_act":            0,
+		"queueing_enabled":         false,
+		"dht":                      true,
+	})
+}
+
+// TorrentsInfo 获取种子列表，支持 filter、category、tag、hashes、limit 与 offset 参数
+func (h *QbitFacadeHandler) TorrentsInfo(c *gin.Context) {
+	service := h.scoped(c)
+	torrents := service.GetAllTorrents()
+
+	filter := qbitParam(c, "filter")
+	category, filterCategory := qbitLookup(c, "category")
+	tag, filterTag := qbitLookup(c, "tag")
+	var hashes map[string]bool
+	if value := qbitParam(c, "hashes"); value != "" {
+		hashes = make(map[string]bool)
+		for _, hash := range strings.Split(value, "|") {
+			hashes[strings.ToLower(hash)] = true
+		}
+	}
+
+	result := make([]qbitTorrent, 0, len(torrents))
+	for _, torrent := range torrents {
+		if hashes != nil && !hashes[strings.ToLower(torrent.Hash)] {
+			continue
+		}
+		if filterCategory && torrent.Category != category {
+			continue
+		}
+		if filterTag && !qbitHasTag(torrent.Tags, tag) {
+			continue
+		}
+		if !qbitMatchFilter(torrent, filter) {
+			continue
+		}
+		result = append(result, toQbitTorrent(torrent))
+	}
+
+	offset := qbitIntParam(c, "offset")
+	if offset < 0 {
+		offset += len(result)
+	}
+	if offset > 0 {
+		if offset > len(result) {
+			offset = len(result)
+		}
+		result = result[offset:]
+	}
+	if limit := qbitIntParam(c, "limit"); limit > 0 && limit < len(result) {
+		result = result[:limit]
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// AddTorrents 添加种子，支持 urls（换行分隔的磁力链接或种子 URL）与 torrents（上传的种子文件），
+// 以及 category、tags、paused/stopped 参数；目标客户端按分类路由或默认客户端选择，均未配置时使用目标客户端选择策略
+func (h *QbitFacadeHandler) AddTorrents(c *gin.Context) {
+	service := h.scoped(c)
+	category := c.PostForm("category")
+	paused := c.PostForm("paused") == "true" || c.PostForm("stopped") == "true"
+	var tags []string
+	for _, tag := range strings.Split(c.PostForm("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	added := 0
+	var refs []models.TorrentRef
+	var lastErr error
+	add := func(options core.AddOptions) {
+		options.ClientID = h.routedClient(category)
+		options.Category = category
+		// 添加后还要设置标签或暂停时必须得知哈希
+		options.ResolveHash = len(tags) > 0 || paused
+		result, err := service.AddWithOptions(options)
+		if err == nil {
+			err = result.CategoryError
+		}
+		if err != nil {
+			lastErr = err
+			return
+		}
+		added++
+		if result.Hash != "" {
+			refs = append(refs, models.TorrentRef{ClientID: result.ClientID, Hash: result.Hash})
+		}
+	}
+
+	for _, url := range strings.Split(c.PostForm("urls"), "\n") {
+		if url = strings.TrimSpace(url); url != "" {
+			add(core.AddOptions{Link: url})
+		}
+	}
+
+	if form, err := c.MultipartForm(); err == nil {
+		for _, file := range form.File["torrents"] {
+			data, err := readTorrentFile(file.Open)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			add(core.AddOptions{Data: data})
+		}
+	}
+
+	// 添加后再设置标签与暂停状态，与 qBittorrent 一样，请求的选项未能全部生效时返回 Fails.
+	if len(refs) > 0 {
+		if len(tags) > 0 {
+			if err := service.AddTags(refs, tags); err != nil {
+				log.Printf("⚠️  qBittorrent 兼容接口添加标签失败: %v", err)
+				lastErr = err
+			}
+		}
+		if paused {
+			for _, ref := range refs {
+				if err := service.PauseTorrent(ref.ClientID, ref.Hash); err != nil {
+					log.Printf("⚠️  qBittorrent 兼容接口暂停种子失败 [%s]: %v", ref.ClientID, err)
+					lastErr = err
+				}
+			}
+		}
+	}
+
+	if added == 0 || lastErr != nil {
+		if lastErr == nil {
+			c.String(http.StatusBadRequest, "Fails.")
+			return
+		}
+		if status := errorStatus(lastErr); status == http.StatusForbidden || status == http.StatusTooManyRequests {
+			qbitError(c, lastErr)
+			return
+		}
+		c.String(http.StatusOK, "Fails.")
+		return
+	}
+
+	c.String(http.StatusOK, "Ok.")
+}
+
+// DeleteTorrents 删除种子，hashes 为 | 分隔的哈希或 all，deleteFiles 为 true 时同时删除文件
+func (h *QbitFacadeHandler) DeleteTorrents(c *gin.Context) {
+	deleteFiles := c.PostForm("deleteFiles") == "true"
+	h.forEachTorrent(c, func(service *core.TorrentService, ref models.TorrentRef) error {
+		return service.DeleteTorrent(ref.ClientID, ref.Hash, deleteFiles)
+	})
+}
+
+// PauseTorrents 暂停种子（qBittorrent 5.0 起为 torrents/stop）
+func (h *QbitFacadeHandler) PauseTorrents(c *gin.Context) {
+	h.forEachTorrent(c, func(service *core.TorrentService, ref models.TorrentRef) error {
+		return service.PauseTorrent(ref.ClientID, ref.Hash)
+	})
+}
+
+// ResumeTorrents 恢复种子（qBittorrent 5.0 起为 torrents/start）
+func (h *QbitFacadeHandler) ResumeTorrents(c *gin.Context) {
+	h.forEachTorrent(c, func(service *core.TorrentService, ref models.TorrentRef) error {
+		return service.ResumeTorrent(ref.ClientID, ref.Hash)
+	})
+}
+
+// Categories 获取所有分类，保存路径为该分类目标客户端上的默认保存路径
+func (h *QbitFacadeHandler) Categories(c *gin.Context) {
+	service := h.scoped(c)
+	categories, err := service.ListCategories()
+	if err != nil {
+		qbitError(c, err)
+		return
+	}
+
+	result := make(map[string]qbitCategory, len(categories))
+	for _, category := range categories {
+		savePath := ""
+		if clientID, err := h.targetClient(service, category.Name); err == nil {
+			savePath = category.SavePathFor(clientID)
+		}
+		result[category.Name] = qbitCategory{Name: category.Name, SavePath: savePath}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateCategory 创建分类，savePath 作为该分类目标客户端上的默认保存路径
+func (h *QbitFacadeHandler) CreateCategory(c *gin.Context) {
+	service := h.scoped(c)
+	name := strings.TrimSpace(c.PostForm("category"))
+	if name == "" {
+		c.String(http.StatusBadRequest, "Category name is empty")
+		return
+	}
+	if _, err := service.GetCategory(name); err == nil {
+		c.String(http.StatusConflict, "Category name already in use")
+		return
+	}
+
+	category := &models.Category{Name: name}
+	if savePath := strings.TrimSpace(c.PostForm("savePath")); savePath != "" {
+		clientID, err := h.targetClient(service, name)
+		if err != nil {
+			qbitError(c, err)
+			return
+		}
+		category.SavePaths = []models.CategorySavePath{{ClientID: clientID, SavePath: savePath}}
+	}
+
+	if err := service.CreateCategory(category); err != nil {
+		qbitError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// SetCategory 设置种子的分类，category 为空表示取消分类
+func (h *QbitFacadeHandler) SetCategory(c *gin.Context) {
+	service := h.scoped(c)
+	refs := h.resolveHashes(service, c.PostForm("hashes"))
+	if len(refs) == 0 {
+		c.Status(http.StatusOK)
+		return
+	}
+
+	if err := service.AssignCategory(refs, c.PostForm("category")); err != nil {
+		var notFound *core.CategoryNotFoundError
+		if errors.As(err, &notFound) {
+			c.String(http.StatusConflict, "Incorrect category name")
+			return
+		}
+		qbitError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// forEachTorrent 对 hashes 参数匹配的所有种子执行操作
+// 与 qBittorrent 一样忽略不存在的哈希，越权时返回 403
+func (h *QbitFacadeHandler) forEachTorrent(c *gin.Context, action func(*core.TorrentService, models.TorrentRef) error) {
+	service := h.scoped(c)
+	var lastErr error
+	for _, ref := range h.resolveHashes(service, c.PostForm("hashes")) {
+		if err := action(service, ref); err != nil {
+			lastErr = err
+		}
+	}
+
+	if lastErr != nil {
+		qbitError(c, lastErr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// resolveHashes 在所有可见客户端中查找 | 分隔的哈希，all 表示所有种子
+// 同一种子存在于多个客户端时全部返回
+func (h *QbitFacadeHandler) resolveHashes(service *core.TorrentService, value string) []models.TorrentRef {
+	if value == "" {
+		return nil
+	}
+	all := value == "all"
+	wanted := make(map[string]bool)
+	for _, hash := range strings.Split(value, "|") {
+		wanted[strings.ToLower(hash)] = true
+	}
+
+	var refs []models.TorrentRef
+	for _, torrent := range service.GetAllTorrents() {
+		if all || wanted[strings.ToLower(torrent.Hash)] {
+			refs = append(refs, models.TorrentRef{ClientID: torrent.ClientID, Hash: torrent.Hash})
+		}
+	}
+	return refs
+}
+
+// routedClient 返回配置的目标客户端：分类路由优先，其次为默认客户端，均未配置时返回空字符串
+func (h *QbitFacadeHandler) routedClient(category string) string {
+	if clientID, ok := h.options.CategoryClients[category]; ok && category != "" {
+		return clientID
+	}
+	return h.options.DefaultClient
+}
+
+// targetClient 返回分类保存路径所属的客户端：配置的目标客户端，未配置时为第一个有添加权限的客户端
+func (h *QbitFacadeHandler) targetClient(service *core.TorrentService, category string) (string, error) {
+	if clientID := h.routedClient(category); clientID != "" {
+		return clientID, nil
+	}
+
+	clientIDs := service.ClientIDs(models.ActionAdd)
+	if len(clientIDs) == 0 {
+		return "", &core.PermissionDeniedError{Action: models.ActionAdd}
+	}
+	return clientIDs[0], nil
+}
+
+// toQbitTorrent 将统一模型转换为 qBittorrent 的种子结构
+func toQbitTorrent(torrent models.UnifiedTorrent) qbitTorrent {
+	completed := int64(float64(torrent.Size) * torrent.Progress)
+	eta := torrent.ETA
+	if eta < 0 {
+		eta = qbitInfiniteETA
+	}
+
+	return qbitTorrent{
+		Hash:             torrent.Hash,
+		Name:             torrent.Name,
+		Size:             torrent.Size,
+		TotalSize:        torrent.Size,
+		Progress:         torrent.Progress,
+		DlSpeed:          torrent.DownloadSpeed,
+		UpSpeed:          torrent.UploadSpeed,
+		Downloaded:       torrent.Downloaded,
+		Uploaded:         torrent.Uploaded,
+		AmountLeft:       torrent.Size - completed,
+		Completed:        completed,
+		ETA:              eta,
+		State:            qbitState(torrent),
+		Category:         torrent.Category,
+		Tags:             strings.Join(torrent.Tags, ", "),
+		Ratio:            torrent.Ratio,
+		RatioLimit:       -2,
+		SeedingTime:      torrent.SeedingTime,
+		SeedingTimeLimit: -2,
+		SavePath:         torrent.SavePath,
+		ContentPath:      torrent.ContentPath,
+	}
+}
+
+// qbitState 将归一化状态映射为 qBittorrent 的状态名，按是否下载完成区分 DL/UP
+func qbitState(torrent models.UnifiedTorrent) string {
+	done := torrent.Progress >= 1
+	suffix := "DL"
+	if done {
+		suffix = "UP"
+	}
+
+	switch models.NormalizeState(torrent.State) {
+	case models.StateDownloading:
+		return "downloading"
+	case models.StateSeeding:
+		return "uploading"
+	case models.StatePaused:
+		return "paused" + suffix
+	case models.StateQueued:
+		return "queued" + suffix
+	case models.StateChecking:
+		return "checking" + suffix
+	case models.StateStalled:
+		return "stalled" + suffix
+	case models.StateError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// qbitMatchFilter 实现 torrents/info 的 filter 参数
+func qbitMatchFilter(torrent models.UnifiedTorrent, filter string) bool {
+	state := models.NormalizeState(torrent.State)
+	switch filter {
+	case "", "all":
+		return true
+	case "downloading":
+		return torrent.Progress < 1 && state != models.StatePaused
+	case "seeding":
+		return torrent.Progress >= 1 && state != models.StatePaused
+	case "completed":
+		return torrent.Progress >= 1
+	case "paused", "stopped":
+		return state == models.StatePaused
+	case "resumed", "running":
+		return state != models.StatePaused
+	case "active":
+		return torrent.DownloadSpeed > 0 || torrent.UploadSpeed > 0
+	case "inactive":
+		return torrent.DownloadSpeed == 0 && torrent.UploadSpeed == 0
+	case "stalled":
+		return state == models.StateStalled
+	case "errored":
+		return state == models.StateError
+	default:
+		return true
+	}
+}
+
+func qbitHasTag(tags []string, tag string) bool {
+	if tag == "" {
+		return len(tags) == 0
+	}
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// qbitLookup 读取表单或查询参数，qBittorrent 的读取接口同时接受 GET 与 POST
+func qbitLookup(c *gin.Context, key string) (string, bool) {
+	if value, ok := c.GetPostForm(key); ok {
+		return value, true
+	}
+	return c.GetQuery(key)
+}
+
+func qbitParam(c *gin.Context, key string) string {
+	value, _ := qbitLookup(c, key)
+	return value
+}
+
+func qbitIntParam(c *gin.Context, key string) int {
+	n, _ := strconv.Atoi(qbitParam(c, key))
+	return n
+}
+
+// readTorrentFile 读取上传的种子文件，超过大小上限时返回错误
+func readTorrentFile(open func() (multipart.File, error)) ([]byte, error) {
+	file, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxTorrentFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxTorrentFileSize {
+		return nil, errors.New("torrent file too large")
+	}
+	return data, nil
+}
+
+// qbitError 以 qBittorrent 的纯文本格式返回错误
+func qbitError(c *gin.Context, err error) {
+	status := errorStatus(err)
+	if status == http.StatusForbidden {
+		c.String(status, "Forbidden")
+		return
+	}
+	c.String(status, err.Error())
+}
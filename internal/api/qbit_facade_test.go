@@ -0,0 +1,138 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestQbitMatchFilter(t *testing.T) {
+	downloading := models.UnifiedTorrent{State: "downloading", Progress: 0.5, DownloadSpeed: 100}
+	seeding := models.UnifiedTorrent{State: "uploading", Progress: 1}
+	paused := models.UnifiedTorrent{State: "pausedDL", Progress: 0.2}
+	stalled := models.UnifiedTorrent{State: "stalledDL", Progress: 0.2}
+	errored := models.UnifiedTorrent{State: "error", Progress: 0.2}
+
+	tests := []struct {
+		filter  string
+		torrent models.UnifiedTorrent
+		want    bool
+	}{
+		{"", paused, true},
+		{"all", paused, true},
+		{"downloading", downloading, true},
+		{"downloading", paused, false},
+		{"downloading", seeding, false},
+		{"seeding", seeding, true},
+		{"seeding", downloading, false},
+		{"completed", seeding, true},
+		{"completed", downloading, false},
+		{"paused", paused, true},
+		{"stopped", paused, true},
+		{"paused", seeding, false},
+		{"resumed", seeding, true},
+		{"running", paused, false},
+		{"active", downloading, true},
+		{"active", seeding, false},
+		{"inactive", seeding, true},
+		{"stalled", stalled, true},
+		{"stalled", downloading, false},
+		{"errored", errored, true},
+		{"errored", paused, false},
+		{"unknown", paused, true},
+	}
+	for _, test := range tests {
+		t.Run(test.filter+"/"+test.torrent.State, func(t *testing.T) {
+			if got := qbitMatchFilter(test.torrent, test.filter); got != test.want {
+				t.Errorf("qbitMatchFilter(%s, %q) = %t, want %t", test.torrent.State, test.filter, got, test.want)
+			}
+		})
+	}
+}
+
+func TestQbitState(t *testing.T) {
+	tests := []struct {
+		state    string
+		progress float64
+		want     string
+	}{
+		{"downloading", 0.5, "downloading"},
+		{"uploading", 1, "uploading"},
+		{"pausedDL", 0.5, "pausedDL"},
+		{"pausedUP", 1, "pausedUP"},
+		{"queuedDL", 0, "queuedDL"},
+		{"checkingUP", 1, "checkingUP"},
+		{"stalledDL", 0.1, "stalledDL"},
+		{"error", 0.1, "error"},
+		{"something else", 0, "unknown"},
+	}
+	for _, test := range tests {
+		t.Run(test.state, func(t *testing.T) {
+			torrent := models.UnifiedTorrent{State: test.state, Progress: test.progress}
+			if got := qbitState(torrent); got != test.want {
+				t.Errorf("qbitState(%s) = %s, want %s", test.state, got, test.want)
+			}
+		})
+	}
+}
+
+func TestQbitFacadeSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auth, _, apiKey := newTestAuth(t)
+	handler := NewQbitFacadeHandler(core.NewTorrentService(nil, newTestDB(t)), auth, QbitFacadeOptions{})
+
+	router := gin.New()
+	router.POST("/auth/login", handler.Login)
+	router.GET("/torrents/info", handler.RequireSession(), handler.TorrentsInfo)
+
+	login := func(password string) *httptest.ResponseRecorder {
+		form := url.Values{"username": {"alice"}, "password": {password}}
+		request := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(form.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	if recorder := login("wrong"); recorder.Body.String() != "Fails." || len(recorder.Result().Cookies()) != 0 {
+		t.Errorf("login with wrong password = %q, want Fails. without cookie", recorder.Body)
+	}
+	var session *http.Cookie
+	for _, password := range []string{"correct horse", apiKey} {
+		recorder := login(password)
+		cookies := recorder.Result().Cookies()
+		if recorder.Body.String() != "Ok." || len(cookies) != 1 || cookies[0].Name != qbitSessionCookie {
+			t.Fatalf("login = %q with cookies %v, want Ok. with %s", recorder.Body, cookies, qbitSessionCookie)
+		}
+		session = cookies[0]
+	}
+
+	tests := []struct {
+		name       string
+		cookie     *http.Cookie
+		wantStatus int
+	}{
+		{"session cookie", session, http.StatusOK},
+		{"no session", nil, http.StatusForbidden},
+		{"invalid session", &http.Cookie{Name: qbitSessionCookie, Value: "invalid"}, http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/torrents/info", nil)
+			if test.cookie != nil {
+				request.AddCookie(test.cookie)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			if recorder.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaRequest 设置用户配额的请求结构，字段为空表示不限制
+// maxTotalSize 单位为字节，maxAddsPerDay 按过去 24 小时统计
+type QuotaRequest struct {
+	MaxActiveDownloads *int   `json:"maxActiveDownloads"`
+	MaxTotalSize       *int64 `json:"maxTotalSize"`
+	MaxAddsPerDay      *int   `json:"maxAddsPerDay"`
+}
+
+// GetMyQuota 获取当前用户配额与使用情况的处理器
+func (h *TorrentHandler) GetMyQuota(c *gin.Context) {
+	status, err := h.scoped(c).GetQuotaStatus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get quota: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// GetUserQuota 获取指定用户配额的处理器
+func (h *TorrentHandler) GetUserQuota(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	quota, err := h.scoped(c).GetQuota(id)
+	if err != nil {
+		respondError(c, "Failed to get quota: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    quota,
+	})
+}
+
+// SetUserQuota 设置指定用户配额的处理器
+func (h *TorrentHandler) SetUserQuota(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req QuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	quota := models.UserQuota{
+		MaxActiveDownloads: req.MaxActiveDownloads,
+		MaxTotalSize:       req.MaxTotalSize,
+		MaxAddsPerDay:      req.MaxAddsPerDay,
+	}
+	if err := quota.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	quota, err := h.scoped(c).SetQuota(id, quota)
+	if err != nil {
+		respondError(c, "Failed to set quota: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Quota updated successfully",
+		"data":    quota,
+	})
+}
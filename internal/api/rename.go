@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RenameTorrentRequest 重命名种子的请求结构
+type RenameTorrentRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// RenameFileRequest 重命名种子内文件或文件夹的请求结构
+// 路径相对于种子根目录，以 / 分隔
+type RenameFileRequest struct {
+	OldPath string `json:"oldPath" binding:"required"`
+	NewPath string `json:"newPath" binding:"required"`
+}
+
+// RenameTorrent 重命名种子的处理器
+func (h *TorrentHandler) RenameTorrent(c *gin.Context) {
+	// 解析请求体
+	var req RenameTorrentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.scoped(c).RenameTorrent(c.Param("clientID"), c.Param("hash"), req.Name); err != nil {
+		respondError(c, "Failed to rename torrent: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Torrent renamed successfully",
+	})
+}
+
+// RenameFile 重命名种子内文件或文件夹的处理器
+func (h *TorrentHandler) RenameFile(c *gin.Context) {
+	// 解析请求体
+	var req RenameFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.scoped(c).RenameFile(c.Param("clientID"), c.Param("hash"), req.OldPath, req.NewPath); err != nil {
+		respondError(c, "Failed to rename file: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "File renamed successfully",
+	})
+}
@@ -2,22 +2,44 @@ package api
 
 import (
 	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/metrics"
 	"github.com/gin-gonic/gin"
 )
 
+// FacadeOptions 兼容其他下载器协议的接口配置
+type FacadeOptions struct {
+	Qbit         QbitFacadeOptions
+	Transmission TransmissionFacadeOptions
+}
+
 // SetupRouter 设置路由器并返回 Gin 引擎
-func SetupRouter(service *core.TorrentService) *gin.Engine {
-	// 创建 Gin 路由器
-	router := gin.Default()
+func SetupRouter(service *core.TorrentService, auth *core.AuthService, scheduler *core.Scheduler, enforcer *core.ShareLimitEnforcer, collector *core.StatsCollector, watcher *core.FolderWatcher, poller *core.RSSPoller, housekeeping *core.HousekeepingEngine, m *metrics.Metrics, facades FacadeOptions) *gin.Engine {
+	// 创建 Gin 路由器，访问日志中的 access_token 查询参数会被替换为 REDACTED
+	router := gin.New()
+	router.Use(RedactQueryToken(), gin.Logger(), gin.Recovery())
 
 	// 创建处理器
 	handler := NewTorrentHandler(service)
+	scheduleHandler := NewScheduleHandler(scheduler)
+	shareLimitHandler := NewShareLimitHandler(enforcer, service)
+	statsHandler := NewStatsHandler(collector)
+	watchFolderHandler := NewWatchFolderHandler(watcher)
+	rssHandler := NewRSSHandler(poller)
+	housekeepingHandler := NewHousekeepingHandler(housekeeping)
+	authHandler := NewAuthHandler(auth)
+	userHandler := NewUserHandler(auth)
+	qbitHandler := NewQbitFacadeHandler(service, auth, facades.Qbit)
+	transmissionHandler := NewTransmissionFacadeHandler(service, auth, facades.Transmission)
+	requireAdmin := authHandler.RequireAdmin()
+
+	// 记录请求指标
+	router.Use(m.Middleware())
 
 	// 添加 CORS 中间件
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -27,26 +49,202 @@ func SetupRouter(service *core.TorrentService) *gin.Engine {
 		c.Next()
 	})
 
-	// API 路由组
-	v1 := router.Group("/api/v1")
+	// 登录与令牌刷新无需认证
+	login := router.Group("/api/v1/auth")
 	{
+		login.POST("/login", authHandler.Login)     // 登录
+		login.POST("/refresh", authHandler.Refresh) // 刷新令牌
+		login.POST("/logout", authHandler.Logout)   // 注销
+	}
+
+	// API 路由组，所有接口均需认证
+	v1 := router.Group("/api/v1", authHandler.RequireAuth())
+	{
+		// 当前用户与 API 密钥路由
+		account := v1.Group("/auth")
+		{
+			account.GET("/me", authHandler.Me)                      // 获取当前用户
+			account.PUT("/password", authHandler.ChangePassword)    // 修改密码
+			account.GET("/api-keys", authHandler.ListAPIKeys)       // 获取 API 密钥
+			account.POST("/api-keys", authHandler.CreateAPIKey)     // 创建 API 密钥
+			account.DELETE("/api-keys/:id", authHandler.DeleteAPIKey) // 删除 API 密钥
+		}
+
 		// 种子相关路由
 		torrents := v1.Group("/torrents")
 		{
 			torrents.GET("", handler.GetTorrents)           // 获取所有种子
+			torrents.GET("/stream", handler.StreamTorrentsSSE) // 通过 SSE 订阅种子更新
+			torrents.GET("/ws", handler.StreamTorrentsWS)      // 通过 WebSocket 订阅种子更新
+			torrents.GET("/duplicates", handler.GetDuplicates)  // 获取存在于多个客户端上的种子
 			torrents.POST("", handler.AddTorrent)            // 添加种子
 			torrents.POST("/pause", handler.PauseTorrent)    // 暂停种子
 			torrents.POST("/resume", handler.ResumeTorrent)   // 恢复种子
 			torrents.DELETE("", handler.DeleteTorrent)       // 删除种子
+			torrents.PUT("/:clientID/:hash/limits", handler.SetTorrentLimits) // 设置种子速度限制
+			torrents.POST("/category", handler.AssignCategory) // 批量设置分类
+			torrents.POST("/tags", handler.AddTags)             // 批量添加标签
+			torrents.DELETE("/tags", handler.RemoveTags)        // 批量移除标签
+			torrents.PUT("/:clientID/:hash/share-limits", shareLimitHandler.SetTorrentPolicy)      // 设置种子分享目标
+			torrents.DELETE("/:clientID/:hash/share-limits", shareLimitHandler.ClearTorrentPolicy) // 删除种子分享目标
+			torrents.POST("/:clientID/:hash/rename", handler.RenameTorrent)    // 重命名种子
+			torrents.POST("/:clientID/:hash/files/rename", handler.RenameFile) // 重命名种子内文件或文件夹
 		}
 
 		// 客户端相关路由
 		clients := v1.Group("/clients")
 		{
 			clients.GET("", handler.GetClients)              // 获取所有客户端
+			clients.GET("/:id/limits", handler.GetClientLimits) // 获取客户端速度限制
+			clients.PUT("/:id/limits", handler.SetClientLimits) // 设置客户端速度限制
+		}
+
+		// 分类相关路由
+		categories := v1.Group("/categories")
+		{
+			categories.GET("", handler.ListCategories)           // 获取所有分类
+			categories.POST("", requireAdmin, handler.CreateCategory)          // 创建分类
+			categories.PUT("/:name", requireAdmin, handler.UpdateCategory)     // 更新分类保存路径
+			categories.DELETE("/:name", requireAdmin, handler.DeleteCategory)  // 删除分类
+			categories.PUT("/:name/share-limits", requireAdmin, shareLimitHandler.SetCategoryPolicy)      // 设置分类分享目标
+			categories.DELETE("/:name/share-limits", requireAdmin, shareLimitHandler.ClearCategoryPolicy) // 删除分类分享目标
+		}
+
+		// 分享目标路由，仅管理员
+		shareLimits := v1.Group("/share-limits", requireAdmin)
+		{
+			shareLimits.GET("", shareLimitHandler.ListPolicies) // 获取所有分享目标
+		}
+
+		// 带宽调度规则路由，仅管理员
+		schedules := v1.Group("/schedules", requireAdmin)
+		{
+			schedules.GET("", scheduleHandler.ListRules)          // 获取所有调度规则
+			schedules.POST("", scheduleHandler.CreateRule)        // 创建调度规则
+			schedules.GET("/:id", scheduleHandler.GetRule)        // 获取单条调度规则
+			schedules.PUT("/:id", scheduleHandler.UpdateRule)     // 更新调度规则
+			schedules.DELETE("/:id", scheduleHandler.DeleteRule)  // 删除调度规则
+		}
+
+		// 用户管理路由，仅管理员
+		users := v1.Group("/users", requireAdmin)
+		{
+			users.GET("", userHandler.ListUsers)              // 获取所有用户
+			users.POST("", userHandler.CreateUser)            // 创建用户
+			users.PUT("/:id", userHandler.UpdateUser)         // 修改用户角色或重置密码
+			users.DELETE("/:id", userHandler.DeleteUser)      // 删除用户
+			users.PUT("/:id/grants", userHandler.SetGrants)   // 设置用户的客户端授权
+			users.GET("/:id/quota", handler.GetUserQuota)     // 获取用户配额
+			users.PUT("/:id/quota", handler.SetUserQuota)     // 设置用户配额
+		}
+
+		// 目标客户端选择策略路由，仅管理员
+		selection := v1.Group("/selection-policy", requireAdmin)
+		{
+			selection.GET("", handler.GetSelectionPolicy) // 获取目标客户端选择策略
+			selection.PUT("", handler.SetSelectionPolicy) // 设置目标客户端选择策略
+		}
+
+		// 监视目录路由，仅管理员
+		watchFolders := v1.Group("/watch-folders", requireAdmin)
+		{
+			watchFolders.GET("", watchFolderHandler.ListFolders)                  // 获取所有监视目录
+			watchFolders.POST("", watchFolderHandler.CreateFolder)                // 创建监视目录
+			watchFolders.GET("/:id", watchFolderHandler.GetFolder)                // 获取单个监视目录
+			watchFolders.PUT("/:id", watchFolderHandler.UpdateFolder)             // 更新监视目录
+			watchFolders.DELETE("/:id", watchFolderHandler.DeleteFolder)          // 删除监视目录
+			watchFolders.GET("/:id/ingestions", watchFolderHandler.ListIngestions) // 获取文件处理结果
+		}
+
+		// RSS 订阅路由，仅管理员
+		rss := v1.Group("/rss", requireAdmin)
+		{
+			rss.GET("/feeds", rssHandler.ListFeeds)                // 获取所有订阅源
+			rss.POST("/feeds", rssHandler.CreateFeed)              // 创建订阅源
+			rss.GET("/feeds/:id", rssHandler.GetFeed)              // 获取单个订阅源
+			rss.PUT("/feeds/:id", rssHandler.UpdateFeed)           // 更新订阅源
+			rss.DELETE("/feeds/:id", rssHandler.DeleteFeed)        // 删除订阅源
+			rss.POST("/feeds/:id/refresh", rssHandler.RefreshFeed) // 立即抓取订阅源
+			rss.GET("/feeds/:id/items", rssHandler.ListItems)      // 获取已处理的条目
+			rss.GET("/rules", rssHandler.ListRules)                // 获取所有过滤规则
+			rss.POST("/rules", rssHandler.CreateRule)              // 创建过滤规则
+			rss.GET("/rules/:id", rssHandler.GetRule)              // 获取单条过滤规则
+			rss.PUT("/rules/:id", rssHandler.UpdateRule)           // 更新过滤规则
+			rss.DELETE("/rules/:id", rssHandler.DeleteRule)        // 删除过滤规则
+		}
+
+		// 种子整理规则路由，仅管理员
+		housekeepingRoutes := v1.Group("/housekeeping", requireAdmin)
+		{
+			housekeepingRoutes.GET("/rules", housekeepingHandler.ListRules)                // 获取所有整理规则
+			housekeepingRoutes.POST("/rules", housekeepingHandler.CreateRule)              // 创建整理规则
+			housekeepingRoutes.GET("/rules/:id", housekeepingHandler.GetRule)              // 获取单条整理规则
+			housekeepingRoutes.PUT("/rules/:id", housekeepingHandler.UpdateRule)           // 更新整理规则
+			housekeepingRoutes.DELETE("/rules/:id", housekeepingHandler.DeleteRule)        // 删除整理规则
+			housekeepingRoutes.GET("/rules/:id/preview", housekeepingHandler.PreviewRule)  // 预演已保存的规则
+			housekeepingRoutes.POST("/preview", housekeepingHandler.PreviewDraft)          // 预演未保存的规则
+			housekeepingRoutes.GET("/history", housekeepingHandler.ListExecutions)         // 获取执行历史
+		}
+
+		// 种子迁移路由，仅管理员
+		migrations := v1.Group("/migrations", requireAdmin)
+		{
+			migrations.POST("", handler.StartMigration)   // 创建迁移任务（支持预演）
+			migrations.GET("", handler.ListMigrations)    // 获取迁移任务列表
+			migrations.GET("/:id", handler.GetMigration)  // 获取迁移任务进度
+		}
+
+		// 辅种路由，仅管理员
+		v1.POST("/cross-seed", requireAdmin, handler.CrossSeed) // 扫描辅种目录并注入匹配的种子
+
+		// 审计日志路由，仅管理员
+		audit := v1.Group("/audit", requireAdmin)
+		{
+			audit.GET("", handler.GetAuditLogs)          // 查询审计日志
+			audit.GET("/export", handler.ExportAuditLogs) // 导出审计日志（CSV/JSON）
+		}
+
+		// 当前用户路由
+		me := v1.Group("/me")
+		{
+			me.GET("/quota", handler.GetMyQuota) // 获取当前用户的配额与使用情况
+		}
+
+		// 传输统计路由
+		stats := v1.Group("/stats")
+		{
+			stats.GET("/summary", handler.GetSummary)      // 获取仪表盘汇总
+			stats.GET("/history", statsHandler.GetHistory) // 查询历史传输统计
 		}
 	}
 
+	// qBittorrent Web API 兼容接口，供 Sonarr、Radarr、autobrr 等工具使用
+	qbitAPI := router.Group("/api/v2")
+	{
+		qbitAPI.POST("/auth/login", qbitHandler.Login) // 会话登录
+		qbitAPI.POST("/auth/logout", qbitHandler.Logout) // 会话注销
+
+		session := qbitAPI.Group("", qbitHandler.RequireSession())
+		session.GET("/app/version", qbitHandler.Version)             // qBittorrent 版本
+		session.GET("/app/webapiVersion", qbitHandler.WebAPIVersion) // Web API 版本
+		session.GET("/app/preferences", qbitHandler.Preferences)     // 偏好设置
+		session.GET("/torrents/info", qbitHandler.TorrentsInfo)      // 种子列表
+		session.POST("/torrents/info", qbitHandler.TorrentsInfo)
+		session.POST("/torrents/add", qbitHandler.AddTorrents)       // 添加种子
+		session.POST("/torrents/delete", qbitHandler.DeleteTorrents) // 删除种子
+		session.POST("/torrents/pause", qbitHandler.PauseTorrents)   // 暂停种子
+		session.POST("/torrents/stop", qbitHandler.PauseTorrents)
+		session.POST("/torrents/resume", qbitHandler.ResumeTorrents) // 恢复种子
+		session.POST("/torrents/start", qbitHandler.ResumeTorrents)
+		session.GET("/torrents/categories", qbitHandler.Categories)          // 分类列表
+		session.POST("/torrents/categories", qbitHandler.Categories)
+		session.POST("/torrents/createCategory", qbitHandler.CreateCategory) // 创建分类
+		session.POST("/torrents/setCategory", qbitHandler.SetCategory)       // 设置种子分类
+	}
+
+	// Transmission RPC 兼容接口，汇总所有可见客户端的种子
+	router.POST("/transmission/rpc", transmissionHandler.RequireSession(), transmissionHandler.RPC)
+
 	// 健康检查路由
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -55,6 +253,9 @@ func SetupRouter(service *core.TorrentService) *gin.Engine {
 		})
 	})
 
+	// Prometheus 指标，包含所有客户端的信息，仅管理员可访问
+	router.GET("/metrics", authHandler.RequireAuth(), requireAdmin, gin.WrapH(m.Handler()))
+
 	// 根路径
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -62,12 +263,40 @@ func SetupRouter(service *core.TorrentService) *gin.Engine {
 			"version": "1.0.0",
 			"endpoints": gin.H{
 				"health":         "/health",
+				"metrics":        "/metrics",
+				"login":          "/api/v1/auth/login (POST)",
+				"api_keys":       "/api/v1/auth/api-keys",
+				"users":          "/api/v1/users",
+				"my_quota":       "/api/v1/me/quota",
+				"audit":          "/api/v1/audit",
+				"qbittorrent":    "/api/v2 (qBittorrent Web API)",
+				"transmission":   "/transmission/rpc (Transmission RPC)",
 				"torrents":       "/api/v1/torrents",
 				"add_torrent":    "/api/v1/torrents (POST)",
+				"duplicates":     "/api/v1/torrents/duplicates",
 				"pause_torrent":  "/api/v1/torrents/pause (POST)",
 				"resume_torrent": "/api/v1/torrents/resume (POST)",
 				"delete_torrent": "/api/v1/torrents (DELETE)",
 				"clients":        "/api/v1/clients",
+				"client_limits":  "/api/v1/clients/{id}/limits (GET/PUT)",
+				"selection_policy": "/api/v1/selection-policy (GET/PUT)",
+				"migrations":     "/api/v1/migrations",
+				"cross_seed":     "/api/v1/cross-seed (POST)",
+				"torrent_limits": "/api/v1/torrents/{clientID}/{hash}/limits (PUT)",
+				"categories":     "/api/v1/categories",
+				"assign_category": "/api/v1/torrents/category (POST)",
+				"torrent_tags":   "/api/v1/torrents/tags (POST/DELETE)",
+				"schedules":      "/api/v1/schedules",
+				"watch_folders":  "/api/v1/watch-folders",
+				"rss":            "/api/v1/rss/feeds, /api/v1/rss/rules",
+				"housekeeping":   "/api/v1/housekeeping/rules",
+				"share_limits":   "/api/v1/share-limits",
+				"rename_torrent": "/api/v1/torrents/{clientID}/{hash}/rename (POST)",
+				"rename_file":    "/api/v1/torrents/{clientID}/{hash}/files/rename (POST)",
+				"torrent_stream": "/api/v1/torrents/stream (SSE)",
+				"torrent_ws":     "/api/v1/torrents/ws (WebSocket)",
+				"stats_summary":  "/api/v1/stats/summary",
+				"stats_history":  "/api/v1/stats/history",
 			},
 		})
 	})
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/metrics"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetricsRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auth, accessToken, apiKey := newTestAuth(t)
+	if _, err := auth.CreateUser(nil, "bob", "correct horse", models.RoleViewer); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	pair, err := auth.Login("bob", "correct horse")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	service := core.NewTorrentService(nil, newTestDB(t))
+	router := SetupRouter(service, auth, nil, nil, nil, nil, nil, nil, metrics.New(metrics.Options{}), FacadeOptions{})
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{"no credentials", "", "", http.StatusUnauthorized},
+		{"viewer", "Authorization", "Bearer " + pair.AccessToken, http.StatusForbidden},
+		{"admin access token", "Authorization", "Bearer " + accessToken, http.StatusOK},
+		{"admin api key", "X-API-Key", apiKey, http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if test.header != "" {
+				request.Header.Set(test.header, test.value)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			if recorder.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+		})
+	}
+
+	request := httptest.NewRequest(http.MethodOptions, "/api/v1/torrents", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if headers := recorder.Header().Get("Access-Control-Allow-Headers"); headers != "Content-Type, Authorization, X-API-Key" {
+		t.Errorf("Access-Control-Allow-Headers = %q, want X-API-Key allowed", headers)
+	}
+}
@@ -0,0 +1,367 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultRSSItemLimit 查询已处理条目时默认返回的条数
+const defaultRSSItemLimit = 100
+
+type RSSHandler struct {
+	poller *core.RSSPoller
+}
+
+func NewRSSHandler(p *core.RSSPoller) *RSSHandler {
+	return &RSSHandler{
+		poller: p,
+	}
+}
+
+// RSSFeedRequest 创建或更新订阅源的请求结构，interval 单位为分钟，为 0 时使用 RSS_INTERVAL
+type RSSFeedRequest struct {
+	Name     string `json:"name" binding:"required"`
+	URL      string `json:"url" binding:"required"`
+	Interval int    `json:"interval"`
+	Enabled  *bool  `json:"enabled"`
+}
+
+// toModel 将请求转换为订阅源模型，未指定 enabled 时默认启用
+func (r *RSSFeedRequest) toModel() *models.RSSFeed {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return &models.RSSFeed{
+		Name:     r.Name,
+		URL:      r.URL,
+		Interval: r.Interval,
+		Enabled:  enabled,
+	}
+}
+
+// RSSRuleRequest 创建或更新过滤规则的请求结构
+// feedID 为 0 时作用于所有订阅源；clientID 为空时按目标客户端选择策略选择
+type RSSRuleRequest struct {
+	Name                string `json:"name" binding:"required"`
+	FeedID              uint   `json:"feedID"`
+	Include             string `json:"include"`
+	Exclude             string `json:"exclude"`
+	MinSize             int64  `json:"minSize"`
+	MaxSize             int64  `json:"maxSize"`
+	Season              int    `json:"season"`
+	MinEpisode          int    `json:"minEpisode"`
+	MaxEpisode          int    `json:"maxEpisode"`
+	SkipGrabbedEpisodes bool   `json:"skipGrabbedEpisodes"`
+	ClientID            string `json:"clientID"`
+	Category            string `json:"category"`
+	Enabled             *bool  `json:"enabled"`
+}
+
+// toModel 将请求转换为过滤规则模型，未指定 enabled 时默认启用
+func (r *RSSRuleRequest) toModel() *models.RSSRule {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return &models.RSSRule{
+		Name:                r.Name,
+		FeedID:              r.FeedID,
+		Include:             r.Include,
+		Exclude:             r.Exclude,
+		MinSize:             r.MinSize,
+		MaxSize:             r.MaxSize,
+		Season:              r.Season,
+		MinEpisode:          r.MinEpisode,
+		MaxEpisode:          r.MaxEpisode,
+		SkipGrabbedEpisodes: r.SkipGrabbedEpisodes,
+		ClientID:            r.ClientID,
+		Category:            r.Category,
+		Enabled:             enabled,
+	}
+}
+
+// ListFeeds 获取所有订阅源的处理器
+func (h *RSSHandler) ListFeeds(c *gin.Context) {
+	feeds, err := h.poller.ListFeeds()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get RSS feeds: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    feeds,
+		"count":   len(feeds),
+	})
+}
+
+// GetFeed 获取单个订阅源的处理器
+func (h *RSSHandler) GetFeed(c *gin.Context) {
+	id, ok := parseRSSID(c, "feed")
+	if !ok {
+		return
+	}
+
+	feed, err := h.poller.GetFeed(id)
+	if err != nil {
+		respondError(c, "Failed to get RSS feed: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    feed,
+	})
+}
+
+// CreateFeed 创建订阅源的处理器
+func (h *RSSHandler) CreateFeed(c *gin.Context) {
+	var req RSSFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	feed := req.toModel()
+	if err := h.poller.CreateFeed(currentAccess(c), feed); err != nil {
+		respondError(c, "Failed to create RSS feed: ", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "RSS feed created successfully",
+		"data":    feed,
+	})
+}
+
+// UpdateFeed 更新订阅源的处理器
+func (h *RSSHandler) UpdateFeed(c *gin.Context) {
+	id, ok := parseRSSID(c, "feed")
+	if !ok {
+		return
+	}
+
+	var req RSSFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	feed := req.toModel()
+	if err := h.poller.UpdateFeed(currentAccess(c), id, feed); err != nil {
+		respondError(c, "Failed to update RSS feed: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "RSS feed updated successfully",
+		"data":    feed,
+	})
+}
+
+// DeleteFeed 删除订阅源的处理器
+func (h *RSSHandler) DeleteFeed(c *gin.Context) {
+	id, ok := parseRSSID(c, "feed")
+	if !ok {
+		return
+	}
+
+	if err := h.poller.DeleteFeed(currentAccess(c), id); err != nil {
+		respondError(c, "Failed to delete RSS feed: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "RSS feed deleted successfully",
+	})
+}
+
+// RefreshFeed 立即抓取订阅源的处理器，返回本次新处理的条目
+func (h *RSSHandler) RefreshFeed(c *gin.Context) {
+	id, ok := parseRSSID(c, "feed")
+	if !ok {
+		return
+	}
+
+	result, err := h.poller.FetchFeed(id)
+	if err != nil {
+		respondError(c, "Failed to fetch RSS feed: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// ListItems 获取订阅源最近处理条目的处理器，limit 默认 100
+func (h *RSSHandler) ListItems(c *gin.Context) {
+	id, ok := parseRSSID(c, "feed")
+	if !ok {
+		return
+	}
+
+	limit := defaultRSSItemLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid limit: " + value,
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	items, err := h.poller.ListItems(id, limit)
+	if err != nil {
+		respondError(c, "Failed to get RSS items: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    items,
+		"count":   len(items),
+	})
+}
+
+// ListRules 获取所有过滤规则的处理器
+func (h *RSSHandler) ListRules(c *gin.Context) {
+	rules, err := h.poller.ListRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get RSS rules: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+		"count":   len(rules),
+	})
+}
+
+// GetRule 获取单条过滤规则的处理器
+func (h *RSSHandler) GetRule(c *gin.Context) {
+	id, ok := parseRSSID(c, "rule")
+	if !ok {
+		return
+	}
+
+	rule, err := h.poller.GetRule(id)
+	if err != nil {
+		respondError(c, "Failed to get RSS rule: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// CreateRule 创建过滤规则的处理器
+func (h *RSSHandler) CreateRule(c *gin.Context) {
+	var req RSSRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	rule := req.toModel()
+	if err := h.poller.CreateRule(currentAccess(c), rule); err != nil {
+		respondError(c, "Failed to create RSS rule: ", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "RSS rule created successfully",
+		"data":    rule,
+	})
+}
+
+// UpdateRule 更新过滤规则的处理器
+func (h *RSSHandler) UpdateRule(c *gin.Context) {
+	id, ok := parseRSSID(c, "rule")
+	if !ok {
+		return
+	}
+
+	var req RSSRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	rule := req.toModel()
+	if err := h.poller.UpdateRule(currentAccess(c), id, rule); err != nil {
+		respondError(c, "Failed to update RSS rule: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "RSS rule updated successfully",
+		"data":    rule,
+	})
+}
+
+// DeleteRule 删除过滤规则的处理器
+func (h *RSSHandler) DeleteRule(c *gin.Context) {
+	id, ok := parseRSSID(c, "rule")
+	if !ok {
+		return
+	}
+
+	if err := h.poller.DeleteRule(currentAccess(c), id); err != nil {
+		respondError(c, "Failed to delete RSS rule: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "RSS rule deleted successfully",
+	})
+}
+
+// parseRSSID 解析路径中的订阅源或规则 ID，失败时直接写入 400 响应
+func parseRSSID(c *gin.Context, kind string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid RSS " + kind + " id: " + c.Param("id"),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleHandler struct {
+	scheduler *core.Scheduler
+}
+
+func NewScheduleHandler(s *core.Scheduler) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduler: s,
+	}
+}
+
+// ScheduleRuleRequest 创建或更新调度规则的请求结构
+type ScheduleRuleRequest struct {
+	Name            string `json:"name" binding:"required"`
+	ClientID        string `json:"clientID"`
+	Days            string `json:"days"`
+	StartTime       string `json:"startTime" binding:"required"`
+	EndTime         string `json:"endTime" binding:"required"`
+	DownloadLimit   *int64 `json:"downloadLimit"`
+	UploadLimit     *int64 `json:"uploadLimit"`
+	AltSpeedEnabled *bool  `json:"altSpeedEnabled"`
+	Enabled         *bool  `json:"enabled"`
+}
+
+// toModel 将请求转换为调度规则模型，未指定 enabled 时默认启用
+func (r *ScheduleRuleRequest) toModel() *models.ScheduleRule {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return &models.ScheduleRule{
+		Name:            r.Name,
+		ClientID:        r.ClientID,
+		Days:            r.Days,
+		StartTime:       r.StartTime,
+		EndTime:         r.EndTime,
+		DownloadLimit:   r.DownloadLimit,
+		UploadLimit:     r.UploadLimit,
+		AltSpeedEnabled: r.AltSpeedEnabled,
+		Enabled:         enabled,
+	}
+}
+
+// ListRules 获取所有调度规则的处理器
+func (h *ScheduleHandler) ListRules(c *gin.Context) {
+	rules, err := h.scheduler.ListRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get schedule rules: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+		"count":   len(rules),
+	})
+}
+
+// GetRule 获取单条调度规则的处理器
+func (h *ScheduleHandler) GetRule(c *gin.Context) {
+	id, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.scheduler.GetRule(id)
+	if err != nil {
+		respondError(c, "Failed to get schedule rule: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// CreateRule 创建调度规则的处理器
+func (h *ScheduleHandler) CreateRule(c *gin.Context) {
+	// 解析请求体
+	var req ScheduleRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	rule := req.toModel()
+	if err := rule.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid schedule rule: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.scheduler.CreateRule(currentAccess(c), rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to create schedule rule: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Schedule rule created successfully",
+		"data":    rule,
+	})
+}
+
+// UpdateRule 更新调度规则的处理器
+func (h *ScheduleHandler) UpdateRule(c *gin.Context) {
+	id, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	// 解析请求体
+	var req ScheduleRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	rule := req.toModel()
+	if err := rule.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid schedule rule: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.scheduler.UpdateRule(currentAccess(c), id, rule); err != nil {
+		respondError(c, "Failed to update schedule rule: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Schedule rule updated successfully",
+		"data":    rule,
+	})
+}
+
+// DeleteRule 删除调度规则的处理器
+func (h *ScheduleHandler) DeleteRule(c *gin.Context) {
+	id, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.scheduler.DeleteRule(currentAccess(c), id); err != nil {
+		respondError(c, "Failed to delete schedule rule: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Schedule rule deleted successfully",
+	})
+}
+
+// parseRuleID 解析路径中的规则 ID，失败时直接写入 400 响应
+func parseRuleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid rule id: " + c.Param("id"),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
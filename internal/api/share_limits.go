@@ -0,0 +1,159 @@
+package api
+
+import (
+	"net/http"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+type ShareLimitHandler struct {
+	enforcer *core.ShareLimitEnforcer
+	service  *core.TorrentService
+}
+
+func NewShareLimitHandler(e *core.ShareLimitEnforcer, s *core.TorrentService) *ShareLimitHandler {
+	return &ShareLimitHandler{
+		enforcer: e,
+		service:  s,
+	}
+}
+
+// authorize 检查当前用户能否修改种子的分享目标，无权限时直接写入 403 响应
+func (h *ShareLimitHandler) authorize(c *gin.Context) bool {
+	err := h.service.WithAccess(currentAccess(c)).AuthorizeTorrent(c.Param("clientID"), c.Param("hash"), models.ActionEdit)
+	if err != nil {
+		respondError(c, "Forbidden: ", err)
+		return false
+	}
+	return true
+}
+
+// ShareLimitsRequest 设置分享目标的请求结构
+// seedingTimeLimit 单位为分钟，action 可选 pause、remove、remove_with_data
+type ShareLimitsRequest struct {
+	RatioLimit       *float64 `json:"ratioLimit"`
+	SeedingTimeLimit *int64   `json:"seedingTimeLimit"`
+	Action           string   `json:"action" binding:"required"`
+}
+
+// ListPolicies 获取所有分享目标的处理器
+func (h *ShareLimitHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.enforcer.ListPolicies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get share limits: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    policies,
+		"count":   len(policies),
+	})
+}
+
+// SetTorrentPolicy 设置单个种子分享目标的处理器
+func (h *ShareLimitHandler) SetTorrentPolicy(c *gin.Context) {
+	if !h.authorize(c) {
+		return
+	}
+
+	limits, ok := bindShareLimits(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.enforcer.SetTorrentPolicy(currentAccess(c), c.Param("clientID"), c.Param("hash"), limits)
+	if err != nil {
+		respondError(c, "Failed to set share limits: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Share limits updated successfully",
+		"data":    policy,
+	})
+}
+
+// ClearTorrentPolicy 删除单个种子分享目标的处理器
+func (h *ShareLimitHandler) ClearTorrentPolicy(c *gin.Context) {
+	if !h.authorize(c) {
+		return
+	}
+
+	if err := h.enforcer.ClearTorrentPolicy(currentAccess(c), c.Param("clientID"), c.Param("hash")); err != nil {
+		respondError(c, "Failed to clear share limits: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Share limits cleared successfully",
+	})
+}
+
+// SetCategoryPolicy 设置分类分享目标的处理器
+func (h *ShareLimitHandler) SetCategoryPolicy(c *gin.Context) {
+	limits, ok := bindShareLimits(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.enforcer.SetCategoryPolicy(currentAccess(c), c.Param("name"), limits)
+	if err != nil {
+		respondError(c, "Failed to set share limits: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Share limits updated successfully",
+		"data":    policy,
+	})
+}
+
+// ClearCategoryPolicy 删除分类分享目标的处理器
+func (h *ShareLimitHandler) ClearCategoryPolicy(c *gin.Context) {
+	if err := h.enforcer.ClearCategoryPolicy(currentAccess(c), c.Param("name")); err != nil {
+		respondError(c, "Failed to clear share limits: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Share limits cleared successfully",
+	})
+}
+
+// bindShareLimits 解析并校验分享目标请求，失败时直接写入 400 响应
+func bindShareLimits(c *gin.Context) (models.ShareLimits, bool) {
+	// 解析请求体
+	var req ShareLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return models.ShareLimits{}, false
+	}
+
+	limits := models.ShareLimits{
+		RatioLimit:       req.RatioLimit,
+		SeedingTimeLimit: req.SeedingTimeLimit,
+		Action:           models.ShareLimitAction(req.Action),
+	}
+	if err := limits.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid share limits: " + err.Error(),
+		})
+		return models.ShareLimits{}, false
+	}
+
+	return limits, true
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// 未指定时间范围时默认查询最近 24 小时
+const defaultStatsRange = 24 * time.Hour
+
+type StatsHandler struct {
+	collector *core.StatsCollector
+}
+
+func NewStatsHandler(collector *core.StatsCollector) *StatsHandler {
+	return &StatsHandler{
+		collector: collector,
+	}
+}
+
+// StatsTotal 单个客户端在查询范围内的传输总量
+type StatsTotal struct {
+	ClientID   string `json:"client_id"`
+	Downloaded int64  `json:"downloaded"`
+	Uploaded   int64  `json:"uploaded"`
+}
+
+// GetHistory 查询历史传输统计的处理器
+// 支持 from/to（RFC3339）或 range（如 24h、7d）指定时间范围，
+// granularity 可选 hour 或 day，clientID 与 hash 用于过滤
+func (h *StatsHandler) GetHistory(c *gin.Context) {
+	query, err := parseStatsQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid query: " + err.Error(),
+		})
+		return
+	}
+
+	if query.ClientID != "" && !authorizeClient(c, query.ClientID, models.ActionView) {
+		return
+	}
+
+	stats, err := h.collector.History(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get stats history: " + err.Error(),
+		})
+		return
+	}
+
+	// 只返回当前用户可见客户端的统计
+	access := currentAccess(c)
+	visible := make([]models.TransferStat, 0, len(stats))
+	for _, stat := range stats {
+		if access.Can(stat.ClientID, models.ActionView) {
+			visible = append(visible, stat)
+		}
+	}
+	stats = visible
+
+	totals := make([]StatsTotal, 0)
+	index := make(map[string]int)
+	for _, stat := range stats {
+		i, ok := index[stat.ClientID]
+		if !ok {
+			i = len(totals)
+			index[stat.ClientID] = i
+			totals = append(totals, StatsTotal{ClientID: stat.ClientID})
+		}
+		totals[i].Downloaded += stat.Downloaded
+		totals[i].Uploaded += stat.Uploaded
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"from":        query.From,
+		"to":          query.To,
+		"granularity": query.Granularity,
+		"data":        stats,
+		"totals":      totals,
+	})
+}
+
+// parseStatsQuery 解析历史统计查询参数
+func parseStatsQuery(c *gin.Context) (core.StatsHistoryQuery, error) {
+	query := core.StatsHistoryQuery{
+		To:          time.Now(),
+		Granularity: c.DefaultQuery("granularity", models.StatGranularityHour),
+		ClientID:    c.Query("clientID"),
+		Hash:        c.Query("hash"),
+	}
+	if query.Granularity != models.StatGranularityHour && query.Granularity != models.StatGranularityDay {
+		return query, errors.New("granularity must be hour or day")
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return query, errors.New("to must be an RFC3339 time")
+		}
+		query.To = t
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return query, errors.New("from must be an RFC3339 time")
+		}
+		query.From = t
+	} else {
+		span := defaultStatsRange
+		if r := c.Query("range"); r != "" {
+			parsed, err := parseRange(r)
+			if err != nil {
+				return query, err
+			}
+			span = parsed
+		}
+		query.From = query.To.Add(-span)
+	}
+
+	if !query.From.Before(query.To) {
+		return query, errors.New("from must be before to")
+	}
+	return query, nil
+}
+
+// parseRange 解析时间范围，在 time.ParseDuration 的基础上支持以 d 为单位的天数
+func parseRange(value string) (time.Duration, error) {
+	var span time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, errors.New("invalid range: " + value)
+		}
+		span = time.Duration(n) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, errors.New("invalid range: " + value)
+		}
+		span = parsed
+	}
+
+	if span <= 0 {
+		return 0, errors.New("range must be positive")
+	}
+	return span, nil
+}
+
+// GetSummary 获取仪表盘汇总信息的处理器
+func (h *TorrentHandler) GetSummary(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    h.scoped(c).GetSummary(),
+	})
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// streamKeepAlive SSE 注释心跳与 WebSocket ping 的间隔
+	streamKeepAlive = 15 * time.Second
+	// streamWriteTimeout WebSocket 单条消息的写超时
+	streamWriteTimeout = 10 * time.Second
+)
+
+// 跨域策略与 CORS 中间件保持一致，允许所有来源
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// StreamTorrentsSSE 通过 Server-Sent Events 推送种子更新的处理器
+// 事件名为 snapshot 或 diff，数据为 JSON 格式的 TorrentUpdate
+func (h *TorrentHandler) StreamTorrentsSSE(c *gin.Context) {
+	service := h.scoped(c)
+	sub, err := service.Subscribe(parseTorrentFilter(c))
+	if err != nil {
+		respondError(c, "Failed to subscribe: ", err)
+		return
+	}
+	defer service.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case update, ok := <-sub.Updates:
+			if !ok {
+				// 订阅者过慢被断开，客户端应重新连接
+				return false
+			}
+			c.SSEvent(update.Type, update)
+			return true
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		}
+	})
+}
+
+// StreamTorrentsWS 通过 WebSocket 推送种子更新的处理器
+// 每条文本消息为 JSON 格式的 TorrentUpdate
+func (h *TorrentHandler) StreamTorrentsWS(c *gin.Context) {
+	// 在升级连接前订阅，以便权限错误仍能以普通 HTTP 响应返回
+	service := h.scoped(c)
+	sub, err := service.Subscribe(parseTorrentFilter(c))
+	if err != nil {
+		respondError(c, "Failed to subscribe: ", err)
+		return
+	}
+	defer service.Unsubscribe(sub)
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade 已写入错误响应
+		return
+	}
+	defer conn.Close()
+
+	// 持续读取以处理 pong 和 close 控制帧，连接断开时结束
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(streamKeepAlive)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case update, ok := <-sub.Updates:
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "subscriber too slow"),
+					time.Now().Add(streamWriteTimeout))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err := conn.WriteJSON(update); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseTorrentFilter 从查询参数解析过滤条件
+// clientID 可重复出现或以逗号分隔，category 和 state 为精确匹配
+func parseTorrentFilter(c *gin.Context) models.TorrentFilter {
+	var clientIDs []string
+	for _, value := range c.QueryArray("clientID") {
+		for _, clientID := range strings.Split(value, ",") {
+			if clientID = strings.TrimSpace(clientID); clientID != "" {
+				clientIDs = append(clientIDs, clientID)
+			}
+		}
+	}
+
+	// owner=me 表示当前用户
+	owner := c.Query("owner")
+	if owner == "me" {
+		owner = currentUser(c).Username
+	}
+
+	return models.TorrentFilter{
+		ClientIDs: clientIDs,
+		Category:  c.Query("category"),
+		State:     c.Query("state"),
+		AddedBy:   owner,
+	}
+}
@@ -0,0 +1,575 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// transmissionSessionHeader Transmission RPC 的 CSRF 会话请求头
+	transmissionSessionHeader = "X-Transmission-Session-Id"
+	// 对外报告的 Transmission 与 RPC 版本
+	transmissionVersion    = "3.00 (Down-Nexus)"
+	transmissionRPCVersion = 17
+	transmissionRPCMinimum = 14
+	// transmissionIDPruneInterval 清理已删除种子 ID 的最小间隔
+	transmissionIDPruneInterval = time.Minute
+)
+
+// Transmission 的种子状态
+const (
+	transmissionStatusStopped      = 0
+	transmissionStatusCheckWait    = 1
+	transmissionStatusCheck        = 2
+	transmissionStatusDownloadWait = 3
+	transmissionStatusDownload     = 4
+	transmissionStatusSeedWait     = 5
+	transmissionStatusSeed         = 6
+)
+
+// TransmissionFacadeOptions Transmission RPC 兼容接口添加种子时的目标客户端
+type TransmissionFacadeOptions struct {
+	// DefaultClient 目标客户端，为空时使用目标客户端选择策略
+	DefaultClient string
+}
+
+// TransmissionFacadeHandler 实现 Transmission RPC（/transmission/rpc）的常用方法，
+// 汇总所有可见客户端的种子，请求转换为 TorrentService 调用
+type TransmissionFacadeHandler struct {
+	service   *core.TorrentService
+	auth      *core.AuthService
+	options   TransmissionFacadeOptions
+	sessionID string
+	ids       *transmissionIDs
+}
+
+func NewTransmissionFacadeHandler(service *core.TorrentService, auth *core.AuthService, options TransmissionFacadeOptions) *TransmissionFacadeHandler {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return &TransmissionFacadeHandler{
+		service:   service,
+		auth:      auth,
+		options:   options,
+		sessionID: base64.RawURLEncoding.EncodeToString(buf),
+		ids:       newTransmissionIDs(),
+	}
+}
+
+// transmissionIDs 为汇总后的种子分配进程内稳定的数字 ID，Transmission 客户端使用数字 ID 引用种子
+type transmissionIDs struct {
+	mutex sync.Mutex
+	next  int64
+	ids   map[string]int64
+	refs  map[int64]models.TorrentRef
+	// pruned 上次清理的时间
+	pruned time.Time
+}
+
+func newTransmissionIDs() *transmissionIDs {
+	return &transmissionIDs{
+		ids:  make(map[string]int64),
+		refs: make(map[int64]models.TorrentRef),
+	}
+}
+
+// id 返回种子的数字 ID，首次出现时分配新 ID
+func (t *transmissionIDs) id(clientID, hash string) int64 {
+	key := clientID + "/" + strings.ToLower(hash)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if id, ok := t.ids[key]; ok {
+		return id
+	}
+	t.next++
+	t.ids[key] = t.next
+	t.refs[t.next] = models.TorrentRef{ClientID: clientID, Hash: hash}
+	return t.next
+}
+
+// prune 删除已不在任何客户端上的种子的 ID，避免映射随种子增删无限增长
+// 每隔 transmissionIDPruneInterval 最多执行一次，snapshot 需返回所有用户可见的全部种子；已分配的数字不会复用
+func (t *transmissionIDs) prune(snapshot func() []models.UnifiedTorrent) {
+	t.mutex.Lock()
+	if time.Since(t.pruned) < transmissionIDPruneInterval {
+		t.mutex.Unlock()
+		return
+	}
+	t.pruned = time.Now()
+	t.mutex.Unlock()
+
+	live := make(map[string]bool)
+	for _, torrent := range snapshot() {
+		live[torrent.ClientID+"/"+strings.ToLower(torrent.Hash)] = true
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for key, id := range t.ids {
+		if !live[key] {
+			delete(t.ids, key)
+			delete(t.refs, id)
+		}
+	}
+}
+
+func (t *transmissionIDs) ref(id int64) (models.TorrentRef, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	ref, ok := t.refs[id]
+	return ref, ok
+}
+
+// transmissionRequest Transmission RPC 请求
+type transmissionRequest struct {
+	Method    string          `json:"method"`
+	Arguments json.RawMessage `json:"arguments"`
+	Tag       json.RawMessage `json:"tag,omitempty"`
+}
+
+// transmissionResponse Transmission RPC 响应，Result 为 success 或错误描述
+type transmissionResponse struct {
+	Result    string          `json:"result"`
+	Arguments interface{}     `json:"arguments"`
+	Tag       json.RawMessage `json:"tag,omitempty"`
+}
+
+// RequireSession Transmission RPC 认证中间件
+// 使用 HTTP Basic 认证（Down-Nexus 用户名与密码，密码也可以是该用户的 API 密钥），
+// 也兼容 Authorization: Bearer 与 X-API-Key 请求头
+func (h *TransmissionFacadeHandler) RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user *models.User
+		var err error
+		if token := bearerToken(c); token != "" {
+			user, err = h.auth.Authenticate(token)
+		} else if username, password, ok := c.Request.BasicAuth(); ok {
+			user, err = h.auth.CheckCredentials(username, password)
+		} else {
+			err = &core.InvalidCredentialsError{}
+		}
+		if err != nil {
+			var invalid *core.InvalidCredentialsError
+			if errors.As(err, &invalid) {
+				h.unauthorized(c)
+				return
+			}
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		setCurrentUser(c, user)
+		c.Next()
+	}
+}
+
+func (h *TransmissionFacadeHandler) unauthorized(c *gin.Context) {
+	c.Header("WWW-Authenticate", `Basic realm="Transmission"`)
+	c.AbortWithStatus(http.StatusUnauthorized)
+}
+
+// scoped 返回按当前用户权限执行操作的服务视图
+func (h *TransmissionFacadeHandler) scoped(c *gin.Context) *core.TorrentService {
+	return h.service.WithAccess(currentAccess(c))
+}
+
+// RPC 处理 Transmission RPC 请求
+// 与 Transmission 一样，缺少或错误的 X-Transmission-Session-Id 返回 409 并在响应头中给出正确的会话 ID
+func (h *TransmissionFacadeHandler) RPC(c *gin.Context) {
+	if c.GetHeader(transmissionSessionHeader) != h.sessionID {
+		c.Header(transmissionSessionHeader, h.sessionID)
+		c.String(http.StatusConflict, "<h1>409: Conflict</h1><p>Your request had an invalid session-id header.</p>")
+		return
+	}
+	c.Header(transmissionSessionHeader, h.sessionID)
+
+	var req transmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.String(http.StatusBadRequest, "Invalid request format: "+err.Error())
+		return
+	}
+
+	var arguments interface{}
+	var err error
+	service := h.scoped(c)
+	switch req.Method {
+	case "session-get":
+		arguments = h.sessionGet()
+	case "session-stats":
+		arguments = h.sessionStats(service)
+	case "torrent-get":
+		arguments, err = h.torrentGet(service, req.Arguments)
+	case "torrent-add":
+		arguments, err = h.torrentAdd(service, req.Arguments)
+	case "torrent-start", "torrent-start-now":
+		err = h.forEachTorrent(service, req.Arguments, func(ref models.TorrentRef) error {
+			return service.ResumeTorrent(ref.ClientID, ref.Hash)
+		})
+	case "torrent-stop":
+		err = h.forEachTorrent(service, req.Arguments, func(ref models.TorrentRef) error {
+			return service.PauseTorrent(ref.ClientID, ref.Hash)
+		})
+	case "torrent-remove":
+		var args struct {
+			DeleteLocalData bool `json:"delete-local-data"`
+		}
+		if err = decodeArguments(req.Arguments, &args); err == nil {
+			err = h.forEachTorrent(service, req.Arguments, func(ref models.TorrentRef) error {
+				return service.DeleteTorrent(ref.ClientID, ref.Hash, args.DeleteLocalData)
+			})
+		}
+	default:
+		err = errors.New("method name not recognized")
+	}
+
+	response := transmissionResponse{Result: "success", Arguments: arguments, Tag: req.Tag}
+	if err != nil {
+		response.Result = err.Error()
+	}
+	if response.Arguments == nil {
+		response.Arguments = gin.H{}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// sessionGet 返回会话设置，Down-Nexus 不提供全局下载目录等设置，相关字段为默认值
+func (h *TransmissionFacadeHandler) sessionGet() gin.H {
+	return gin.H{
+		"version":                  transmissionVersion,
+		"rpc-version":              transmissionRPCVersion,
+		"rpc-version-minimum":      transmissionRPCMinimum,
+		"session-id":               h.sessionID,
+		"download-dir":             "",
+		"speed-limit-down-enabled": false,
+		"speed-limit-up-enabled":   false,
+		"alt-speed-enabled":        false,
+		"seedRatioLimited":         false,
+		"download-queue-enabled":   false,
+		"seed-queue-enabled":       false,
+		"units": gin.H{
+			"speed-units":  []string{"kB/s", "MB/s", "GB/s", "TB/s"},
+			"speed-bytes":  1000,
+			"size-units":   []string{"kB", "MB", "GB", "TB"},
+			"size-bytes":   1000,
+			"memory-units": []string{"KiB", "MiB", "GiB", "TiB"},
+			"memory-bytes": 1024,
+		},
+	}
+}
+
+// sessionStats 返回所有可见客户端合计的会话统计
+func (h *TransmissionFacadeHandler) sessionStats(service *core.TorrentService) gin.H {
+	total := service.GetSummary().Total
+	paused := total.StateCounts[models.StatePaused]
+	stats := gin.H{
+		"uploadedBytes":   total.Uploaded,
+		"downloadedBytes": total.Downloaded,
+		"filesAdded":      0,
+		"sessionCount":    1,
+		"secondsActive":   0,
+	}
+	return gin.H{
+		"activeTorrentCount": total.TorrentCount - paused,
+		"pausedTorrentCount": paused,
+		"torrentCount":       total.TorrentCount,
+		"downloadSpeed":      total.DownloadSpeed,
+		"uploadSpeed":        total.UploadSpeed,
+		"cumulative-stats":   stats,
+		"current-stats":      stats,
+	}
+}
+
+// torrentGet 返回 ids 指定（省略时为全部）种子的 fields 字段
+func (h *TransmissionFacadeHandler) torrentGet(service *core.TorrentService, raw json.RawMessage) (gin.H, error) {
+	var args struct {
+		Fields []string        `json:"fields"`
+		IDs    json.RawMessage `json:"ids"`
+	}
+	if err := decodeArguments(raw, &args); err != nil {
+		return nil, err
+	}
+	selector, err := parseTransmissionIDs(args.IDs, true)
+	if err != nil {
+		return nil, err
+	}
+	h.ids.prune(h.service.GetAllTorrents)
+
+	torrents := make([]map[string]interface{}, 0)
+	for _, torrent := range service.GetAllTorrents() {
+		id := h.ids.id(torrent.ClientID, torrent.Hash)
+		if !selector.match(id, torrent.Hash) {
+			continue
+		}
+		fields := h.torrentFields(id, torrent)
+		if len(args.Fields) > 0 {
+			selected := make(map[string]interface{}, len(args.Fields))
+			for _, name := range args.Fields {
+				if value, ok := fields[name]; ok {
+					selected[name] = value
+				}
+			}
+			fields = selected
+		}
+		torrents = append(torrents, fields)
+	}
+
+	result := gin.H{"torrents": torrents}
+	if selector.recentlyActive {
+		result["removed"] = []int64{}
+	}
+	return result, nil
+}
+
+// torrentFields 将统一模型转换为 Transmission 的种子字段
+func (h *TransmissionFacadeHandler) torrentFields(id int64, torrent models.UnifiedTorrent) map[string]interface{} {
+	left := torrent.Size - int64(float64(torrent.Size)*torrent.Progress)
+	eta := torrent.ETA
+	switch {
+	case eta < 0:
+		eta = -1
+	case eta >= qbitInfiniteETA:
+		eta = -2
+	}
+
+	labels := make([]string, 0, len(torrent.Tags)+1)
+	if torrent.Category != "" {
+		labels = append(labels, torrent.Category)
+	}
+	labels = append(labels, torrent.Tags...)
+
+	state := models.NormalizeState(torrent.State)
+	errorCode, errorString := 0, ""
+	if state == models.StateError {
+		errorCode, errorString = 3, "error reported by client "+torrent.ClientID
+	}
+
+	return map[string]interface{}{
+		"id":                      id,
+		"hashString":              strings.ToLower(torrent.Hash),
+		"name":                    torrent.Name,
+		"totalSize":               torrent.Size,
+		"sizeWhenDone":            torrent.Size,
+		"leftUntilDone":           left,
+		"haveValid":               torrent.Size - left,
+		"percentDone":             torrent.Progress,
+		"status":                  transmissionStatus(state, torrent.Progress),
+		"rateDownload":            torrent.DownloadSpeed,
+		"rateUpload":              torrent.UploadSpeed,
+		"downloadedEver":          torrent.Downloaded,
+		"uploadedEver":            torrent.Uploaded,
+		"uploadRatio":             torrent.Ratio,
+		"eta":                     eta,
+		"isFinished":              false,
+		"error":                   errorCode,
+		"errorString":             errorString,
+		"labels":                  labels,
+		"downloadDir":             torrent.SavePath,
+		"secondsSeeding":          torrent.SeedingTime,
+		"queuePosition":           0,
+		"addedDate":               0,
+		"peersConnected":          0,
+		"metadataPercentComplete": 1,
+	}
+}
+
+// transmissionStatus 将归一化状态映射为 Transmission 的状态码
+func transmissionStatus(state string, progress float64) int {
+	done := progress >= 1
+	switch state {
+	case models.StatePaused, models.StateError:
+		return transmissionStatusStopped
+	case models.StateChecking:
+		return transmissionStatusCheck
+	case models.StateQueued:
+		if done {
+			return transmissionStatusSeedWait
+		}
+		return transmissionStatusDownloadWait
+	case models.StateSeeding:
+		return transmissionStatusSeed
+	case models.StateDownloading:
+		return transmissionStatusDownload
+	default:
+		if done {
+			return transmissionStatusSeed
+		}
+		return transmissionStatusDownload
+	}
+}
+
+// torrentAdd 通过 filename（磁力链接或种子 URL）或 metainfo（base64 编码的种子文件）添加种子
+// download-dir 不受支持，保存路径由目标客户端或分类决定；labels 作为标签添加
+func (h *TransmissionFacadeHandler) torrentAdd(service *core.TorrentService, raw json.RawMessage) (gin.H, error) {
+	var args struct {
+		Filename string   `json:"filename"`
+		MetaInfo string   `json:"metainfo"`
+		Paused   bool     `json:"paused"`
+		Labels   []string `json:"labels"`
+	}
+	if err := decodeArguments(raw, &args); err != nil {
+		return nil, err
+	}
+
+	options := core.AddOptions{ClientID: h.options.DefaultClient}
+	switch {
+	case args.MetaInfo != "":
+		data, err := base64.StdEncoding.DecodeString(args.MetaInfo)
+		if err != nil {
+			return nil, errors.New("invalid or corrupt torrent file")
+		}
+		options.Data = data
+	case args.Filename != "":
+		options.Link = args.Filename
+	default:
+		return nil, errors.New("no filename or metainfo specified")
+	}
+	result, err := service.AddWithOptions(options)
+	clientID, hash := result.ClientID, result.Hash
+	// 与 Transmission 一样，重复的种子以 torrent-duplicate 返回成功
+	var duplicate *core.DuplicateTorrentError
+	if errors.As(err, &duplicate) {
+		if copies := service.FindDuplicates(duplicate.Hash, ""); len(copies) > 0 {
+			return gin.H{
+				"torrent-duplicate": gin.H{
+					"id":         h.ids.id(copies[0].ClientID, copies[0].Hash),
+					"hashString": duplicate.Hash,
+					"name":       copies[0].Name,
+				},
+			}, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hash == "" {
+		return gin.H{}, nil
+	}
+
+	refs := []models.TorrentRef{{ClientID: clientID, Hash: hash}}
+	if len(args.Labels) > 0 {
+		if err := service.AddTags(refs, args.Labels); err != nil {
+			log.Printf("⚠️  Transmission 兼容接口添加标签失败 [%s]: %v", clientID, err)
+		}
+	}
+	if args.Paused {
+		if err := service.PauseTorrent(clientID, hash); err != nil {
+			log.Printf("⚠️  Transmission 兼容接口暂停种子失败 [%s]: %v", clientID, err)
+		}
+	}
+
+	return gin.H{
+		"torrent-added": gin.H{
+			"id":         h.ids.id(clientID, hash),
+			"hashString": strings.ToLower(hash),
+			"name":       hash,
+		},
+	}, nil
+}
+
+// forEachTorrent 对 ids 指定的种子执行操作，省略 ids 表示所有种子，返回最后一个错误
+func (h *TransmissionFacadeHandler) forEachTorrent(service *core.TorrentService, raw json.RawMessage, action func(models.TorrentRef) error) error {
+	var args struct {
+		IDs json.RawMessage `json:"ids"`
+	}
+	if err := decodeArguments(raw, &args); err != nil {
+		return err
+	}
+	selector, err := parseTransmissionIDs(args.IDs, false)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, torrent := range service.GetAllTorrents() {
+		id := h.ids.id(torrent.ClientID, torrent.Hash)
+		if !selector.match(id, torrent.Hash) {
+			continue
+		}
+		if ref, ok := h.ids.ref(id); ok {
+			if err := action(ref); err != nil {
+				lastErr = err
+			}
+		}
+	}
+	return lastErr
+}
+
+// transmissionSelector ids 参数：省略或 recently-active 表示所有种子，否则为数字 ID 与哈希的集合
+type transmissionSelector struct {
+	all            bool
+	recentlyActive bool
+	ids            map[int64]bool
+	hashes         map[string]bool
+}
+
+func (s transmissionSelector) match(id int64, hash string) bool {
+	return s.all || s.ids[id] || s.hashes[strings.ToLower(hash)]
+}
+
+// parseTransmissionIDs 解析 ids 参数，支持单个数字、recently-active 以及数字与哈希混合的数组
+// recently-active 按所有种子处理，只有 torrent-get 这样的只读方法才能使用（allowRecent），
+// 否则 torrent-remove 等方法会误作用于全部种子
+func parseTransmissionIDs(raw json.RawMessage, allowRecent bool) (transmissionSelector, error) {
+	selector := transmissionSelector{ids: make(map[int64]bool), hashes: make(map[string]bool)}
+	if len(raw) == 0 || string(raw) == "null" {
+		selector.all = true
+		return selector, nil
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		if single == "recently-active" {
+			if !allowRecent {
+				return selector, errors.New("recently-active is only supported by torrent-get")
+			}
+			selector.all = true
+			selector.recentlyActive = true
+		} else {
+			selector.hashes[strings.ToLower(single)] = true
+		}
+		return selector, nil
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err != nil {
+		list = []json.RawMessage{raw}
+	}
+	for _, item := range list {
+		var id int64
+		if err := json.Unmarshal(item, &id); err == nil {
+			selector.ids[id] = true
+			continue
+		}
+		var hash string
+		if err := json.Unmarshal(item, &hash); err == nil {
+			selector.hashes[strings.ToLower(hash)] = true
+			continue
+		}
+		return selector, errors.New("invalid ids argument")
+	}
+	return selector, nil
+}
+
+// decodeArguments 解析 RPC 参数，参数为空时保持零值
+func decodeArguments(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return errors.New("invalid arguments: " + err.Error())
+	}
+	return nil
+}
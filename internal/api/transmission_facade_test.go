@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseTransmissionIDs(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		allowRecent bool
+		want        transmissionSelector
+		wantErr     bool
+	}{
+		{name: "omitted", raw: "", want: transmissionSelector{all: true}},
+		{name: "null", raw: "null", want: transmissionSelector{all: true}},
+		{name: "single id", raw: "3", want: transmissionSelector{ids: map[int64]bool{3: true}}},
+		{name: "single hash", raw: `"ABC"`, want: transmissionSelector{hashes: map[string]bool{"abc": true}}},
+		{name: "mixed list", raw: `[1, "ABC", 2]`, want: transmissionSelector{ids: map[int64]bool{1: true, 2: true}, hashes: map[string]bool{"abc": true}}},
+		{name: "recently active", raw: `"recently-active"`, allowRecent: true, want: transmissionSelector{all: true, recentlyActive: true}},
+		{name: "recently active not allowed", raw: `"recently-active"`, wantErr: true},
+		{name: "invalid item", raw: `[1, {"id": 2}]`, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseTransmissionIDs(json.RawMessage(test.raw), test.allowRecent)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("parseTransmissionIDs(%s) error = nil, want error", test.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTransmissionIDs(%s) error = %v", test.raw, err)
+			}
+			if test.want.ids == nil {
+				test.want.ids = map[int64]bool{}
+			}
+			if test.want.hashes == nil {
+				test.want.hashes = map[string]bool{}
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseTransmissionIDs(%s) = %+v, want %+v", test.raw, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTransmissionStatus(t *testing.T) {
+	tests := []struct {
+		state    string
+		progress float64
+		want     int
+	}{
+		{models.StatePaused, 0.5, transmissionStatusStopped},
+		{models.StateError, 0.5, transmissionStatusStopped},
+		{models.StateChecking, 0.5, transmissionStatusCheck},
+		{models.StateQueued, 0.5, transmissionStatusDownloadWait},
+		{models.StateQueued, 1, transmissionStatusSeedWait},
+		{models.StateDownloading, 0.5, transmissionStatusDownload},
+		{models.StateSeeding, 1, transmissionStatusSeed},
+		{models.StateStalled, 0.5, transmissionStatusDownload},
+		{models.StateStalled, 1, transmissionStatusSeed},
+	}
+	for _, test := range tests {
+		if got := transmissionStatus(test.state, test.progress); got != test.want {
+			t.Errorf("transmissionStatus(%s, %v) = %d, want %d", test.state, test.progress, got, test.want)
+		}
+	}
+}
+
+func TestTransmissionIDsPrune(t *testing.T) {
+	ids := newTransmissionIDs()
+	first := ids.id("qb-1", "AAA")
+	second := ids.id("tr-1", "bbb")
+	if again := ids.id("qb-1", "aaa"); again != first {
+		t.Errorf("id() = %d for the same torrent, want %d", again, first)
+	}
+
+	ids.prune(func() []models.UnifiedTorrent {
+		return []models.UnifiedTorrent{{ClientID: "tr-1", Hash: "BBB"}}
+	})
+	if _, ok := ids.ref(first); ok {
+		t.Errorf("ref(%d) found after the torrent was removed", first)
+	}
+	if ref, ok := ids.ref(second); !ok || ref.ClientID != "tr-1" {
+		t.Errorf("ref(%d) = %+v, %t, want tr-1", second, ref, ok)
+	}
+	if id := ids.id("qb-1", "aaa"); id == first {
+		t.Errorf("id() reused pruned ID %d", id)
+	}
+}
+
+func TestTransmissionFacadeSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auth, _, apiKey := newTestAuth(t)
+	handler := NewTransmissionFacadeHandler(core.NewTorrentService(nil, newTestDB(t)), auth, TransmissionFacadeOptions{})
+
+	router := gin.New()
+	router.POST("/transmission/rpc", handler.RequireSession(), handler.RPC)
+
+	tests := []struct {
+		name       string
+		password   string
+		sessionID  string
+		wantStatus int
+	}{
+		{"password", "correct horse", handler.sessionID, http.StatusOK},
+		{"api key as password", apiKey, handler.sessionID, http.StatusOK},
+		{"wrong password", "wrong", handler.sessionID, http.StatusUnauthorized},
+		{"missing session id", "correct horse", "", http.StatusConflict},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/transmission/rpc", strings.NewReader(`{"method":"session-get","tag":7}`))
+			request.SetBasicAuth("alice", test.password)
+			if test.sessionID != "" {
+				request.Header.Set(transmissionSessionHeader, test.sessionID)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+
+			switch test.wantStatus {
+			case http.StatusConflict:
+				if recorder.Header().Get(transmissionSessionHeader) != handler.sessionID {
+					t.Errorf("409 response does not carry the session id")
+				}
+			case http.StatusOK:
+				var response transmissionResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if response.Result != "success" || string(response.Tag) != "7" {
+					t.Errorf("response = %+v, want success with tag 7", response)
+				}
+			}
+		})
+	}
+}
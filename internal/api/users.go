@@ -0,0 +1,202 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	auth *core.AuthService
+}
+
+func NewUserHandler(auth *core.AuthService) *UserHandler {
+	return &UserHandler{
+		auth: auth,
+	}
+}
+
+// CreateUserRequest 创建用户的请求结构，role 可选 admin、operator、viewer
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+// UpdateUserRequest 修改用户的请求结构，未提供的字段保持不变
+type UpdateUserRequest struct {
+	Role     *string `json:"role"`
+	Password *string `json:"password"`
+}
+
+// GrantRequest 单条授权，clientID 为 * 表示所有客户端
+type GrantRequest struct {
+	ClientID string   `json:"clientID" binding:"required"`
+	Actions  []string `json:"actions" binding:"required"`
+}
+
+// SetGrantsRequest 替换用户授权的请求结构，空列表表示取消客户端限制
+type SetGrantsRequest struct {
+	Grants []GrantRequest `json:"grants"`
+}
+
+// ListUsers 获取所有用户的处理器
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	users, err := h.auth.ListUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get users: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    users,
+		"count":   len(users),
+	})
+}
+
+// CreateUser 创建用户的处理器
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	user, err := h.auth.CreateUser(currentAccess(c), req.Username, req.Password, req.Role)
+	if err != nil {
+		respondError(c, "Failed to create user: ", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "User created successfully",
+		"data":    user,
+	})
+}
+
+// UpdateUser 修改用户角色或重置密码的处理器
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	user, err := h.auth.UpdateUser(currentAccess(c), id, req.Role, req.Password)
+	if err != nil {
+		respondError(c, "Failed to update user: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "User updated successfully",
+		"data":    user,
+	})
+}
+
+// DeleteUser 删除用户的处理器
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.auth.DeleteUser(currentAccess(c), id); err != nil {
+		respondError(c, "Failed to delete user: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "User deleted successfully",
+	})
+}
+
+// SetGrants 替换用户客户端授权的处理器
+func (h *UserHandler) SetGrants(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req SetGrantsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	grants := make([]models.UserGrant, 0, len(req.Grants))
+	seen := make(map[string]bool)
+	for _, g := range req.Grants {
+		if seen[g.ClientID] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid grant: duplicate client " + g.ClientID,
+			})
+			return
+		}
+		seen[g.ClientID] = true
+
+		grant := models.UserGrant{
+			ClientID: g.ClientID,
+			Actions:  strings.Join(g.Actions, ","),
+		}
+		if err := grant.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid grant: " + err.Error(),
+			})
+			return
+		}
+		grants = append(grants, grant)
+	}
+
+	user, err := h.auth.SetGrants(currentAccess(c), id, grants)
+	if err != nil {
+		respondError(c, "Failed to set grants: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Grants updated successfully",
+		"data":    user,
+	})
+}
+
+// parseUserID 解析路径中的用户 ID，失败时直接写入 400 响应
+func parseUserID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid user id: " + c.Param("id"),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultIngestionLimit 查询处理结果时默认返回的条数
+const defaultIngestionLimit = 100
+
+type WatchFolderHandler struct {
+	watcher *core.FolderWatcher
+}
+
+func NewWatchFolderHandler(w *core.FolderWatcher) *WatchFolderHandler {
+	return &WatchFolderHandler{
+		watcher: w,
+	}
+}
+
+// WatchFolderRequest 创建或更新监视目录的请求结构
+// clientID 为空时按目标客户端选择策略选择；poll 为 true 时使用轮询，适用于网络挂载
+type WatchFolderRequest struct {
+	Path     string `json:"path" binding:"required"`
+	ClientID string `json:"clientID"`
+	Category string `json:"category"`
+	Poll     bool   `json:"poll"`
+	Enabled  *bool  `json:"enabled"`
+}
+
+// toModel 将请求转换为监视目录模型，未指定 enabled 时默认启用
+func (r *WatchFolderRequest) toModel() *models.WatchFolder {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return &models.WatchFolder{
+		Path:     r.Path,
+		ClientID: r.ClientID,
+		Category: r.Category,
+		Poll:     r.Poll,
+		Enabled:  enabled,
+	}
+}
+
+// ListFolders 获取所有监视目录的处理器
+func (h *WatchFolderHandler) ListFolders(c *gin.Context) {
+	folders, err := h.watcher.ListFolders()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get watch folders: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    folders,
+		"count":   len(folders),
+	})
+}
+
+// GetFolder 获取单个监视目录的处理器
+func (h *WatchFolderHandler) GetFolder(c *gin.Context) {
+	id, ok := parseFolderID(c)
+	if !ok {
+		return
+	}
+
+	folder, err := h.watcher.GetFolder(id)
+	if err != nil {
+		respondError(c, "Failed to get watch folder: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    folder,
+	})
+}
+
+// CreateFolder 创建监视目录的处理器
+func (h *WatchFolderHandler) CreateFolder(c *gin.Context) {
+	var req WatchFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	folder := req.toModel()
+	if err := h.watcher.CreateFolder(currentAccess(c), folder); err != nil {
+		respondError(c, "Failed to create watch folder: ", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Watch folder created successfully",
+		"data":    folder,
+	})
+}
+
+// UpdateFolder 更新监视目录的处理器
+func (h *WatchFolderHandler) UpdateFolder(c *gin.Context) {
+	id, ok := parseFolderID(c)
+	if !ok {
+		return
+	}
+
+	var req WatchFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	folder := req.toModel()
+	if err := h.watcher.UpdateFolder(currentAccess(c), id, folder); err != nil {
+		respondError(c, "Failed to update watch folder: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Watch folder updated successfully",
+		"data":    folder,
+	})
+}
+
+// DeleteFolder 删除监视目录的处理器
+func (h *WatchFolderHandler) DeleteFolder(c *gin.Context) {
+	id, ok := parseFolderID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.watcher.DeleteFolder(currentAccess(c), id); err != nil {
+		respondError(c, "Failed to delete watch folder: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Watch folder deleted successfully",
+	})
+}
+
+// ListIngestions 获取监视目录最近处理结果的处理器，limit 默认 100
+func (h *WatchFolderHandler) ListIngestions(c *gin.Context) {
+	id, ok := parseFolderID(c)
+	if !ok {
+		return
+	}
+
+	limit := defaultIngestionLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid limit: " + value,
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	ingestions, err := h.watcher.ListIngestions(id, limit)
+	if err != nil {
+		respondError(c, "Failed to get watch folder history: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    ingestions,
+		"count":   len(ingestions),
+	})
+}
+
+// parseFolderID 解析路径中的监视目录 ID，失败时直接写入 400 响应
+func parseFolderID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid watch folder id: " + c.Param("id"),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
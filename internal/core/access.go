@@ -0,0 +1,133 @@
+package core
+
+import (
+	"down-nexus-api/internal/models"
+)
+
+// Access 当前请求用户的访问权限
+// nil 表示内部调用，不做任何限制
+type Access struct {
+	UserID   uint
+	Username string
+	Role     string
+	// SourceIP 请求来源地址，用于审计日志
+	SourceIP string
+	// grants 每个客户端允许的操作，为 nil 表示角色权限作用于所有客户端
+	grants map[string]map[string]bool
+}
+
+// NewAccess 根据用户的角色与授权计算访问权限
+func NewAccess(user *models.User) *Access {
+	access := &Access{UserID: user.ID, Username: user.Username, Role: user.Role}
+	if user.Role == models.RoleAdmin || len(user.Grants) == 0 {
+		return access
+	}
+
+	access.grants = make(map[string]map[string]bool)
+	for _, grant := range user.Grants {
+		actions, ok := access.grants[grant.ClientID]
+		if !ok {
+			actions = make(map[string]bool)
+			access.grants[grant.ClientID] = actions
+		}
+		for _, action := range grant.ActionList() {
+			actions[action] = true
+		}
+	}
+	return access
+}
+
+// IsAdmin 判断是否为管理员（或内部调用）
+func (a *Access) IsAdmin() bool {
+	return a == nil || a.Role == models.RoleAdmin
+}
+
+// Can 判断是否允许在客户端上执行操作
+func (a *Access) Can(clientID, action string) bool {
+	if a.IsAdmin() {
+		return true
+	}
+	if !roleAllows(a.Role, action) {
+		return false
+	}
+	if a.grants == nil {
+		return true
+	}
+	return a.grants[clientID][action] || a.grants[models.GrantAllClients][action]
+}
+
+func roleAllows(role, action string) bool {
+	for _, a := range models.RoleActions[role] {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// WithAccess 返回以指定用户权限执行操作的服务视图
+// 视图与原服务共享客户端、缓存与订阅，所有操作都会按权限检查，种子列表只包含可见客户端
+func (ts *TorrentService) WithAccess(access *Access) *TorrentService {
+	scoped := *ts
+	scoped.access = access
+	return &scoped
+}
+
+// authorize 检查当前权限是否允许在客户端上执行操作
+func (ts *TorrentService) authorize(clientID, action string) error {
+	if !ts.access.Can(clientID, action) {
+		return &PermissionDeniedError{ClientID: clientID, Action: action}
+	}
+	return nil
+}
+
+// requireAdmin 检查当前权限是否为管理员，用于不属于单个客户端的全局配置
+func (ts *TorrentService) requireAdmin(action string) error {
+	if !ts.access.IsAdmin() {
+		return &PermissionDeniedError{Action: action}
+	}
+	return nil
+}
+
+// visibleClients 返回当前权限可见的客户端 ID
+func (ts *TorrentService) visibleClients() []string {
+	return ts.ClientIDs(models.ActionView)
+}
+
+// ClientIDs 返回当前权限允许执行 action 的已连接客户端 ID，按加载顺序排列
+func (ts *TorrentService) ClientIDs(action string) []string {
+	var clientIDs []string
+	for _, client := range ts.clients {
+		if ts.access.Can(client.GetClientID(), action) {
+			clientIDs = append(clientIDs, client.GetClientID())
+		}
+	}
+	return clientIDs
+}
+
+// filterTorrents 过滤掉当前权限不可见客户端的种子
+func (ts *TorrentService) filterTorrents(torrents []models.UnifiedTorrent) []models.UnifiedTorrent {
+	if ts.access.IsAdmin() {
+		return torrents
+	}
+	visible := make([]models.UnifiedTorrent, 0, len(torrents))
+	for _, torrent := range torrents {
+		if ts.access.Can(torrent.ClientID, models.ActionView) {
+			visible = append(visible, torrent)
+		}
+	}
+	return visible
+}
+
+// PermissionDeniedError 无权执行操作
+type PermissionDeniedError struct {
+	ClientID string
+	Action   string
+}
+
+func (e *PermissionDeniedError) Error() string {
+	if e.ClientID == "" {
+		return "permission denied: " + e.Action
+	}
+	return "permission denied: " + e.Action + " on client " + e.ClientID
+}
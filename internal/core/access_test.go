@@ -0,0 +1,135 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestAccessCan(t *testing.T) {
+	tests := []struct {
+		name     string
+		access   *Access
+		clientID string
+		action   string
+		want     bool
+	}{
+		{"internal call", nil, "qb-1", models.ActionManage, true},
+		{"admin ignores grants", NewAccess(&models.User{Role: models.RoleAdmin, Grants: []models.UserGrant{{ClientID: "tr-1", Actions: "view"}}}), "qb-1", models.ActionManage, true},
+		{"viewer can view", NewAccess(&models.User{Role: models.RoleViewer}), "qb-1", models.ActionView, true},
+		{"viewer cannot pause", NewAccess(&models.User{Role: models.RoleViewer}), "qb-1", models.ActionPause, false},
+		{"operator can delete", NewAccess(&models.User{Role: models.RoleOperator}), "qb-1", models.ActionDelete, true},
+		{"operator cannot delete files", NewAccess(&models.User{Role: models.RoleOperator}), "qb-1", models.ActionDeleteFiles, false},
+		{"operator cannot manage", NewAccess(&models.User{Role: models.RoleOperator}), "qb-1", models.ActionManage, false},
+		{
+			name:     "granted client",
+			access:   NewAccess(&models.User{Role: models.RoleOperator, Grants: []models.UserGrant{{ClientID: "qb-1", Actions: "view,pause"}}}),
+			clientID: "qb-1",
+			action:   models.ActionPause,
+			want:     true,
+		},
+		{
+			name:     "action outside the grant",
+			access:   NewAccess(&models.User{Role: models.RoleOperator, Grants: []models.UserGrant{{ClientID: "qb-1", Actions: "view,pause"}}}),
+			clientID: "qb-1",
+			action:   models.ActionAdd,
+			want:     false,
+		},
+		{
+			name:     "client outside the grants",
+			access:   NewAccess(&models.User{Role: models.RoleOperator, Grants: []models.UserGrant{{ClientID: "qb-1", Actions: "view"}}}),
+			clientID: "tr-1",
+			action:   models.ActionView,
+			want:     false,
+		},
+		{
+			name:     "grant for all clients",
+			access:   NewAccess(&models.User{Role: models.RoleOperator, Grants: []models.UserGrant{{ClientID: models.GrantAllClients, Actions: "view"}}}),
+			clientID: "tr-1",
+			action:   models.ActionView,
+			want:     true,
+		},
+		{
+			name:     "grant cannot exceed the role",
+			access:   NewAccess(&models.User{Role: models.RoleViewer, Grants: []models.UserGrant{{ClientID: "qb-1", Actions: "view,delete_files"}}}),
+			clientID: "qb-1",
+			action:   models.ActionDeleteFiles,
+			want:     false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.access.Can(test.clientID, test.action); got != test.want {
+				t.Errorf("Can(%q, %q) = %t, want %t", test.clientID, test.action, got, test.want)
+			}
+		})
+	}
+}
+
+func TestWithAccess(t *testing.T) {
+	qb := &fakeClient{id: "qb-1", torrents: []models.UnifiedTorrent{{ClientID: "qb-1", Hash: testHashA}}}
+	tr := &fakeClient{id: "tr-1", torrents: []models.UnifiedTorrent{{ClientID: "tr-1", Hash: testHashB}}}
+	service := NewTorrentService([]clients.DownloaderClient{qb, tr}, newTestDB(t))
+	scoped := service.WithAccess(NewAccess(&models.User{
+		Role:   models.RoleOperator,
+		Grants: []models.UserGrant{{ClientID: "qb-1", Actions: "view,pause"}},
+	}))
+
+	torrents := scoped.GetAllTorrents()
+	if len(torrents) != 1 || torrents[0].ClientID != "qb-1" {
+		t.Errorf("GetAllTorrents() = %+v, want only qb-1 torrents", torrents)
+	}
+	if all := service.GetAllTorrents(); len(all) != 2 {
+		t.Errorf("unscoped GetAllTorrents() returned %d torrents, want 2", len(all))
+	}
+
+	tests := []struct {
+		name     string
+		clientID string
+		hash     string
+		wantErr  bool
+	}{
+		{"granted client", "qb-1", testHashA, false},
+		{"client outside the grants", "tr-1", testHashB, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := scoped.PauseTorrent(test.clientID, test.hash)
+			var denied *PermissionDeniedError
+			if test.wantErr != errors.As(err, &denied) {
+				t.Errorf("PauseTorrent() error = %v, want PermissionDeniedError %t", err, test.wantErr)
+			}
+		})
+	}
+	if err := scoped.CreateCategory(&models.Category{Name: "tv"}); err == nil {
+		t.Error("CreateCategory() as operator succeeded, want PermissionDeniedError")
+	}
+}
+
+func TestLastAdminCannotBeRemoved(t *testing.T) {
+	auth, admin := newTestAuth(t)
+	operator, err := auth.CreateUser(nil, "bob", testPassword, models.RoleOperator)
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	viewer := models.RoleViewer
+	var lastAdmin *LastAdminError
+	if _, err := auth.UpdateUser(nil, admin.ID, &viewer, nil); !errors.As(err, &lastAdmin) {
+		t.Errorf("UpdateUser(demote last admin) error = %v, want LastAdminError", err)
+	}
+	if err := auth.DeleteUser(nil, admin.ID); !errors.As(err, &lastAdmin) {
+		t.Errorf("DeleteUser(last admin) error = %v, want LastAdminError", err)
+	}
+	if err := auth.DeleteUser(nil, operator.ID); err != nil {
+		t.Errorf("DeleteUser(operator) error = %v", err)
+	}
+
+	invalid := "root"
+	var invalidRole *InvalidRoleError
+	if _, err := auth.UpdateUser(nil, admin.ID, &invalid, nil); !errors.As(err, &invalidRole) {
+		t.Errorf("UpdateUser(invalid role) error = %v, want InvalidRoleError", err)
+	}
+}
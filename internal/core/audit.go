@@ -0,0 +1,108 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// AuditQuery 审计日志查询条件，零值字段表示不过滤
+type AuditQuery struct {
+	From     time.Time
+	To       time.Time
+	Username string
+	Action   string
+	ClientID string
+	Hash     string
+	Success  *bool
+	// Limit 为 0 表示不限制条数
+	Limit  int
+	Offset int
+}
+
+// audit 以当前视图的身份记录一次修改操作，err 为 nil 表示成功
+func (ts *TorrentService) audit(action, clientID, hash string, params map[string]interface{}, err error) {
+	Audit(ts.db, ts.access, action, clientID, hash, params, err)
+}
+
+// Audit 以 access 的身份记录一次修改操作，access 为 nil 表示内部调用，err 为 nil 表示成功
+// 供不经过 TorrentService 权限视图的服务（用户、调度规则、监视目录等）使用；写入失败只记录日志，不影响操作本身
+func Audit(db *gorm.DB, access *Access, action, clientID, hash string, params map[string]interface{}, err error) {
+	if db == nil {
+		return
+	}
+
+	entry := models.AuditLog{
+		Username: models.AuditActorSystem,
+		Action:   action,
+		ClientID: clientID,
+		Hash:     strings.ToLower(hash),
+		Success:  err == nil,
+	}
+	if access != nil {
+		entry.UserID = access.UserID
+		entry.Username = access.Username
+		entry.SourceIP = access.SourceIP
+	}
+	if len(params) > 0 {
+		if data, marshalErr := json.Marshal(params); marshalErr == nil {
+			entry.Params = string(data)
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if dbErr := db.Create(&entry).Error; dbErr != nil {
+		log.Printf("⚠️  写入审计日志失败 [%s %s/%s]: %v", action, clientID, hash, dbErr)
+	}
+}
+
+// ListAuditLogs 按条件查询审计日志，按时间倒序返回，同时返回符合条件的总数
+func (ts *TorrentService) ListAuditLogs(query AuditQuery) ([]models.AuditLog, int64, error) {
+	if err := ts.requireAdmin("view audit log"); err != nil {
+		return nil, 0, err
+	}
+
+	db := ts.db.Model(&models.AuditLog{})
+	if !query.From.IsZero() {
+		db = db.Where("created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("created_at < ?", query.To)
+	}
+	if query.Username != "" {
+		db = db.Where("username = ?", query.Username)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.ClientID != "" {
+		db = db.Where("client_id = ?", query.ClientID)
+	}
+	if query.Hash != "" {
+		db = db.Where("hash = ?", strings.ToLower(query.Hash))
+	}
+	if query.Success != nil {
+		db = db.Where("success = ?", *query.Success)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	db = db.Order("created_at DESC, id DESC").Offset(query.Offset)
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+	var logs []models.AuditLog
+	if err := db.Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestTorrentOperationsAreAudited(t *testing.T) {
+	client := &fakeClient{id: "qb-1"}
+	service := NewTorrentService([]clients.DownloaderClient{client}, newTestDB(t))
+	viewer := service.WithAccess(&Access{UserID: 7, Username: "bob", Role: models.RoleViewer, SourceIP: "10.0.0.1"})
+
+	service.PauseTorrent("qb-1", strings.ToUpper(testHashA))
+	viewer.DeleteTorrent("qb-1", testHashA, true)
+
+	logs, total, err := service.ListAuditLogs(AuditQuery{})
+	if err != nil {
+		t.Fatalf("ListAuditLogs() error = %v", err)
+	}
+	if total != 2 || len(logs) != 2 {
+		t.Fatalf("ListAuditLogs() = %d logs, total %d, want 2", len(logs), total)
+	}
+	// 按时间倒序返回
+	denied, paused := logs[0], logs[1]
+	if paused.Username != models.AuditActorSystem || paused.Action != models.AuditActionPause || paused.Hash != testHashA || !paused.Success {
+		t.Errorf("pause entry = %+v", paused)
+	}
+	if denied.Username != "bob" || denied.UserID != 7 || denied.SourceIP != "10.0.0.1" || denied.Success ||
+		denied.Params != `{"deleteFiles":true}` || !strings.Contains(denied.Error, "permission denied") {
+		t.Errorf("denied entry = %+v", denied)
+	}
+}
+
+func TestListAuditLogs(t *testing.T) {
+	db := newTestDB(t)
+	service := NewTorrentService(nil, db)
+	failure := errors.New("failure")
+	bob := &Access{UserID: 7, Username: "bob", Role: models.RoleOperator}
+	Audit(db, nil, models.AuditActionPause, "qb-1", testHashA, nil, nil)
+	Audit(db, bob, models.AuditActionAdd, "qb-1", testHashB, nil, nil)
+	Audit(db, bob, models.AuditActionDelete, "tr-1", testHashB, nil, failure)
+	Audit(db, nil, models.AuditActionConfig, "", "", map[string]interface{}{"name": "tv"}, nil)
+
+	yes, no := true, false
+	tests := []struct {
+		name    string
+		query   AuditQuery
+		want    []string
+		wantAll int64
+	}{
+		{"all", AuditQuery{}, []string{"config", "delete", "add", "pause"}, 4},
+		{"by user", AuditQuery{Username: "bob"}, []string{"delete", "add"}, 2},
+		{"by action", AuditQuery{Action: models.AuditActionAdd}, []string{"add"}, 1},
+		{"by client", AuditQuery{ClientID: "qb-1"}, []string{"add", "pause"}, 2},
+		{"by hash ignores case", AuditQuery{Hash: strings.ToUpper(testHashB)}, []string{"delete", "add"}, 2},
+		{"failed only", AuditQuery{Success: &no}, []string{"delete"}, 1},
+		{"succeeded only", AuditQuery{Success: &yes}, []string{"config", "add", "pause"}, 3},
+		{"paginated", AuditQuery{Limit: 2, Offset: 1}, []string{"delete", "add"}, 4},
+		{"time range", AuditQuery{From: time.Now().Add(time.Hour)}, nil, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logs, total, err := service.ListAuditLogs(test.query)
+			if err != nil {
+				t.Fatalf("ListAuditLogs() error = %v", err)
+			}
+			var actions []string
+			for _, entry := range logs {
+				actions = append(actions, entry.Action)
+			}
+			if strings.Join(actions, ",") != strings.Join(test.want, ",") || total != test.wantAll {
+				t.Errorf("ListAuditLogs() = %v (total %d), want %v (total %d)", actions, total, test.want, test.wantAll)
+			}
+		})
+	}
+
+	var denied *PermissionDeniedError
+	if _, _, err := service.WithAccess(bob).ListAuditLogs(AuditQuery{}); !errors.As(err, &denied) {
+		t.Errorf("ListAuditLogs() as operator error = %v, want PermissionDeniedError", err)
+	}
+}
+
+func TestUserChangesAreAuditedWithoutSecrets(t *testing.T) {
+	auth, admin := newTestAuth(t)
+	access := NewAccess(admin)
+	if _, err := auth.CreateUser(access, "bob", "secret password", models.RoleViewer); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if _, _, err := auth.CreateAPIKey(access, admin.ID, "script", nil); err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+
+	logs, _, err := NewTorrentService(nil, auth.db).ListAuditLogs(AuditQuery{Action: models.AuditActionUser, Username: "alice"})
+	if err != nil {
+		t.Fatalf("ListAuditLogs() error = %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("user audit entries = %+v, want 2", logs)
+	}
+	for _, entry := range logs {
+		if strings.Contains(entry.Params, "secret password") || strings.Contains(entry.Params, apiKeyPrefix) {
+			t.Errorf("audit params leak a secret: %s", entry.Params)
+		}
+	}
+}
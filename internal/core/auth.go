@@ -0,0 +1,643 @@
+package core
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix API 密钥的固定前缀，用于区分 API 密钥与 JWT
+const apiKeyPrefix = "dnx_"
+
+// minPasswordLength 密码最短长度
+const minPasswordLength = 8
+
+// AuthService 认证服务
+// 用户密码以 bcrypt 保存，登录后签发短期访问令牌（JWT）与可轮换的刷新令牌，
+// 脚本可使用长期 API 密钥
+type AuthService struct {
+	db         *gorm.DB
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+
+	// verified 缓存 CheckCredentials 校验通过的密码，键为用户名与密码的摘要，值为当时的密码哈希
+	mutex    sync.Mutex
+	verified map[string]verifiedPassword
+}
+
+type verifiedPassword struct {
+	passwordHash string
+	expiresAt    time.Time
+}
+
+func NewAuthService(db *gorm.DB, secret []byte, accessTTL, refreshTTL time.Duration) *AuthService {
+	return &AuthService{
+		db:         db,
+		secret:     secret,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		verified:   make(map[string]verifiedPassword),
+	}
+}
+
+// EnsureAdmin 数据库中没有任何用户时创建初始管理员
+// password 为空时生成随机密码并返回，已存在用户时返回空字符串；
+// 已有用户但没有管理员时（如从未区分角色的旧版本升级），将最早创建的用户提升为管理员
+func (as *AuthService) EnsureAdmin(username, password string) (string, error) {
+	var count int64
+	if err := as.db.Model(&models.User{}).Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "", as.ensureAdminExists()
+	}
+
+	if password == "" {
+		generated, err := randomToken(12)
+		if err != nil {
+			return "", err
+		}
+		password = generated
+	}
+	if _, err := as.CreateUser(nil, username, password, models.RoleAdmin); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+func (as *AuthService) ensureAdminExists() error {
+	var admins int64
+	if err := as.db.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&admins).Error; err != nil {
+		return err
+	}
+	if admins > 0 {
+		return nil
+	}
+
+	var first models.User
+	if err := as.db.Order("id").First(&first).Error; err != nil {
+		return err
+	}
+	return as.db.Model(&first).Update("role", models.RoleAdmin).Error
+}
+
+// CreateUser 创建用户，审计日志不记录密码
+func (as *AuthService) CreateUser(access *Access, username, password, role string) (_ *models.User, err error) {
+	defer func() {
+		Audit(as.db, access, models.AuditActionUser, "", "", map[string]interface{}{"createUser": username, "role": role}, err)
+	}()
+
+	if !models.IsValidRole(role) {
+		return nil, &InvalidRoleError{Role: role}
+	}
+	if err := validatePassword(password); err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{Username: username, PasswordHash: string(hash), Role: role}
+	if err := as.db.Create(user).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ListUsers 获取所有用户及其授权
+func (as *AuthService) ListUsers() ([]models.User, error) {
+	var users []models.User
+	err := as.db.Preload("Grants").Order("id").Find(&users).Error
+	return users, err
+}
+
+// UpdateUser 修改用户的角色或重置密码，nil 表示不修改
+// 重置密码会吊销该用户所有的刷新令牌
+func (as *AuthService) UpdateUser(access *Access, id uint, role, password *string) (_ *models.User, err error) {
+	defer func() {
+		Audit(as.db, access, models.AuditActionUser, "", "", map[string]interface{}{"updateUser": id, "role": role, "resetPassword": password != nil}, err)
+	}()
+
+	user, err := as.getUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if role != nil {
+		if !models.IsValidRole(*role) {
+			return nil, &InvalidRoleError{Role: *role}
+		}
+		if user.Role == models.RoleAdmin && *role != models.RoleAdmin {
+			if err := as.checkNotLastAdmin(); err != nil {
+				return nil, err
+			}
+		}
+		updates["role"] = *role
+	}
+	if password != nil {
+		if err := validatePassword(*password); err != nil {
+			return nil, err
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		updates["password_hash"] = string(hash)
+	}
+	if len(updates) == 0 {
+		return user, nil
+	}
+
+	err = as.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(updates).Error; err != nil {
+			return err
+		}
+		if password == nil {
+			return nil
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", user.ID).
+			Update("revoked_at", time.Now()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return as.getUser(id)
+}
+
+// DeleteUser 删除用户及其授权、API 密钥和刷新令牌
+// 使用硬删除，避免软删除的记录占用唯一用户名
+func (as *AuthService) DeleteUser(access *Access, id uint) (err error) {
+	defer func() {
+		Audit(as.db, access, models.AuditActionUser, "", "", map[string]interface{}{"deleteUser": id}, err)
+	}()
+
+	user, err := as.getUser(id)
+	if err != nil {
+		return err
+	}
+	if user.Role == models.RoleAdmin {
+		if err := as.checkNotLastAdmin(); err != nil {
+			return err
+		}
+	}
+
+	return as.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", id).Delete(&models.UserGrant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", id).Delete(&models.APIKey{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.User{}, id).Error
+	})
+}
+
+// SetGrants 替换用户的全部授权，空列表表示角色权限作用于所有客户端
+func (as *AuthService) SetGrants(access *Access, id uint, grants []models.UserGrant) (_ *models.User, err error) {
+	defer func() {
+		Audit(as.db, access, models.AuditActionUser, "", "", map[string]interface{}{"setGrants": id, "grants": grants}, err)
+	}()
+
+	if _, err := as.getUser(id); err != nil {
+		return nil, err
+	}
+
+	err = as.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", id).Delete(&models.UserGrant{}).Error; err != nil {
+			return err
+		}
+		for i := range grants {
+			grants[i].ID = 0
+			grants[i].UserID = id
+		}
+		if len(grants) == 0 {
+			return nil
+		}
+		return tx.Create(&grants).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return as.getUser(id)
+}
+
+// checkNotLastAdmin 确保移除一个管理员后仍至少保留一个管理员
+func (as *AuthService) checkNotLastAdmin() error {
+	var admins int64
+	if err := as.db.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&admins).Error; err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return &LastAdminError{}
+	}
+	return nil
+}
+
+func (as *AuthService) getUser(id uint) (*models.User, error) {
+	var user models.User
+	if err := as.db.Preload("Grants").First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &UserNotFoundError{ID: id}
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Login 校验用户名和密码并签发令牌
+func (as *AuthService) Login(username, password string) (*models.TokenPair, error) {
+	user, err := as.checkPassword(username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	// 顺带清理该用户已过期或已吊销的刷新令牌
+	as.db.Where("user_id = ? AND (expires_at < ? OR revoked_at IS NOT NULL)", user.ID, time.Now()).
+		Delete(&models.RefreshToken{})
+
+	return as.issueTokens(as.db, user.ID)
+}
+
+// SessionToken 校验用户名和密码（或该用户的 API 密钥）并只签发访问令牌
+// 用于兼容其他客户端协议的会话登录，这些客户端会在会话过期后重新登录，因此不签发刷新令牌
+func (as *AuthService) SessionToken(username, password string) (string, time.Time, error) {
+	user, err := as.CheckCredentials(username, password)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return as.signAccessToken(user.ID)
+}
+
+// CheckCredentials 校验用户名和密码（或该用户的 API 密钥），用于每个请求都携带凭据的 Basic 认证
+// 校验通过的密码会缓存一段时间以避免每次请求都计算 bcrypt，但每次仍会重新读取用户并比对密码哈希，
+// 因此修改密码或删除用户后立即失效；API 密钥每次都查询数据库，吊销后立即失效
+func (as *AuthService) CheckCredentials(username, password string) (*models.User, error) {
+	if strings.HasPrefix(password, apiKeyPrefix) {
+		user, err := as.authenticateAPIKey(password)
+		if err != nil {
+			return nil, err
+		}
+		if user.Username != username {
+			return nil, &InvalidCredentialsError{}
+		}
+		return user, nil
+	}
+
+	var user models.User
+	if err := as.db.Preload("Grants").Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &InvalidCredentialsError{}
+		}
+		return nil, err
+	}
+
+	key := hashToken(username + "\x00" + password)
+	now := time.Now()
+	as.mutex.Lock()
+	cached, ok := as.verified[key]
+	as.mutex.Unlock()
+	if ok && now.Before(cached.expiresAt) && cached.passwordHash == user.PasswordHash {
+		return &user, nil
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, &InvalidCredentialsError{}
+	}
+
+	as.mutex.Lock()
+	defer as.mutex.Unlock()
+	for k, v := range as.verified {
+		if now.After(v.expiresAt) {
+			delete(as.verified, k)
+		}
+	}
+	as.verified[key] = verifiedPassword{passwordHash: user.PasswordHash, expiresAt: now.Add(as.accessTTL)}
+	return &user, nil
+}
+
+// checkPassword 校验用户名和密码
+func (as *AuthService) checkPassword(username, password string) (*models.User, error) {
+	var user models.User
+	if err := as.db.Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &InvalidCredentialsError{}
+		}
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, &InvalidCredentialsError{}
+	}
+	return &user, nil
+}
+
+// Refresh 使用刷新令牌换取新的令牌，旧的刷新令牌随即失效
+func (as *AuthService) Refresh(refreshToken string) (*models.TokenPair, error) {
+	var pair *models.TokenPair
+	err := as.db.Transaction(func(tx *gorm.DB) error {
+		var token models.RefreshToken
+		err := tx.Where("token_hash = ? AND revoked_at IS NULL AND expires_at > ?", hashToken(refreshToken), time.Now()).
+			First(&token).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &InvalidCredentialsError{}
+			}
+			return err
+		}
+
+		// 以条件更新吊销，避免同一个刷新令牌被并发使用两次
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", token.ID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &InvalidCredentialsError{}
+		}
+
+		pair, err = as.issueTokens(tx, token.UserID)
+		return err
+	})
+	return pair, err
+}
+
+// Logout 吊销刷新令牌
+func (as *AuthService) Logout(refreshToken string) error {
+	return as.db.Model(&models.RefreshToken{}).
+		Where("token_hash = ? AND revoked_at IS NULL", hashToken(refreshToken)).
+		Update("revoked_at", time.Now()).Error
+}
+
+// ChangePassword 修改密码，并吊销该用户所有的刷新令牌
+func (as *AuthService) ChangePassword(access *Access, userID uint, oldPassword, newPassword string) (err error) {
+	defer func() {
+		Audit(as.db, access, models.AuditActionUser, "", "", map[string]interface{}{"changePassword": userID}, err)
+	}()
+
+	var user models.User
+	if err := as.db.First(&user, userID).Error; err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(oldPassword)) != nil {
+		return &IncorrectPasswordError{}
+	}
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return as.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password_hash", string(hash)).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", user.ID).
+			Update("revoked_at", time.Now()).Error
+	})
+}
+
+// Authenticate 校验访问令牌或 API 密钥，返回对应的用户
+func (as *AuthService) Authenticate(token string) (*models.User, error) {
+	if strings.HasPrefix(token, apiKeyPrefix) {
+		return as.authenticateAPIKey(token)
+	}
+	return as.AuthenticateAccessToken(token)
+}
+
+// AuthenticateAccessToken 只校验短期访问令牌（JWT），不接受 API 密钥
+func (as *AuthService) AuthenticateAccessToken(token string) (*models.User, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return as.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, &InvalidCredentialsError{}
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, &InvalidCredentialsError{}
+	}
+	return as.findUser(uint(userID))
+}
+
+func (as *AuthService) authenticateAPIKey(key string) (*models.User, error) {
+	var apiKey models.APIKey
+	err := as.db.Where("key_hash = ?", hashToken(key)).First(&apiKey).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &InvalidCredentialsError{}
+		}
+		return nil, err
+	}
+	if apiKey.ExpiresAt != nil && apiKey.ExpiresAt.Before(time.Now()) {
+		return nil, &InvalidCredentialsError{}
+	}
+
+	user, err := as.findUser(apiKey.UserID)
+	if err != nil {
+		return nil, err
+	}
+	as.db.Model(&apiKey).UpdateColumn("last_used_at", time.Now())
+	return user, nil
+}
+
+func (as *AuthService) findUser(userID uint) (*models.User, error) {
+	var user models.User
+	if err := as.db.Preload("Grants").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &InvalidCredentialsError{}
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateAPIKey 为用户创建 API 密钥，返回的明文密钥只在此时可见
+func (as *AuthService) CreateAPIKey(access *Access, userID uint, name string, expiresAt *time.Time) (_ string, _ *models.APIKey, err error) {
+	defer func() {
+		Audit(as.db, access, models.AuditActionUser, "", "", map[string]interface{}{"createAPIKey": name, "userID": userID, "expiresAt": expiresAt}, err)
+	}()
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	key := apiKeyPrefix + secret
+
+	apiKey := &models.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    key[:len(apiKeyPrefix)+8],
+		KeyHash:   hashToken(key),
+		ExpiresAt: expiresAt,
+	}
+	if err := as.db.Create(apiKey).Error; err != nil {
+		return "", nil, err
+	}
+	return key, apiKey, nil
+}
+
+// ListAPIKeys 获取用户的所有 API 密钥
+func (as *AuthService) ListAPIKeys(userID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := as.db.Where("user_id = ?", userID).Order("id").Find(&keys).Error
+	return keys, err
+}
+
+// DeleteAPIKey 删除用户的 API 密钥
+func (as *AuthService) DeleteAPIKey(access *Access, userID, id uint) (err error) {
+	defer func() {
+		Audit(as.db, access, models.AuditActionUser, "", "", map[string]interface{}{"deleteAPIKey": id, "userID": userID}, err)
+	}()
+
+	result := as.db.Where("user_id = ?", userID).Delete(&models.APIKey{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &APIKeyNotFoundError{ID: id}
+	}
+	return nil
+}
+
+// issueTokens 签发访问令牌和刷新令牌
+func (as *AuthService) issueTokens(db *gorm.DB, userID uint) (*models.TokenPair, error) {
+	accessToken, expiresAt, err := as.signAccessToken(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Create(&models.RefreshToken{
+		UserID:    userID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(as.refreshTTL),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// signAccessToken 签发访问令牌（JWT）
+func (as *AuthService) signAccessToken(userID uint) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(as.accessTTL)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   strconv.FormatUint(uint64(userID), 10),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}).SignedString(as.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return &InvalidPasswordError{}
+	}
+	return nil
+}
+
+// randomToken 生成 n 字节的随机数并以十六进制编码
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// InvalidCredentialsError 用户名、密码或令牌无效
+type InvalidCredentialsError struct{}
+
+func (e *InvalidCredentialsError) Error() string {
+	return "invalid credentials"
+}
+
+// IncorrectPasswordError 修改密码时原密码错误
+// 与 InvalidCredentialsError 区分，避免客户端将其误判为会话过期
+type IncorrectPasswordError struct{}
+
+func (e *IncorrectPasswordError) Error() string {
+	return "current password is incorrect"
+}
+
+// InvalidPasswordError 密码不满足要求
+type InvalidPasswordError struct{}
+
+func (e *InvalidPasswordError) Error() string {
+	return "password must be at least " + strconv.Itoa(minPasswordLength) + " characters"
+}
+
+// InvalidRoleError 角色无效
+type InvalidRoleError struct {
+	Role string
+}
+
+func (e *InvalidRoleError) Error() string {
+	return "invalid role: " + e.Role
+}
+
+// LastAdminError 不允许移除最后一个管理员
+type LastAdminError struct{}
+
+func (e *LastAdminError) Error() string {
+	return "cannot remove the last admin"
+}
+
+// UserNotFoundError 用户不存在
+type UserNotFoundError struct {
+	ID uint
+}
+
+func (e *UserNotFoundError) Error() string {
+	return "user not found: " + strconv.FormatUint(uint64(e.ID), 10)
+}
+
+// APIKeyNotFoundError API 密钥不存在
+type APIKeyNotFoundError struct {
+	ID uint
+}
+
+func (e *APIKeyNotFoundError) Error() string {
+	return "api key not found: " + strconv.FormatUint(uint64(e.ID), 10)
+}
@@ -0,0 +1,234 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testPassword = "correct horse"
+
+func newTestAuth(t *testing.T) (*AuthService, *models.User) {
+	t.Helper()
+	auth := NewAuthService(newTestDB(t), []byte("secret"), time.Minute, time.Hour)
+	user, err := auth.CreateUser(nil, "alice", testPassword, models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	return auth, user
+}
+
+func TestLogin(t *testing.T) {
+	auth, _ := newTestAuth(t)
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  bool
+	}{
+		{"valid credentials", "alice", testPassword, false},
+		{"wrong password", "alice", "wrong password", true},
+		{"unknown user", "bob", testPassword, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pair, err := auth.Login(test.username, test.password)
+			if test.wantErr {
+				var invalid *InvalidCredentialsError
+				if !errors.As(err, &invalid) {
+					t.Errorf("Login() error = %v, want InvalidCredentialsError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Login() error = %v", err)
+			}
+			if user, err := auth.Authenticate(pair.AccessToken); err != nil || user.Username != "alice" {
+				t.Errorf("Authenticate(access token) = %v, %v, want alice", user, err)
+			}
+		})
+	}
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	auth, _ := newTestAuth(t)
+	pair, err := auth.Login("alice", testPassword)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	rotated, err := auth.Refresh(pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	var invalid *InvalidCredentialsError
+	if _, err := auth.Refresh(pair.RefreshToken); !errors.As(err, &invalid) {
+		t.Errorf("Refresh(used token) error = %v, want InvalidCredentialsError", err)
+	}
+
+	if err := auth.Logout(rotated.RefreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if _, err := auth.Refresh(rotated.RefreshToken); !errors.As(err, &invalid) {
+		t.Errorf("Refresh(revoked token) error = %v, want InvalidCredentialsError", err)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	auth, user := newTestAuth(t)
+	key, _, err := auth.CreateAPIKey(nil, user.ID, "script", nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+	expired := time.Now().Add(-time.Hour)
+	expiredKey, _, err := auth.CreateAPIKey(nil, user.ID, "old", &expired)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+	sign := func(secret string, method jwt.SigningMethod, expiresAt time.Time) string {
+		token, err := jwt.NewWithClaims(method, jwt.RegisteredClaims{
+			Subject:   "1",
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		}).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"api key", key, false},
+		{"expired api key", expiredKey, true},
+		{"unknown api key", apiKeyPrefix + "unknown", true},
+		{"access token", sign("secret", jwt.SigningMethodHS256, time.Now().Add(time.Minute)), false},
+		{"expired access token", sign("secret", jwt.SigningMethodHS256, time.Now().Add(-time.Minute)), true},
+		{"wrong secret", sign("other", jwt.SigningMethodHS256, time.Now().Add(time.Minute)), true},
+		{"wrong signing method", sign("secret", jwt.SigningMethodHS512, time.Now().Add(time.Minute)), true},
+		{"garbage", "not a token", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := auth.Authenticate(test.token)
+			if test.wantErr {
+				var invalid *InvalidCredentialsError
+				if !errors.As(err, &invalid) {
+					t.Errorf("Authenticate() error = %v, want InvalidCredentialsError", err)
+				}
+				return
+			}
+			if err != nil || got.ID != user.ID {
+				t.Errorf("Authenticate() = %v, %v, want user %d", got, err, user.ID)
+			}
+		})
+	}
+
+	// API 密钥不能用作访问令牌
+	if _, err := auth.AuthenticateAccessToken(key); err == nil {
+		t.Error("AuthenticateAccessToken(api key) succeeded, want error")
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldPassword string
+		newPassword string
+		wantErr     interface{}
+	}{
+		{"incorrect current password", "wrong password", "new password", &IncorrectPasswordError{}},
+		{"new password too short", testPassword, "short", &InvalidPasswordError{}},
+		{"success", testPassword, "new password", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			auth, user := newTestAuth(t)
+			pair, err := auth.Login("alice", testPassword)
+			if err != nil {
+				t.Fatalf("Login() error = %v", err)
+			}
+
+			err = auth.ChangePassword(nil, user.ID, test.oldPassword, test.newPassword)
+			switch want := test.wantErr.(type) {
+			case *IncorrectPasswordError:
+				if !errors.As(err, &want) {
+					t.Fatalf("ChangePassword() error = %v, want IncorrectPasswordError", err)
+				}
+				return
+			case *InvalidPasswordError:
+				if !errors.As(err, &want) {
+					t.Fatalf("ChangePassword() error = %v, want InvalidPasswordError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ChangePassword() error = %v", err)
+			}
+			if _, err := auth.Login("alice", test.newPassword); err != nil {
+				t.Errorf("Login(new password) error = %v", err)
+			}
+			// 修改密码后原有的刷新令牌全部失效
+			if _, err := auth.Refresh(pair.RefreshToken); err == nil {
+				t.Error("Refresh() after password change succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestCheckCredentials(t *testing.T) {
+	auth, user := newTestAuth(t)
+	key, apiKey, err := auth.CreateAPIKey(nil, user.ID, "script", nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		// before 在校验前修改凭据
+		before  func() error
+		wantErr bool
+	}{
+		{name: "password", username: "alice", password: testPassword},
+		{name: "cached password", username: "alice", password: testPassword},
+		{name: "api key", username: "alice", password: key},
+		{name: "api key of another user", username: "bob", password: key, wantErr: true},
+		{name: "wrong password", username: "alice", password: "wrong password", wantErr: true},
+		{
+			name: "old password after change", username: "alice", password: testPassword, wantErr: true,
+			before: func() error { return auth.ChangePassword(nil, user.ID, testPassword, "new password") },
+		},
+		{name: "new password", username: "alice", password: "new password"},
+		{
+			name: "revoked api key", username: "alice", password: key, wantErr: true,
+			before: func() error { return auth.DeleteAPIKey(nil, user.ID, apiKey.ID) },
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.before != nil {
+				if err := test.before(); err != nil {
+					t.Fatalf("before() error = %v", err)
+				}
+			}
+			got, err := auth.CheckCredentials(test.username, test.password)
+			if test.wantErr {
+				var invalid *InvalidCredentialsError
+				if !errors.As(err, &invalid) {
+					t.Errorf("CheckCredentials() error = %v, want InvalidCredentialsError", err)
+				}
+				return
+			}
+			if err != nil || got.ID != user.ID {
+				t.Errorf("CheckCredentials() = %v, %v, want alice", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+	"gorm.io/gorm"
+)
+
+// ListCategories 获取所有分类定义
+func (ts *TorrentService) ListCategories() ([]models.Category, error) {
+	var categories []models.Category
+	err := ts.db.Preload("SavePaths").Order("name").Find(&categories).Error
+	return categories, err
+}
+
+// GetCategory 根据名称获取分类定义
+func (ts *TorrentService) GetCategory(name string) (*models.Category, error) {
+	var category models.Category
+	if err := ts.db.Preload("SavePaths").Where("name = ?", name).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &CategoryNotFoundError{Name: name}
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// CreateCategory 创建分类定义并同步到各客户端，同名分类已存在时返回 CategoryExistsError
+func (ts *TorrentService) CreateCategory(category *models.Category) (err error) {
+	defer func() {
+		ts.audit(models.AuditActionConfig, "", "", map[string]interface{}{"createCategory": category}, err)
+	}()
+
+	if err := ts.requireAdmin("create category"); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := ts.db.Model(&models.Category{}).Where("name = ?", category.Name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return &CategoryExistsError{Name: category.Name}
+	}
+
+	if err := ts.db.Create(category).Error; err != nil {
+		return err
+	}
+	ts.syncCategory(category)
+	return nil
+}
+
+// UpdateCategory 替换分类在各客户端上的默认保存路径并同步到各客户端
+func (ts *TorrentService) UpdateCategory(name string, savePaths []models.CategorySavePath) (category *models.Category, err error) {
+	defer func() {
+		params := map[string]interface{}{"updateCategory": name, "savePaths": savePaths}
+		ts.audit(models.AuditActionConfig, "", "", params, err)
+	}()
+
+	if err := ts.requireAdmin("update category"); err != nil {
+		return nil, err
+	}
+
+	category, err = ts.GetCategory(name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ts.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("category_id = ?", category.ID).Delete(&models.CategorySavePath{}).Error; err != nil {
+			return err
+		}
+		for i := range savePaths {
+			savePaths[i].ID = 0
+			savePaths[i].CategoryID = category.ID
+		}
+		if len(savePaths) > 0 {
+			if err := tx.Create(&savePaths).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	category.SavePaths = savePaths
+	ts.syncCategory(category)
+	return category, nil
+}
+
+// DeleteCategory 删除分类定义，已分配到种子上的客户端分类保持不变
+// 使用硬删除以便之后可以重新创建同名分类
+func (ts *TorrentService) DeleteCategory(name string) (err error) {
+	defer func() {
+		ts.audit(models.AuditActionConfig, "", "", map[string]interface{}{"deleteCategory": name}, err)
+	}()
+
+	if err := ts.requireAdmin("delete category"); err != nil {
+		return err
+	}
+
+	category, err := ts.GetCategory(name)
+	if err != nil {
+		return err
+	}
+	return ts.db.Unscoped().Select("SavePaths").Delete(category).Error
+}
+
+// syncCategory 将分类定义推送到所有客户端，失败仅记录日志，分配种子时会再次同步
+func (ts *TorrentService) syncCategory(category *models.Category) {
+	for _, client := range ts.clients {
+		clientID := client.GetClientID()
+		if err := client.EnsureCategory(category.Name, category.SavePathFor(clientID)); err != nil {
+			log.Printf("⚠️  同步分类 %q 到客户端 [%s] 失败: %v", category.Name, clientID, err)
+		}
+	}
+}
+
+// AssignCategory 批量为种子设置分类，category 为空表示取消分类
+func (ts *TorrentService) AssignCategory(refs []models.TorrentRef, category string) error {
+	var definition *models.Category
+	if category != "" {
+		var err error
+		definition, err = ts.GetCategory(category)
+		if err != nil {
+			for _, ref := range refs {
+				ts.audit(models.AuditActionSetCategory, ref.ClientID, ref.Hash, map[string]interface{}{"category": category}, err)
+			}
+			return err
+		}
+	}
+
+	params := map[string]interface{}{"category": category}
+	return ts.forEachClient(refs, models.ActionEdit, models.AuditActionSetCategory, params, func(client clients.DownloaderClient, hashes []string) error {
+		if definition != nil {
+			if err := client.EnsureCategory(definition.Name, definition.SavePathFor(client.GetClientID())); err != nil {
+				return err
+			}
+		}
+		return client.SetCategory(hashes, category)
+	})
+}
+
+// AddTags 批量为种子添加标签
+func (ts *TorrentService) AddTags(refs []models.TorrentRef, tags []string) error {
+	params := map[string]interface{}{"addTags": tags}
+	return ts.forEachClient(refs, models.ActionEdit, models.AuditActionTags, params, func(client clients.DownloaderClient, hashes []string) error {
+		return client.AddTags(hashes, tags)
+	})
+}
+
+// RemoveTags 批量移除种子的标签
+func (ts *TorrentService) RemoveTags(refs []models.TorrentRef, tags []string) error {
+	params := map[string]interface{}{"removeTags": tags}
+	return ts.forEachClient(refs, models.ActionEdit, models.AuditActionTags, params, func(client clients.DownloaderClient, hashes []string) error {
+		return client.RemoveTags(hashes, tags)
+	})
+}
+
+// forEachClient 将种子按客户端分组后依次执行 action，汇总所有客户端的错误
+// 没有 permission 权限的客户端直接记为错误，不影响其他客户端
+// 每个种子按所在客户端的执行结果分别写入一条 auditAction 审计日志
+func (ts *TorrentService) forEachClient(refs []models.TorrentRef, permission, auditAction string, params map[string]interface{}, action func(clients.DownloaderClient, []string) error) error {
+	var order []string
+	grouped := make(map[string][]string)
+	for _, ref := range refs {
+		if _, ok := grouped[ref.ClientID]; !ok {
+			order = append(order, ref.ClientID)
+		}
+		if !containsHash(grouped[ref.ClientID], ref.Hash) {
+			grouped[ref.ClientID] = append(grouped[ref.ClientID], ref.Hash)
+		}
+	}
+
+	var errs []error
+	for _, clientID := range order {
+		client, err := ts.clientFor(clientID, permission)
+		if err != nil {
+			for _, hash := range grouped[clientID] {
+				ts.audit(auditAction, clientID, hash, params, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		// 开启归属限制时跳过无权操作的种子
+		var hashes []string
+		for _, hash := range grouped[clientID] {
+			if err := ts.AuthorizeTorrent(clientID, hash, permission); err != nil {
+				ts.audit(auditAction, clientID, hash, params, err)
+				errs = append(errs, fmt.Errorf("%s: %w", hash, err))
+				continue
+			}
+			hashes = append(hashes, hash)
+		}
+		if len(hashes) == 0 {
+			continue
+		}
+
+		err = action(client, hashes)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", clientID, err))
+		}
+		for _, hash := range hashes {
+			ts.invalidate(clientID, hash, false)
+			ts.audit(auditAction, clientID, hash, params, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func containsHash(hashes []string, hash string) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// CategoryNotFoundError 分类未定义
+type CategoryNotFoundError struct {
+	Name string
+}
+
+func (e *CategoryNotFoundError) Error() string {
+	return "category not found: " + e.Name
+}
+
+// CategoryExistsError 同名分类已存在
+type CategoryExistsError struct {
+	Name string
+}
+
+func (e *CategoryExistsError) Error() string {
+	return "category already exists: " + e.Name
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+const (
+	testHashA = "1111111111111111111111111111111111111111"
+	testHashB = "2222222222222222222222222222222222222222"
+)
+
+func TestCreateCategoryRejectsDuplicates(t *testing.T) {
+	service := NewTorrentService(nil, newTestDB(t))
+	if err := service.CreateCategory(&models.Category{Name: "tv"}); err != nil {
+		t.Fatalf("CreateCategory() error = %v", err)
+	}
+
+	err := service.CreateCategory(&models.Category{Name: "tv"})
+	var exists *CategoryExistsError
+	if !errors.As(err, &exists) {
+		t.Fatalf("CreateCategory() error = %v, want CategoryExistsError", err)
+	}
+}
+
+func TestAssignCategory(t *testing.T) {
+	qb := &fakeClient{id: "qb-1"}
+	tr := &fakeClient{id: "tr-1"}
+	service := NewTorrentService([]clients.DownloaderClient{qb, tr}, newTestDB(t))
+	category := &models.Category{Name: "tv", SavePaths: []models.CategorySavePath{{ClientID: "qb-1", SavePath: "/data/tv"}}}
+	if err := service.CreateCategory(category); err != nil {
+		t.Fatalf("CreateCategory() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		refs     []models.TorrentRef
+		category string
+		qbCalls  []string
+		trCalls  []string
+		wantErr  bool
+	}{
+		{
+			name:     "grouped by client",
+			refs:     []models.TorrentRef{{ClientID: "qb-1", Hash: testHashA}, {ClientID: "tr-1", Hash: testHashB}, {ClientID: "qb-1", Hash: testHashA}},
+			category: "tv",
+			qbCalls:  []string{"category " + testHashA + " tv"},
+			trCalls:  []string{"category " + testHashB + " tv"},
+		},
+		{
+			name:     "clear category",
+			refs:     []models.TorrentRef{{ClientID: "qb-1", Hash: testHashA}},
+			category: "",
+			qbCalls:  []string{"category " + testHashA + " "},
+		},
+		{
+			name:     "unknown category",
+			refs:     []models.TorrentRef{{ClientID: "qb-1", Hash: testHashA}},
+			category: "movies",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown client does not stop the others",
+			refs:     []models.TorrentRef{{ClientID: "missing", Hash: testHashA}, {ClientID: "tr-1", Hash: testHashB}},
+			category: "tv",
+			trCalls:  []string{"category " + testHashB + " tv"},
+			wantErr:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			qb.calls, tr.calls = nil, nil
+			err := service.AssignCategory(test.refs, test.category)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("AssignCategory() error = %v, wantErr %t", err, test.wantErr)
+			}
+			if calls := qb.Calls(); !reflect.DeepEqual(calls, test.qbCalls) {
+				t.Errorf("qb calls = %v, want %v", calls, test.qbCalls)
+			}
+			if calls := tr.Calls(); !reflect.DeepEqual(calls, test.trCalls) {
+				t.Errorf("tr calls = %v, want %v", calls, test.trCalls)
+			}
+		})
+	}
+
+	// 分类按各客户端的保存路径创建
+	if want := map[string]string{"tv": "/data/tv"}; !reflect.DeepEqual(qb.categories, want) {
+		t.Errorf("qb categories = %v, want %v", qb.categories, want)
+	}
+	if want := map[string]string{"tv": ""}; !reflect.DeepEqual(tr.categories, want) {
+		t.Errorf("tr categories = %v, want %v", tr.categories, want)
+	}
+}
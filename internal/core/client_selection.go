@@ -0,0 +1,190 @@
+package core
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+	"gorm.io/gorm"
+)
+
+// selectionHint 选择目标客户端时使用的种子信息
+type selectionHint struct {
+	category string
+	// trackers 小写的 tracker 域名
+	trackers []string
+}
+
+// GetSelectionPolicy 获取目标客户端选择策略，未配置时返回默认的轮询策略
+func (ts *TorrentService) GetSelectionPolicy() (models.ClientSelectionPolicy, error) {
+	if ts.db == nil {
+		return models.DefaultClientSelectionPolicy(), nil
+	}
+
+	var policy models.ClientSelectionPolicy
+	err := ts.db.Preload("Rules", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultClientSelectionPolicy(), nil
+	}
+	return policy, err
+}
+
+// SetSelectionPolicy 替换目标客户端选择策略，仅管理员可用
+func (ts *TorrentService) SetSelectionPolicy(policy models.ClientSelectionPolicy) (_ models.ClientSelectionPolicy, err error) {
+	defer func() {
+		ts.audit(models.AuditActionConfig, "", "", map[string]interface{}{"selectionPolicy": policy}, err)
+	}()
+
+	if err := ts.requireAdmin("set selection policy"); err != nil {
+		return policy, err
+	}
+	if err := policy.Validate(); err != nil {
+		return policy, err
+	}
+	for _, rule := range policy.Rules {
+		if _, err := ts.getClient(rule.ClientID); err != nil {
+			return policy, err
+		}
+	}
+
+	err = ts.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.ClientSelectionRule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.ClientSelectionPolicy{}).Error; err != nil {
+			return err
+		}
+		policy.ID = 0
+		for i := range policy.Rules {
+			policy.Rules[i].ID = 0
+		}
+		return tx.Create(&policy).Error
+	})
+	return policy, err
+}
+
+// SelectClientForURL 按选择策略为磁力链接或种子 URL 选择目标客户端
+// tracker 策略使用磁力链接的 tr 参数；种子 URL 使用下载地址的域名，通常即为站点域名
+func (ts *TorrentService) SelectClientForURL(magnetURL, category string) (string, error) {
+	trackers := clients.MagnetTrackers(magnetURL)
+	if len(trackers) == 0 && !strings.HasPrefix(magnetURL, "magnet:") {
+		trackers = []string{magnetURL}
+	}
+	return ts.selectClient(selectionHint{category: category, trackers: trackerHosts(trackers)})
+}
+
+// SelectClientForFile 按选择策略为 .torrent 文件选择目标客户端，tracker 策略使用文件中的 announce 地址
+func (ts *TorrentService) SelectClientForFile(data []byte, category string) (string, error) {
+	trackers, _ := clients.TorrentFileTrackers(data)
+	return ts.selectClient(selectionHint{category: category, trackers: trackerHosts(trackers)})
+}
+
+// selectClient 在当前权限允许添加种子的客户端中选择目标客户端
+// 规则指定的客户端不可用或无权添加时视为未命中
+func (ts *TorrentService) selectClient(hint selectionHint) (string, error) {
+	candidates := ts.ClientIDs(models.ActionAdd)
+	if len(candidates) == 0 {
+		return "", &PermissionDeniedError{Action: models.ActionAdd}
+	}
+
+	policy, err := ts.GetSelectionPolicy()
+	if err != nil {
+		return "", err
+	}
+
+	strategy := policy.Strategy
+	if strategy.IsRuleBased() {
+		if clientID, ok := matchSelectionRule(policy, hint, candidates); ok {
+			return clientID, nil
+		}
+		strategy = policy.FallbackStrategy()
+	}
+
+	switch strategy {
+	case models.SelectionLeastActive:
+		return ts.leastActiveClient(candidates), nil
+	case models.SelectionMostFreeSpace:
+		if clientID, ok := ts.mostFreeSpaceClient(candidates); ok {
+			return clientID, nil
+		}
+		log.Printf("⚠️  无法获取任何客户端的剩余空间，改用轮询选择目标客户端")
+	}
+	return candidates[int(ts.rotation.Add(1)-1)%len(candidates)], nil
+}
+
+// matchSelectionRule 按顺序匹配规则，返回第一个命中且可用的客户端
+func matchSelectionRule(policy models.ClientSelectionPolicy, hint selectionHint, candidates []string) (string, bool) {
+	for _, rule := range policy.Rules {
+		if !slices.Contains(candidates, rule.ClientID) {
+			continue
+		}
+		switch policy.Strategy {
+		case models.SelectionCategory:
+			if hint.category != "" && rule.Match == hint.category {
+				return rule.ClientID, true
+			}
+		case models.SelectionTracker:
+			for _, host := range hint.trackers {
+				if host == rule.Match || strings.HasSuffix(host, "."+rule.Match) {
+					return rule.ClientID, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// leastActiveClient 返回下载中种子最少的客户端，数量相同时按加载顺序
+func (ts *TorrentService) leastActiveClient(candidates []string) string {
+	active := make(map[string]int, len(candidates))
+	for _, torrent := range ts.WithAccess(nil).GetAllTorrents() {
+		if isActiveDownload(torrent) {
+			active[torrent.ClientID]++
+		}
+	}
+
+	best := candidates[0]
+	for _, clientID := range candidates[1:] {
+		if active[clientID] < active[best] {
+			best = clientID
+		}
+	}
+	return best
+}
+
+// mostFreeSpaceClient 返回剩余空间最多的客户端，查询失败的客户端不参与比较
+func (ts *TorrentService) mostFreeSpaceClient(candidates []string) (string, bool) {
+	best, bestSpace := "", int64(-1)
+	for _, clientID := range candidates {
+		client, err := ts.getClient(clientID)
+		if err != nil {
+			continue
+		}
+		space, err := client.GetFreeSpace()
+		if err != nil {
+			log.Printf("⚠️  获取客户端 [%s] 剩余空间失败: %v", clientID, err)
+			continue
+		}
+		if space > bestSpace {
+			best, bestSpace = clientID, space
+		}
+	}
+	return best, best != ""
+}
+
+// trackerHosts 提取 tracker 地址的小写域名，忽略无法解析的地址
+func trackerHosts(trackers []string) []string {
+	hosts := make([]string, 0, len(trackers))
+	for _, tracker := range trackers {
+		u, err := url.Parse(tracker)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		hosts = append(hosts, strings.ToLower(u.Hostname()))
+	}
+	return hosts
+}
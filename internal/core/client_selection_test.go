@@ -0,0 +1,108 @@
+package core
+
+import (
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestSelectClient(t *testing.T) {
+	qb := &fakeClient{id: "qb-1", free: 100, torrents: []models.UnifiedTorrent{
+		{ClientID: "qb-1", Hash: testHashA, State: "downloading", Progress: 0.5},
+	}}
+	tr := &fakeClient{id: "tr-1", free: 200}
+	service := NewTorrentService([]clients.DownloaderClient{qb, tr}, newTestDB(t))
+	magnet := func(tracker string) string {
+		return "magnet:?xt=urn:btih:" + testHashB + "&tr=" + tracker
+	}
+
+	tests := []struct {
+		name     string
+		policy   models.ClientSelectionPolicy
+		link     string
+		category string
+		want     []string
+	}{
+		{
+			name:   "round robin",
+			policy: models.ClientSelectionPolicy{Strategy: models.SelectionRoundRobin},
+			link:   magnet(""),
+			want:   []string{"qb-1", "tr-1", "qb-1"},
+		},
+		{
+			name:   "least active",
+			policy: models.ClientSelectionPolicy{Strategy: models.SelectionLeastActive},
+			link:   magnet(""),
+			want:   []string{"tr-1", "tr-1"},
+		},
+		{
+			name:   "most free space",
+			policy: models.ClientSelectionPolicy{Strategy: models.SelectionMostFreeSpace},
+			link:   magnet(""),
+			want:   []string{"tr-1"},
+		},
+		{
+			name:     "category rule",
+			policy:   models.ClientSelectionPolicy{Strategy: models.SelectionCategory, Rules: []models.ClientSelectionRule{{Match: "tv", ClientID: "tr-1"}}},
+			link:     magnet(""),
+			category: "tv",
+			want:     []string{"tr-1", "tr-1"},
+		},
+		{
+			name:   "tracker rule matches subdomains",
+			policy: models.ClientSelectionPolicy{Strategy: models.SelectionTracker, Rules: []models.ClientSelectionRule{{Match: "example.org", ClientID: "tr-1"}}},
+			link:   magnet("https://tracker.example.org/announce"),
+			want:   []string{"tr-1"},
+		},
+		{
+			name:   "torrent url host is used as tracker",
+			policy: models.ClientSelectionPolicy{Strategy: models.SelectionTracker, Rules: []models.ClientSelectionRule{{Match: "example.org", ClientID: "tr-1"}}},
+			link:   "https://example.org/download/1.torrent",
+			want:   []string{"tr-1"},
+		},
+		{
+			name:   "unmatched rule uses fallback",
+			policy: models.ClientSelectionPolicy{Strategy: models.SelectionTracker, Fallback: models.SelectionMostFreeSpace, Rules: []models.ClientSelectionRule{{Match: "example.org", ClientID: "qb-1"}}},
+			link:   magnet("https://notexample.org/announce"),
+			want:   []string{"tr-1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := service.SetSelectionPolicy(test.policy); err != nil {
+				t.Fatalf("SetSelectionPolicy() error = %v", err)
+			}
+			service.rotation.Store(0)
+			for i, want := range test.want {
+				got, err := service.SelectClientForURL(test.link, test.category)
+				if err != nil {
+					t.Fatalf("SelectClientForURL() error = %v", err)
+				}
+				if got != want {
+					t.Errorf("selection %d = %s, want %s", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectClientRespectsGrants(t *testing.T) {
+	service := NewTorrentService([]clients.DownloaderClient{&fakeClient{id: "qb-1"}, &fakeClient{id: "tr-1"}}, newTestDB(t))
+	_, err := service.SetSelectionPolicy(models.ClientSelectionPolicy{
+		Strategy: models.SelectionCategory,
+		Rules:    []models.ClientSelectionRule{{Match: "tv", ClientID: "qb-1"}},
+	})
+	if err != nil {
+		t.Fatalf("SetSelectionPolicy() error = %v", err)
+	}
+
+	user := &models.User{Role: models.RoleOperator, Grants: []models.UserGrant{{ClientID: "tr-1", Actions: models.ActionAdd}}}
+	got, err := service.WithAccess(NewAccess(user)).SelectClientForURL("magnet:?xt=urn:btih:"+testHashA, "tv")
+	if err != nil {
+		t.Fatalf("SelectClientForURL() error = %v", err)
+	}
+	if got != "tr-1" {
+		t.Errorf("SelectClientForURL() = %s, want tr-1 since qb-1 is not granted", got)
+	}
+}
@@ -0,0 +1,247 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+const (
+	// crossSeedAppearTimeout 注入后等待种子出现在客户端列表中的时长，qBittorrent 异步添加
+	crossSeedAppearTimeout = 10 * time.Second
+	crossSeedPollInterval  = 500 * time.Millisecond
+)
+
+// SetCrossSeedDir 设置辅种扫描的 .torrent 文件目录，为空表示不启用辅种
+func (ts *TorrentService) SetCrossSeedDir(dir string) {
+	ts.crossSeedDir = dir
+}
+
+// CrossSeed 扫描辅种目录中的 .torrent 文件，按文件名与大小匹配各客户端上已完成的种子，仅管理员可用
+// 匹配成功时以相同保存路径暂停添加到持有数据的客户端并触发校验，校验完成后需手动恢复；dryRun 只报告匹配结果
+func (ts *TorrentService) CrossSeed(dryRun bool) (models.CrossSeedReport, error) {
+	report := models.CrossSeedReport{DryRun: dryRun, Results: make([]models.CrossSeedResult, 0)}
+	if err := ts.requireAdmin("cross-seed"); err != nil {
+		return report, err
+	}
+	if ts.crossSeedDir == "" {
+		return report, &CrossSeedDisabledError{}
+	}
+
+	entries, err := os.ReadDir(ts.crossSeedDir)
+	if err != nil {
+		return report, err
+	}
+
+	matcher := newCrossSeedMatcher(ts)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".torrent") {
+			continue
+		}
+
+		result := ts.crossSeedFile(matcher, entry.Name(), dryRun)
+		switch result.Status {
+		case models.CrossSeedInjected:
+			report.Injected++
+		case models.CrossSeedMatched:
+			report.Matched++
+		case models.CrossSeedSkipped:
+			report.Skipped++
+		case models.CrossSeedFailed:
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// crossSeedFile 匹配并注入辅种目录中的单个 .torrent 文件
+func (ts *TorrentService) crossSeedFile(matcher *crossSeedMatcher, file string, dryRun bool) models.CrossSeedResult {
+	result := models.CrossSeedResult{File: file, Status: models.CrossSeedFailed}
+	data, err := os.ReadFile(filepath.Join(ts.crossSeedDir, file))
+	if err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+	if result.Hash, err = clients.TorrentFileInfoHash(data); err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+	name, files, err := clients.TorrentFileContents(data)
+	if err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+	result.Name, result.Size = name, totalFileSize(files)
+
+	if existing := matcher.byHash[result.Hash]; existing != "" {
+		result.Status = models.CrossSeedSkipped
+		result.Reason = "torrent already exists on client " + existing
+		return result
+	}
+	match, content, ok := matcher.match(files)
+	if !ok {
+		result.Status = models.CrossSeedSkipped
+		result.Reason = "no torrent with matching files"
+		return result
+	}
+	result.ClientID = match.ClientID
+	result.MatchedHash = strings.ToLower(match.Hash)
+	result.MatchedName = match.Name
+	result.SavePath = content.SavePath
+	if dryRun {
+		result.Status = models.CrossSeedMatched
+		return result
+	}
+
+	if err := ts.injectCrossSeed(match.ClientID, result.Hash, data, content.SavePath, file); err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+	matcher.byHash[result.Hash] = match.ClientID
+	result.Status = models.CrossSeedInjected
+	return result
+}
+
+// injectCrossSeed 以相同保存路径暂停添加种子并触发校验
+func (ts *TorrentService) injectCrossSeed(clientID, hash string, data []byte, savePath, file string) (err error) {
+	defer func() {
+		params := map[string]interface{}{"crossSeed": file, "savePath": savePath}
+		ts.audit(models.AuditActionAdd, clientID, hash, params, err)
+	}()
+
+	client, err := ts.getClient(clientID)
+	if err != nil {
+		return err
+	}
+	if _, err := client.ImportTorrent(data, models.ImportOptions{SavePath: savePath, Paused: true}); err != nil {
+		return fmt.Errorf("add torrent: %w", err)
+	}
+	ts.invalidate(clientID, hash, false)
+	ts.recordOwnership(clientID, hash, models.SourceCrossSeed)
+
+	deadline := time.Now().Add(crossSeedAppearTimeout)
+	for {
+		if _, found, _ := findClientTorrent(client, hash); found {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("torrent did not appear on client %s after adding", clientID)
+		}
+		time.Sleep(crossSeedPollInterval)
+	}
+	if err := client.RecheckTorrent(hash); err != nil {
+		return fmt.Errorf("recheck: %w", err)
+	}
+	return nil
+}
+
+// crossSeedMatcher 一次扫描中共享的种子索引，文件列表按需查询并缓存
+type crossSeedMatcher struct {
+	ts *TorrentService
+	// byHash 已存在的种子所在的客户端，键为小写 info-hash
+	byHash map[string]string
+	// bySize 已完成的种子按总大小分组，匹配时只比较大小相同的种子
+	bySize   map[int64][]models.UnifiedTorrent
+	contents map[string]*models.TorrentContent
+}
+
+func newCrossSeedMatcher(ts *TorrentService) *crossSeedMatcher {
+	matcher := &crossSeedMatcher{
+		ts:       ts,
+		byHash:   make(map[string]string),
+		bySize:   make(map[int64][]models.UnifiedTorrent),
+		contents: make(map[string]*models.TorrentContent),
+	}
+
+	torrents := ts.GetAllTorrents()
+	sort.Slice(torrents, func(i, j int) bool {
+		if torrents[i].ClientID != torrents[j].ClientID {
+			return torrents[i].ClientID < torrents[j].ClientID
+		}
+		return torrents[i].Hash < torrents[j].Hash
+	})
+	for _, torrent := range torrents {
+		hash := strings.ToLower(torrent.Hash)
+		if _, ok := matcher.byHash[hash]; !ok {
+			matcher.byHash[hash] = torrent.ClientID
+		}
+		if torrent.Progress >= 1 {
+			matcher.bySize[torrent.Size] = append(matcher.bySize[torrent.Size], torrent)
+		}
+	}
+	return matcher
+}
+
+// match 返回文件路径与大小完全一致的第一个已完成种子
+func (m *crossSeedMatcher) match(files []models.TorrentFile) (models.UnifiedTorrent, models.TorrentContent, bool) {
+	for _, candidate := range m.bySize[totalFileSize(files)] {
+		content := m.content(candidate)
+		if content != nil && sameFiles(files, content.Files) {
+			return candidate, *content, true
+		}
+	}
+	return models.UnifiedTorrent{}, models.TorrentContent{}, false
+}
+
+// content 查询候选种子的文件列表，失败时记录日志并缓存为空
+func (m *crossSeedMatcher) content(torrent models.UnifiedTorrent) *models.TorrentContent {
+	key := torrentKey(torrent.ClientID, torrent.Hash)
+	if content, ok := m.contents[key]; ok {
+		return content
+	}
+
+	var content *models.TorrentContent
+	client, err := m.ts.getClient(torrent.ClientID)
+	if err == nil {
+		var fetched models.TorrentContent
+		if fetched, err = client.GetTorrentFiles(torrent.Hash); err == nil {
+			content = &fetched
+		}
+	}
+	if err != nil {
+		log.Printf("⚠️  获取种子文件列表失败 [%s/%s]: %v", torrent.ClientID, torrent.Hash, err)
+	}
+	m.contents[key] = content
+	return content
+}
+
+// sameFiles 比较两个文件列表的路径与大小，与顺序无关
+func sameFiles(a, b []models.TorrentFile) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[models.TorrentFile]int, len(a))
+	for _, file := range a {
+		counts[file]++
+	}
+	for _, file := range b {
+		if counts[file] == 0 {
+			return false
+		}
+		counts[file]--
+	}
+	return true
+}
+
+func totalFileSize(files []models.TorrentFile) int64 {
+	var total int64
+	for _, file := range files {
+		total += file.Size
+	}
+	return total
+}
+
+// CrossSeedDisabledError 未配置辅种目录
+type CrossSeedDisabledError struct{}
+
+func (e *CrossSeedDisabledError) Error() string {
+	return "cross-seed directory is not configured"
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestSameFiles(t *testing.T) {
+	a := models.TorrentFile{Path: "show/a.mkv", Size: 10}
+	b := models.TorrentFile{Path: "show/b.nfo", Size: 20}
+	tests := []struct {
+		name string
+		x, y []models.TorrentFile
+		want bool
+	}{
+		{"equal", []models.TorrentFile{a, b}, []models.TorrentFile{a, b}, true},
+		{"different order", []models.TorrentFile{a, b}, []models.TorrentFile{b, a}, true},
+		{"different size", []models.TorrentFile{a}, []models.TorrentFile{{Path: a.Path, Size: 11}}, false},
+		{"different path", []models.TorrentFile{a}, []models.TorrentFile{{Path: "other/a.mkv", Size: 10}}, false},
+		{"missing file", []models.TorrentFile{a, b}, []models.TorrentFile{a}, false},
+		{"duplicates are counted", []models.TorrentFile{a, a}, []models.TorrentFile{a, b}, false},
+		{"both empty", nil, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := sameFiles(test.x, test.y); got != test.want {
+				t.Errorf("sameFiles() = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCrossSeedDryRun(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		// 与 qb-1 上的种子文件一致，但 info 字典不同（私有标记）
+		"match.torrent":  "d4:infod6:lengthi1e4:name8:show.mkv12:piece lengthi16384e7:privatei1eee",
+		"other.torrent":  "d4:infod6:lengthi2e4:name8:show.mkv12:piece lengthi16384eee",
+		"same.torrent":   "d4:infod6:lengthi1e4:name8:show.mkv12:piece lengthi16384eee",
+		"broken.torrent": "not bencode",
+		"ignored.txt":    "d4:infod6:lengthi1e4:name8:show.mkv12:piece lengthi16384eee",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	existing, _ := clients.TorrentFileInfoHash([]byte(files["same.torrent"]))
+	client := &fakeClient{
+		id:       "qb-1",
+		torrents: []models.UnifiedTorrent{{ClientID: "qb-1", Hash: existing, Name: "show.mkv", Size: 1, Progress: 1}},
+		contents: map[string]models.TorrentContent{
+			existing: {SavePath: "/data", Files: []models.TorrentFile{{Path: "show.mkv", Size: 1}}},
+		},
+	}
+	service := NewTorrentService([]clients.DownloaderClient{client}, newTestDB(t))
+
+	if _, err := service.CrossSeed(true); !errors.As(err, new(*CrossSeedDisabledError)) {
+		t.Fatalf("CrossSeed() error = %v, want CrossSeedDisabledError", err)
+	}
+	service.SetCrossSeedDir(dir)
+	if _, err := service.WithAccess(&Access{UserID: 1, Role: models.RoleOperator}).CrossSeed(true); !errors.As(err, new(*PermissionDeniedError)) {
+		t.Errorf("CrossSeed() as operator error = %v, want PermissionDeniedError", err)
+	}
+
+	report, err := service.CrossSeed(true)
+	if err != nil {
+		t.Fatalf("CrossSeed() error = %v", err)
+	}
+	if report.Matched != 1 || report.Skipped != 2 || report.Failed != 1 || report.Injected != 0 {
+		t.Errorf("report = %+v, want 1 matched, 2 skipped and 1 failed", report)
+	}
+	want := map[string]models.CrossSeedStatus{
+		"broken.torrent": models.CrossSeedFailed,
+		"match.torrent":  models.CrossSeedMatched,
+		"other.torrent":  models.CrossSeedSkipped,
+		"same.torrent":   models.CrossSeedSkipped,
+	}
+	for _, result := range report.Results {
+		if result.Status != want[result.File] {
+			t.Errorf("%s: status = %s (%s), want %s", result.File, result.Status, result.Reason, want[result.File])
+		}
+		if result.File == "match.torrent" && (result.ClientID != "qb-1" || result.SavePath != "/data") {
+			t.Errorf("match.torrent matched %s at %q, want qb-1 at /data", result.ClientID, result.SavePath)
+		}
+	}
+	if calls := client.Calls(); len(calls) != 0 {
+		t.Errorf("client calls = %v, want none in a dry run", calls)
+	}
+}
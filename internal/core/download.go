@@ -0,0 +1,127 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const (
+	// downloadTimeout 服务端下载订阅内容或种子文件的超时时间
+	downloadTimeout = 30 * time.Second
+	// maxDownloadSize 订阅内容与种子文件的最大大小，与 qBittorrent 兼容接口的上传限制一致
+	maxDownloadSize = 10 << 20
+)
+
+// downloadClient 服务端下载种子文件使用的 HTTP 客户端
+var downloadClient = &http.Client{Timeout: downloadTimeout}
+
+// publicDownloadClient 下载非管理员提交的 URL，只连接公网地址
+// 地址在建立连接时检查，重定向后的地址与域名解析结果同样受限；不使用代理，避免绕过检查
+var publicDownloadClient = &http.Client{
+	Timeout: downloadTimeout,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: downloadTimeout, Control: dialPublicOnly}).DialContext,
+		TLSHandshakeTimeout: downloadTimeout,
+	},
+	CheckRedirect: func(request *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return checkDownloadURL(request.URL)
+	},
+}
+
+// errNonPublicAddress 目标地址不是公网地址
+var errNonPublicAddress = errors.New("refusing to connect to a non-public address")
+
+// dialPublicOnly 拒绝连接环回、私有、链路本地等非公网地址
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return errNonPublicAddress
+	}
+	return nil
+}
+
+// isPublicIP 判断地址是否可以由用户指定的下载访问
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// checkDownloadURL 只允许 http 与 https 地址
+func checkDownloadURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	return nil
+}
+
+// download 以 GET 下载 url 的内容，超过 maxDownloadSize 时返回错误
+func download(client *http.Client, url string) ([]byte, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", "Down-Nexus")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s from %s", response.Status, url)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxDownloadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDownloadSize {
+		return nil, fmt.Errorf("response is larger than %d bytes", maxDownloadSize)
+	}
+	return data, nil
+}
+
+// fetchTorrent 下载用户提交的种子 URL，用于在添加前得知种子的哈希
+// 只允许 http 与 https；非管理员只能访问公网地址，失败时不返回具体原因，避免借此探测内部网络
+func (ts *TorrentService) fetchTorrent(rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err == nil {
+		err = checkDownloadURL(u)
+	}
+	if err != nil {
+		return nil, &TorrentDownloadError{URL: rawURL}
+	}
+	if ts.access.IsAdmin() {
+		return download(downloadClient, rawURL)
+	}
+
+	data, err := download(publicDownloadClient, rawURL)
+	if err != nil {
+		log.Printf("⚠️  下载用户 %s 提交的种子 URL 失败: %v", ts.access.Username, err)
+		return nil, &TorrentDownloadError{URL: rawURL}
+	}
+	return data, nil
+}
+
+// TorrentDownloadError 无法下载用户提交的种子 URL
+type TorrentDownloadError struct {
+	URL string
+}
+
+func (e *TorrentDownloadError) Error() string {
+	return fmt.Sprintf("cannot download torrent from %s", e.URL)
+}
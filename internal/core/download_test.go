@@ -0,0 +1,102 @@
+package core
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, test := range tests {
+		t.Run(test.ip, func(t *testing.T) {
+			if got := isPublicIP(net.ParseIP(test.ip)); got != test.want {
+				t.Errorf("isPublicIP(%s) = %t, want %t", test.ip, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFetchTorrentRejectsInternalURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testTorrentFile)
+	}))
+	defer server.Close()
+
+	service := NewTorrentService([]clients.DownloaderClient{&fakeClient{id: "qb-1"}}, newTestDB(t))
+	user := service.WithAccess(&Access{UserID: 1, Username: "alice", Role: models.RoleOperator})
+
+	tests := []struct {
+		name    string
+		service *TorrentService
+		url     string
+		wantErr bool
+	}{
+		{"file scheme", service, "file:///etc/passwd", true},
+		{"ftp scheme", user, "ftp://example.com/a.torrent", true},
+		{"loopback", user, server.URL + "/a.torrent", true},
+		{"ipv6 loopback", user, "http://[::1]:1/a.torrent", true},
+		{"link local", user, "http://169.254.169.254/latest/meta-data", true},
+		{"private", user, "http://10.0.0.1/a.torrent", true},
+		{"admin may use local indexers", service, server.URL + "/a.torrent", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := test.service.fetchTorrent(test.url)
+			if !test.wantErr {
+				if err != nil || string(data) != string(testTorrentFile) {
+					t.Fatalf("fetchTorrent() = %q, %v, want the torrent file", data, err)
+				}
+				return
+			}
+			var downloadErr *TorrentDownloadError
+			if !errors.As(err, &downloadErr) {
+				t.Fatalf("fetchTorrent() error = %v, want TorrentDownloadError", err)
+			}
+		})
+	}
+}
+
+func TestPublicDownloadClientChecksRedirects(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		wantErr bool
+	}{
+		{"https", "https://example.com/a.torrent", false},
+		{"file", "file:///etc/passwd", true},
+		{"gopher", "gopher://example.com/", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodGet, test.target, nil)
+			err := publicDownloadClient.CheckRedirect(request, []*http.Request{{}})
+			if (err != nil) != test.wantErr {
+				t.Errorf("CheckRedirect(%s) error = %v, wantErr %t", test.target, err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"sort"
+	"strings"
+
+	"down-nexus-api/internal/models"
+)
+
+// SetDuplicatePolicy 设置添加已存在于其他客户端的种子时的处理方式，默认为 warn
+func (ts *TorrentService) SetDuplicatePolicy(policy models.DuplicatePolicy) {
+	ts.duplicatePolicy = policy
+}
+
+// DuplicatePolicy 返回当前的重复种子处理方式
+func (ts *TorrentService) DuplicatePolicy() models.DuplicatePolicy {
+	if ts.duplicatePolicy == "" {
+		return models.DuplicateWarn
+	}
+	return ts.duplicatePolicy
+}
+
+// locateTorrent 在所有客户端中查找指定 info-hash 的种子，不受当前权限限制
+// 已启动后台同步时查询快照索引，否则实时拉取各客户端的种子列表
+func (ts *TorrentService) locateTorrent(hash string) []models.UnifiedTorrent {
+	if hash == "" {
+		return nil
+	}
+	if ts.cache != nil {
+		return ts.cache.locate(hash)
+	}
+
+	var copies []models.UnifiedTorrent
+	for _, torrent := range ts.WithAccess(nil).GetAllTorrents() {
+		if strings.EqualFold(torrent.Hash, hash) {
+			copies = append(copies, torrent)
+		}
+	}
+	return copies
+}
+
+// FindDuplicates 返回 clientID 以外的客户端上相同 info-hash 的种子，只包含当前权限可见的客户端
+func (ts *TorrentService) FindDuplicates(hash, clientID string) []models.UnifiedTorrent {
+	var copies []models.UnifiedTorrent
+	for _, torrent := range ts.locateTorrent(hash) {
+		if torrent.ClientID != clientID && ts.access.Can(torrent.ClientID, models.ActionView) {
+			copies = append(copies, torrent)
+		}
+	}
+	return copies
+}
+
+// checkDuplicate 策略为 refuse 时，若种子已存在于其他客户端则拒绝添加
+// 其他客户端对当前用户不可见时同样拒绝，但错误中不透露客户端 ID
+func (ts *TorrentService) checkDuplicate(hash, clientID string) error {
+	if ts.DuplicatePolicy() != models.DuplicateRefuse || hash == "" {
+		return nil
+	}
+
+	found := false
+	var visible []string
+	for _, torrent := range ts.locateTorrent(hash) {
+		if torrent.ClientID == clientID {
+			continue
+		}
+		found = true
+		if ts.access.Can(torrent.ClientID, models.ActionView) {
+			visible = append(visible, torrent.ClientID)
+		}
+	}
+	if !found {
+		return nil
+	}
+	return &DuplicateTorrentError{Hash: strings.ToLower(hash), ClientIDs: visible}
+}
+
+// ListDuplicates 列出存在于多个可见客户端上的种子，按名称排序
+func (ts *TorrentService) ListDuplicates() []models.DuplicateTorrent {
+	groups := make(map[string][]models.UnifiedTorrent)
+	for _, torrent := range ts.GetAllTorrents() {
+		hash := strings.ToLower(torrent.Hash)
+		groups[hash] = append(groups[hash], torrent)
+	}
+
+	duplicates := make([]models.DuplicateTorrent, 0)
+	for hash, copies := range groups {
+		if len(copies) < 2 {
+			continue
+		}
+		sort.Slice(copies, func(i, j int) bool { return copies[i].ClientID < copies[j].ClientID })
+		duplicates = append(duplicates, models.DuplicateTorrent{
+			Hash:   hash,
+			Name:   copies[0].Name,
+			Copies: copies,
+		})
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Name != duplicates[j].Name {
+			return duplicates[i].Name < duplicates[j].Name
+		}
+		return duplicates[i].Hash < duplicates[j].Hash
+	})
+	return duplicates
+}
+
+// DuplicateTorrentError 种子已存在于其他客户端
+type DuplicateTorrentError struct {
+	Hash string
+	// ClientIDs 当前用户可见的、已有该种子的客户端
+	ClientIDs []string
+}
+
+func (e *DuplicateTorrentError) Error() string {
+	if len(e.ClientIDs) == 0 {
+		return "torrent " + e.Hash + " already exists on another client"
+	}
+	return "torrent " + e.Hash + " already exists on client " + strings.Join(e.ClientIDs, ", ")
+}
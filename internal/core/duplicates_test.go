@@ -0,0 +1,77 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestAddTorrentDuplicatePolicy(t *testing.T) {
+	qb := &fakeClient{id: "qb-1", torrents: []models.UnifiedTorrent{{ClientID: "qb-1", Hash: testHashA}}}
+	tr := &fakeClient{id: "tr-1"}
+	service := NewTorrentService([]clients.DownloaderClient{qb, tr}, newTestDB(t))
+	// 只能访问 tr-1 的用户看不到 qb-1 上的副本
+	user := NewAccess(&models.User{
+		Role:   models.RoleOperator,
+		Grants: []models.UserGrant{{ClientID: "tr-1", Actions: "view,add"}},
+	})
+
+	tests := []struct {
+		name          string
+		policy        models.DuplicatePolicy
+		access        *Access
+		hash          string
+		clientID      string
+		wantClientIDs []string
+		wantRefused   bool
+	}{
+		{name: "warn adds", policy: models.DuplicateWarn, hash: testHashA, clientID: "tr-1"},
+		{name: "allow adds", policy: models.DuplicateAllow, hash: testHashA, clientID: "tr-1"},
+		{name: "refuse", policy: models.DuplicateRefuse, hash: testHashA, clientID: "tr-1", wantClientIDs: []string{"qb-1"}, wantRefused: true},
+		{name: "refuse hides invisible clients", policy: models.DuplicateRefuse, access: user, hash: testHashA, clientID: "tr-1", wantRefused: true},
+		{name: "refuse ignores the same client", policy: models.DuplicateRefuse, hash: testHashA, clientID: "qb-1"},
+		{name: "refuse allows new torrents", policy: models.DuplicateRefuse, hash: testHashB, clientID: "tr-1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service.SetDuplicatePolicy(test.policy)
+			_, err := service.WithAccess(test.access).AddTorrent("magnet:?xt=urn:btih:"+test.hash, test.clientID)
+			var duplicate *DuplicateTorrentError
+			if !test.wantRefused {
+				if err != nil {
+					t.Fatalf("AddTorrent() error = %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &duplicate) {
+				t.Fatalf("AddTorrent() error = %v, want DuplicateTorrentError", err)
+			}
+			if !reflect.DeepEqual(duplicate.ClientIDs, test.wantClientIDs) {
+				t.Errorf("ClientIDs = %v, want %v", duplicate.ClientIDs, test.wantClientIDs)
+			}
+		})
+	}
+}
+
+func TestListDuplicates(t *testing.T) {
+	qb := &fakeClient{id: "qb-1", torrents: []models.UnifiedTorrent{
+		{ClientID: "qb-1", Hash: testHashA, Name: "b"},
+		{ClientID: "qb-1", Hash: testHashB, Name: "a"},
+	}}
+	tr := &fakeClient{id: "tr-1", torrents: []models.UnifiedTorrent{{ClientID: "tr-1", Hash: testHashA, Name: "b"}}}
+	service := NewTorrentService([]clients.DownloaderClient{tr, qb}, newTestDB(t))
+
+	duplicates := service.ListDuplicates()
+	if len(duplicates) != 1 || duplicates[0].Hash != testHashA {
+		t.Fatalf("ListDuplicates() = %+v, want only %s", duplicates, testHashA)
+	}
+	if copies := duplicates[0].Copies; len(copies) != 2 || copies[0].ClientID != "qb-1" || copies[1].ClientID != "tr-1" {
+		t.Errorf("Copies = %+v, want qb-1 and tr-1", copies)
+	}
+	if copies := service.FindDuplicates(testHashA, "qb-1"); len(copies) != 1 || copies[0].ClientID != "tr-1" {
+		t.Errorf("FindDuplicates() = %+v, want the tr-1 copy", copies)
+	}
+}
@@ -0,0 +1,232 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 创建迁移好全部模型的内存 SQLite 数据库
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	// 内存数据库按连接隔离，只使用一个连接
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	err = db.AutoMigrate(&models.ScheduleRule{}, &models.ScheduleBaseline{}, &models.Category{}, &models.CategorySavePath{}, &models.SharePolicy{},
+		&models.User{}, &models.APIKey{}, &models.RefreshToken{}, &models.UserGrant{}, &models.TorrentOwnership{}, &models.UserQuota{}, &models.AuditLog{},
+		&models.ClientSelectionPolicy{}, &models.ClientSelectionRule{},
+		&models.WatchFolder{}, &models.WatchIngestion{}, &models.RSSFeed{}, &models.RSSRule{}, &models.RSSItem{},
+		&models.HousekeepingRule{}, &models.HousekeepingExecution{})
+	if err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+	return db
+}
+
+// errFakeClient fakeClient 按要求模拟的失败
+var errFakeClient = errors.New("fake client failure")
+
+// fakeClient 记录调用的下载器，未实现的方法调用时 panic
+type fakeClient struct {
+	clients.DownloaderClient
+
+	id       string
+	torrents []models.UnifiedTorrent
+	// free 剩余空间，带数据删除种子时增加该种子的大小
+	free int64
+	// limits 全局速度限制
+	limits models.TransferLimits
+	// categories EnsureCategory 创建的分类及其保存路径
+	categories map[string]string
+	// nativeShareLimits 是否模拟能自行执行分享目标的客户端
+	nativeShareLimits bool
+	// syncs SyncTorrents 依次返回的结果
+	syncs []models.TorrentSync
+	// sameDirRenames 是否模拟只能在同一目录内重命名文件的客户端
+	sameDirRenames bool
+	// contents GetTorrentFiles 返回的保存路径与文件列表，键为种子哈希
+	contents map[string]models.TorrentContent
+
+	mutex sync.Mutex
+	// failures 接下来需要失败的调用次数
+	failures int
+	// calls 按顺序记录的调用，如 "limits 100 200 false"、"category <hash> <category>"
+	calls []string
+}
+
+func (c *fakeClient) GetClientID() string {
+	return c.id
+}
+
+func (c *fakeClient) GetTorrents() ([]models.UnifiedTorrent, error) {
+	return c.torrents, nil
+}
+
+func (c *fakeClient) SyncTorrents(full bool) (models.TorrentSync, error) {
+	if err := c.record(fmt.Sprintf("sync %t", full)); err != nil {
+		return models.TorrentSync{}, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.syncs) == 0 {
+		return models.TorrentSync{Full: full, Torrents: c.torrents}, nil
+	}
+	result := c.syncs[0]
+	c.syncs = c.syncs[1:]
+	return result, nil
+}
+
+func (c *fakeClient) AddTorrent(magnetURL string) (string, error) {
+	if err := c.record("add " + magnetURL); err != nil {
+		return "", err
+	}
+	return clients.MagnetInfoHash(magnetURL), nil
+}
+
+func (c *fakeClient) AddTorrentFile(data []byte) (string, error) {
+	hash, err := clients.TorrentFileInfoHash(data)
+	if err != nil {
+		return "", err
+	}
+	if err := c.record("addFile " + hash); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+func (c *fakeClient) PauseTorrent(hash string) error {
+	return c.record("pause " + hash)
+}
+
+func (c *fakeClient) ResumeTorrent(hash string) error {
+	return c.record("resume " + hash)
+}
+
+func (c *fakeClient) DeleteTorrent(hash string, deleteFiles bool) error {
+	if err := c.record("delete " + hash); err != nil {
+		return err
+	}
+	if deleteFiles {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		for _, torrent := range c.torrents {
+			if torrent.Hash == hash {
+				c.free += torrent.Size
+			}
+		}
+	}
+	return nil
+}
+
+func (c *fakeClient) MoveTorrent(hash, location string) error {
+	return c.record("move " + hash + " " + location)
+}
+
+func (c *fakeClient) GetFreeSpace() (int64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.free, nil
+}
+
+func (c *fakeClient) GetTorrentFiles(hash string) (models.TorrentContent, error) {
+	content, ok := c.contents[hash]
+	if !ok {
+		return models.TorrentContent{}, errFakeClient
+	}
+	return content, nil
+}
+
+func (c *fakeClient) GetTransferLimits() (models.TransferLimits, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.limits, nil
+}
+
+func (c *fakeClient) SetTransferLimits(limits models.TransferLimits) error {
+	if err := c.record(fmt.Sprintf("limits %d %d %t", limits.DownloadLimit, limits.UploadLimit, limits.AltSpeedEnabled)); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.limits = limits
+	return nil
+}
+
+func (c *fakeClient) SetShareLimits(hashes []string, limits models.ShareLimits) (bool, error) {
+	return c.nativeShareLimits, nil
+}
+
+func (c *fakeClient) EnsureCategory(name, savePath string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.categories == nil {
+		c.categories = make(map[string]string)
+	}
+	c.categories[name] = savePath
+	return nil
+}
+
+func (c *fakeClient) SetCategory(hashes []string, category string) error {
+	for _, hash := range hashes {
+		if err := c.record("category " + hash + " " + category); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *fakeClient) AddTags(hashes []string, tags []string) error {
+	return c.record("tags " + strings.Join(hashes, ",") + " " + strings.Join(tags, ","))
+}
+
+func (c *fakeClient) RemoveTags(hashes []string, tags []string) error {
+	return c.record("untags " + strings.Join(hashes, ",") + " " + strings.Join(tags, ","))
+}
+
+func (c *fakeClient) RenameTorrent(hash, name string) error {
+	return c.record("rename " + hash + " " + name)
+}
+
+func (c *fakeClient) RenameFile(hash, oldPath, newPath string) error {
+	if c.sameDirRenames && path.Dir(oldPath) != path.Dir(newPath) {
+		return clients.ErrCrossDirectoryRename
+	}
+	return c.record("renamefile " + hash + " " + oldPath + " " + newPath)
+}
+
+// record 记录一次调用，还有待失败的次数时返回 errFakeClient
+func (c *fakeClient) record(call string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.failures > 0 {
+		c.failures--
+		return errFakeClient
+	}
+	c.calls = append(c.calls, call)
+	return nil
+}
+
+// Calls 返回已成功的调用
+func (c *fakeClient) Calls() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]string(nil), c.calls...)
+}
@@ -0,0 +1,482 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// housekeepingRetryDelay 执行失败的动作在多久之后重试
+	housekeepingRetryDelay = time.Hour
+	// housekeepingWebhookTimeout notify 动作发送通知的超时时间
+	housekeepingWebhookTimeout = 10 * time.Second
+)
+
+// HousekeepingEngine 种子整理规则引擎
+// 按 interval 对所有客户端的种子求值，命中规则的种子以内部调用的身份执行动作并记录到执行历史；
+// 同一种子在规则的同一版本下只成功执行一次，失败的动作在 housekeepingRetryDelay 后重试
+type HousekeepingEngine struct {
+	service  *TorrentService
+	db       *gorm.DB
+	interval time.Duration
+	client   *http.Client
+
+	// mutex 串行执行求值，避免定时执行与规则修改后的立即执行重复处理同一种子
+	mutex   sync.Mutex
+	trigger chan struct{}
+}
+
+func NewHousekeepingEngine(service *TorrentService, db *gorm.DB, interval time.Duration) *HousekeepingEngine {
+	return &HousekeepingEngine{
+		service:  service,
+		db:       db,
+		interval: interval,
+		client:   &http.Client{Timeout: housekeepingWebhookTimeout},
+		trigger:  make(chan struct{}, 1),
+	}
+}
+
+// Run 启动求值循环，直到 ctx 被取消
+func (e *HousekeepingEngine) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	e.evaluate(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-e.trigger:
+		}
+		e.evaluate(time.Now())
+	}
+}
+
+// evaluate 按规则 ID 顺序求值并执行动作，已被前面的规则删除的种子不再参与后续规则
+func (e *HousekeepingEngine) evaluate(now time.Time) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	var rules []models.HousekeepingRule
+	if err := e.db.Where("enabled = ?", true).Order("id").Find(&rules).Error; err != nil {
+		log.Printf("⚠️  加载整理规则失败: %v", err)
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	torrents := e.service.GetAllTorrents()
+	freeSpace := make(map[string]*int64)
+	removed := make(map[string]bool)
+	for i := range rules {
+		rule := &rules[i]
+		matches, err := e.plan(rule, torrents, freeSpace, now)
+		if err != nil {
+			log.Printf("⚠️  整理规则 %s 求值失败: %v", rule.Name, err)
+			continue
+		}
+		for _, torrent := range matches {
+			key := torrentKey(torrent.ClientID, torrent.Hash)
+			if removed[key] {
+				continue
+			}
+			// 前面的删除会释放空间，剩余空间条件按最新值重新判断，条件不再成立时跳过该客户端的其余种子
+			if rule.MaxFreeSpace != nil {
+				if free := e.freeSpace(torrent.ClientID, freeSpace); free == nil || *free >= *rule.MaxFreeSpace {
+					continue
+				}
+			}
+			if e.execute(rule, torrent) && !rule.DryRun &&
+				(rule.Action == models.HousekeepingRemove || rule.Action == models.HousekeepingRemoveWithData) {
+				removed[key] = true
+				delete(freeSpace, torrent.ClientID)
+			}
+		}
+	}
+}
+
+// plan 返回命中规则且需要执行动作的种子
+// 跳过动作不会产生变化的种子，以及在规则当前版本下已执行过的种子；未保存的规则（ID 为 0）不检查执行历史
+func (e *HousekeepingEngine) plan(rule *models.HousekeepingRule, torrents []models.UnifiedTorrent, freeSpace map[string]*int64, now time.Time) ([]models.UnifiedTorrent, error) {
+	executed := make(map[string]bool)
+	if rule.ID != 0 {
+		var records []models.HousekeepingExecution
+		err := e.db.Select("client_id", "hash").
+			Where("rule_id = ? AND dry_run = ? AND created_at >= ?", rule.ID, rule.DryRun, rule.UpdatedAt).
+			Where("success = ? OR created_at > ?", true, now.Add(-housekeepingRetryDelay)).
+			Find(&records).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			executed[torrentKey(record.ClientID, record.Hash)] = true
+		}
+	}
+
+	var candidates []models.UnifiedTorrent
+	for _, torrent := range torrents {
+		if executed[torrentKey(torrent.ClientID, torrent.Hash)] || !housekeepingPending(rule, torrent) {
+			continue
+		}
+		if !e.matches(rule, torrent, freeSpace, now) {
+			continue
+		}
+		candidates = append(candidates, torrent)
+	}
+	return candidates, nil
+}
+
+// matches 判断种子是否满足规则的全部条件，条件所需的信息未知时视为不满足
+func (e *HousekeepingEngine) matches(rule *models.HousekeepingRule, torrent models.UnifiedTorrent, freeSpace map[string]*int64, now time.Time) bool {
+	if rule.ClientID != "" && rule.ClientID != torrent.ClientID {
+		return false
+	}
+	if rule.MinRatio != nil && torrent.Ratio < *rule.MinRatio {
+		return false
+	}
+	if rule.MinSeedingTime != nil && torrent.SeedingTime < *rule.MinSeedingTime*60 {
+		return false
+	}
+	if states := rule.StateList(); len(states) > 0 {
+		state := models.NormalizeState(torrent.State)
+		found := false
+		for _, candidate := range states {
+			found = found || candidate == state
+		}
+		if !found {
+			return false
+		}
+	}
+	if rule.Category != nil && *rule.Category != torrent.Category {
+		return false
+	}
+	if rule.Tracker != "" {
+		hosts := trackerHosts([]string{torrent.Tracker})
+		if len(hosts) == 0 || (hosts[0] != rule.Tracker && !strings.HasSuffix(hosts[0], "."+rule.Tracker)) {
+			return false
+		}
+	}
+	if (rule.MinSize != nil && torrent.Size < *rule.MinSize) || (rule.MaxSize != nil && torrent.Size > *rule.MaxSize) {
+		return false
+	}
+	if rule.MinAge != nil && (torrent.AddedOn <= 0 || now.Unix()-torrent.AddedOn < *rule.MinAge*60) {
+		return false
+	}
+	if rule.MaxFreeSpace != nil {
+		free := e.freeSpace(torrent.ClientID, freeSpace)
+		if free == nil || *free >= *rule.MaxFreeSpace {
+			return false
+		}
+	}
+	return true
+}
+
+// freeSpace 查询客户端的剩余空间并缓存在 cache 中，查询失败时返回 nil
+// 删除种子后调用方需移除该客户端的缓存，下次使用时重新查询
+func (e *HousekeepingEngine) freeSpace(clientID string, cache map[string]*int64) *int64 {
+	if free, ok := cache[clientID]; ok {
+		return free
+	}
+	var free *int64
+	if client, err := e.service.getClient(clientID); err == nil {
+		if value, err := client.GetFreeSpace(); err == nil {
+			free = &value
+		} else {
+			log.Printf("⚠️  获取客户端 [%s] 的剩余空间失败: %v", clientID, err)
+		}
+	}
+	cache[clientID] = free
+	return free
+}
+
+// housekeepingPending 动作执行后种子是否会发生变化，已暂停的种子不再暂停，分类相同时不再设置
+func housekeepingPending(rule *models.HousekeepingRule, torrent models.UnifiedTorrent) bool {
+	switch rule.Action {
+	case models.HousekeepingPause:
+		return !isPausedState(torrent.State)
+	case models.HousekeepingResume:
+		return isPausedState(torrent.State)
+	case models.HousekeepingSetCategory:
+		return torrent.Category != rule.TargetCategory
+	default:
+		return true
+	}
+}
+
+// execute 执行动作并记录结果，预演模式下只记录，返回动作是否成功
+func (e *HousekeepingEngine) execute(rule *models.HousekeepingRule, torrent models.UnifiedTorrent) bool {
+	var err error
+	if !rule.DryRun {
+		err = e.apply(rule, torrent)
+	}
+
+	record := models.HousekeepingExecution{
+		RuleID:   rule.ID,
+		RuleName: rule.Name,
+		ClientID: torrent.ClientID,
+		Hash:     strings.ToLower(torrent.Hash),
+		Name:     torrent.Name,
+		Action:   rule.Action,
+		DryRun:   rule.DryRun,
+		Success:  err == nil,
+	}
+	switch {
+	case err != nil:
+		record.Error = err.Error()
+		log.Printf("⚠️  整理规则 %s 对种子 %s [%s] 执行 %s 失败: %v", rule.Name, torrent.Name, torrent.ClientID, rule.Action, err)
+	case rule.DryRun:
+		log.Printf("🧹 整理规则 %s（预演）命中种子 %s [%s]，动作: %s", rule.Name, torrent.Name, torrent.ClientID, rule.Action)
+	default:
+		log.Printf("🧹 整理规则 %s 对种子 %s [%s] 执行动作: %s", rule.Name, torrent.Name, torrent.ClientID, rule.Action)
+	}
+
+	if err := e.db.Create(&record).Error; err != nil {
+		log.Printf("⚠️  记录整理规则执行结果失败 [%s]: %v", torrent.Name, err)
+	}
+	return record.Success
+}
+
+// apply 对种子执行规则的动作
+func (e *HousekeepingEngine) apply(rule *models.HousekeepingRule, torrent models.UnifiedTorrent) error {
+	service := e.service
+	switch rule.Action {
+	case models.HousekeepingPause:
+		return service.PauseTorrent(torrent.ClientID, torrent.Hash)
+	case models.HousekeepingResume:
+		return service.ResumeTorrent(torrent.ClientID, torrent.Hash)
+	case models.HousekeepingRemove:
+		return service.DeleteTorrent(torrent.ClientID, torrent.Hash, false)
+	case models.HousekeepingRemoveWithData:
+		return service.DeleteTorrent(torrent.ClientID, torrent.Hash, true)
+	case models.HousekeepingMove:
+		return service.MoveTorrent(torrent.ClientID, torrent.Hash, rule.Location)
+	case models.HousekeepingSetCategory:
+		refs := []models.TorrentRef{{ClientID: torrent.ClientID, Hash: torrent.Hash}}
+		return service.AssignCategory(refs, rule.TargetCategory)
+	case models.HousekeepingSetLimits:
+		return e.setLimits(rule, torrent)
+	case models.HousekeepingNotify:
+		return e.notify(rule, torrent)
+	default:
+		return fmt.Errorf("unsupported action: %s", rule.Action)
+	}
+}
+
+// setLimits 设置种子的速度限制，规则未设置的方向为不限速
+func (e *HousekeepingEngine) setLimits(rule *models.HousekeepingRule, torrent models.UnifiedTorrent) error {
+	var download, upload int64
+	if rule.DownloadLimit != nil {
+		download = *rule.DownloadLimit
+	}
+	if rule.UploadLimit != nil {
+		upload = *rule.UploadLimit
+	}
+	return e.service.SetTorrentLimits(torrent.ClientID, torrent.Hash, download, upload)
+}
+
+// housekeepingNotification notify 动作发送的 JSON
+type housekeepingNotification struct {
+	Rule    string                    `json:"rule"`
+	RuleID  uint                      `json:"rule_id"`
+	Action  models.HousekeepingAction `json:"action"`
+	Torrent models.UnifiedTorrent     `json:"torrent"`
+}
+
+// notify 向规则的 WebhookURL 发送通知，非 2xx 响应视为失败
+func (e *HousekeepingEngine) notify(rule *models.HousekeepingRule, torrent models.UnifiedTorrent) error {
+	body, err := json.Marshal(housekeepingNotification{Rule: rule.Name, RuleID: rule.ID, Action: rule.Action, Torrent: torrent})
+	if err != nil {
+		return err
+	}
+
+	response, err := e.client.Post(rule.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", response.Status)
+	}
+	return nil
+}
+
+// refresh 立即触发一轮求值
+func (e *HousekeepingEngine) refresh() {
+	select {
+	case e.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Preview 预演已保存的规则，返回当前会被执行动作的种子，不执行也不记录
+func (e *HousekeepingEngine) Preview(id uint) ([]models.HousekeepingMatch, error) {
+	rule, err := e.GetRule(id)
+	if err != nil {
+		return nil, err
+	}
+	return e.preview(rule)
+}
+
+// PreviewRule 预演未保存的规则，不检查执行历史
+func (e *HousekeepingEngine) PreviewRule(rule *models.HousekeepingRule) ([]models.HousekeepingMatch, error) {
+	if err := e.validate(rule); err != nil {
+		return nil, err
+	}
+	rule.ID = 0
+	return e.preview(rule)
+}
+
+func (e *HousekeepingEngine) preview(rule *models.HousekeepingRule) ([]models.HousekeepingMatch, error) {
+	candidates, err := e.plan(rule, e.service.GetAllTorrents(), make(map[string]*int64), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]models.HousekeepingMatch, 0, len(candidates))
+	for _, candidate := range candidates {
+		matches = append(matches, models.HousekeepingMatch{
+			RuleID:   rule.ID,
+			RuleName: rule.Name,
+			ClientID: candidate.ClientID,
+			Hash:     candidate.Hash,
+			Name:     candidate.Name,
+			Action:   rule.Action,
+		})
+	}
+	return matches, nil
+}
+
+// ListRules 获取所有整理规则
+func (e *HousekeepingEngine) ListRules() ([]models.HousekeepingRule, error) {
+	var rules []models.HousekeepingRule
+	err := e.db.Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// GetRule 获取单条整理规则
+func (e *HousekeepingEngine) GetRule(id uint) (*models.HousekeepingRule, error) {
+	var rule models.HousekeepingRule
+	if err := e.db.First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &HousekeepingRuleNotFoundError{ID: id}
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateRule 创建整理规则，创建后立即求值
+// webhook 地址可能包含令牌，审计日志只记录规则名称与动作
+func (e *HousekeepingEngine) CreateRule(access *Access, rule *models.HousekeepingRule) (err error) {
+	defer func() {
+		Audit(e.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"createHousekeepingRule": rule.Name, "action": rule.Action}, err)
+	}()
+
+	if err := e.validate(rule); err != nil {
+		return err
+	}
+	if err := e.db.Create(rule).Error; err != nil {
+		return err
+	}
+	e.refresh()
+	return nil
+}
+
+// UpdateRule 更新整理规则，更新后所有种子按新版本重新求值
+func (e *HousekeepingEngine) UpdateRule(access *Access, id uint, rule *models.HousekeepingRule) (err error) {
+	defer func() {
+		Audit(e.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"updateHousekeepingRule": id, "name": rule.Name, "action": rule.Action}, err)
+	}()
+
+	existing, err := e.GetRule(id)
+	if err != nil {
+		return err
+	}
+	if err := e.validate(rule); err != nil {
+		return err
+	}
+
+	rule.Model = existing.Model
+	if err := e.db.Save(rule).Error; err != nil {
+		return err
+	}
+	e.refresh()
+	return nil
+}
+
+// DeleteRule 删除整理规则，执行历史保留
+func (e *HousekeepingEngine) DeleteRule(access *Access, id uint) (err error) {
+	defer func() {
+		Audit(e.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"deleteHousekeepingRule": id}, err)
+	}()
+
+	result := e.db.Delete(&models.HousekeepingRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &HousekeepingRuleNotFoundError{ID: id}
+	}
+	return nil
+}
+
+// ListExecutions 获取执行历史，按时间倒序，ruleID 为 0 时返回所有规则的记录
+func (e *HousekeepingEngine) ListExecutions(ruleID uint, limit int) ([]models.HousekeepingExecution, error) {
+	query := e.db.Order("id DESC").Limit(limit)
+	if ruleID != 0 {
+		query = query.Where("rule_id = ?", ruleID)
+	}
+
+	var executions []models.HousekeepingExecution
+	err := query.Find(&executions).Error
+	return executions, err
+}
+
+// validate 校验规则字段，以及客户端与分类是否存在
+func (e *HousekeepingEngine) validate(rule *models.HousekeepingRule) error {
+	if err := rule.Validate(); err != nil {
+		return &InvalidHousekeepingRuleError{Reason: err.Error()}
+	}
+	if rule.ClientID != "" {
+		if _, err := e.service.getClient(rule.ClientID); err != nil {
+			return err
+		}
+	}
+	if rule.Action == models.HousekeepingSetCategory && rule.TargetCategory != "" {
+		if _, err := e.service.GetCategory(rule.TargetCategory); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HousekeepingRuleNotFoundError 整理规则不存在
+type HousekeepingRuleNotFoundError struct {
+	ID uint
+}
+
+func (e *HousekeepingRuleNotFoundError) Error() string {
+	return fmt.Sprintf("housekeeping rule not found: %d", e.ID)
+}
+
+// InvalidHousekeepingRuleError 整理规则不合法
+type InvalidHousekeepingRuleError struct {
+	Reason string
+}
+
+func (e *InvalidHousekeepingRuleError) Error() string {
+	return "invalid housekeeping rule: " + e.Reason
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestHousekeepingRuleMatches(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	torrent := models.UnifiedTorrent{
+		ClientID:    "qb-1",
+		Hash:        testHashA,
+		State:       "forcedUP",
+		Size:        100,
+		Category:    "tv",
+		Ratio:       1.5,
+		SeedingTime: 3600,
+		Tracker:     "https://announce.tracker.example.org/announce",
+		AddedOn:     now.Add(-2 * time.Hour).Unix(),
+	}
+	float := func(v float64) *float64 { return &v }
+	int64p := func(v int64) *int64 { return &v }
+	str := func(v string) *string { return &v }
+	tests := []struct {
+		name string
+		rule models.HousekeepingRule
+		free int64
+		want bool
+	}{
+		{"other client", models.HousekeepingRule{ClientID: "tr-1"}, 0, false},
+		{"same client", models.HousekeepingRule{ClientID: "qb-1"}, 0, true},
+		{"ratio reached", models.HousekeepingRule{MinRatio: float(1.5)}, 0, true},
+		{"ratio not reached", models.HousekeepingRule{MinRatio: float(2)}, 0, false},
+		{"seeding time in minutes", models.HousekeepingRule{MinSeedingTime: int64p(60)}, 0, true},
+		{"seeding time not reached", models.HousekeepingRule{MinSeedingTime: int64p(61)}, 0, false},
+		{"normalized state", models.HousekeepingRule{States: "downloading, seeding"}, 0, true},
+		{"other state", models.HousekeepingRule{States: "paused"}, 0, false},
+		{"category", models.HousekeepingRule{Category: str("tv")}, 0, true},
+		{"uncategorized only", models.HousekeepingRule{Category: str("")}, 0, false},
+		{"tracker subdomain", models.HousekeepingRule{Tracker: "example.org"}, 0, true},
+		{"tracker suffix is not a subdomain", models.HousekeepingRule{Tracker: "ample.org"}, 0, false},
+		{"size in range", models.HousekeepingRule{MinSize: int64p(100), MaxSize: int64p(100)}, 0, true},
+		{"too small", models.HousekeepingRule{MinSize: int64p(101)}, 0, false},
+		{"old enough", models.HousekeepingRule{MinAge: int64p(120)}, 0, true},
+		{"too young", models.HousekeepingRule{MinAge: int64p(121)}, 0, false},
+		{"low free space", models.HousekeepingRule{MaxFreeSpace: int64p(1000)}, 999, true},
+		{"enough free space", models.HousekeepingRule{MaxFreeSpace: int64p(1000)}, 1000, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &fakeClient{id: "qb-1", free: test.free}
+			engine := NewHousekeepingEngine(NewTorrentService([]clients.DownloaderClient{client}, nil), nil, time.Minute)
+			if got := engine.matches(&test.rule, torrent, map[string]*int64{}, now); got != test.want {
+				t.Errorf("matches() = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHousekeepingSkipsDisabledRules(t *testing.T) {
+	db := newTestDB(t)
+	client := &fakeClient{
+		id: "qb-1",
+		torrents: []models.UnifiedTorrent{
+			{ClientID: "qb-1", Name: "seeded", Hash: testHashA, State: models.StateSeeding, Progress: 1, Ratio: 3},
+		},
+	}
+	engine := NewHousekeepingEngine(NewTorrentService([]clients.DownloaderClient{client}, db), db, time.Minute)
+
+	minRatio := 2.0
+	rule := models.HousekeepingRule{
+		Name:     "remove seeded",
+		MinRatio: &minRatio,
+		Action:   models.HousekeepingRemoveWithData,
+		Enabled:  false,
+	}
+	if err := engine.CreateRule(nil, &rule); err != nil {
+		t.Fatalf("CreateRule() error = %v", err)
+	}
+
+	saved, err := engine.GetRule(rule.ID)
+	if err != nil {
+		t.Fatalf("GetRule() error = %v", err)
+	}
+	if saved.Enabled {
+		t.Fatalf("rule created with enabled=false was saved as enabled")
+	}
+
+	engine.evaluate(time.Now())
+	if calls := client.Calls(); len(calls) != 0 {
+		t.Fatalf("disabled rule executed: client calls = %v", calls)
+	}
+	executions, err := engine.ListExecutions(rule.ID, 10)
+	if err != nil {
+		t.Fatalf("ListExecutions() error = %v", err)
+	}
+	if len(executions) != 0 {
+		t.Fatalf("disabled rule recorded executions: %+v", executions)
+	}
+
+	// 启用后同一规则会被执行，且只执行一次
+	saved.Enabled = true
+	if err := engine.UpdateRule(nil, rule.ID, saved); err != nil {
+		t.Fatalf("UpdateRule() error = %v", err)
+	}
+	engine.evaluate(time.Now())
+	if calls, want := client.Calls(), []string{"delete " + testHashA}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("client calls after enabling = %v, want %v", calls, want)
+	}
+}
+
+func TestHousekeepingStopsWhenEnoughSpaceIsFreed(t *testing.T) {
+	const gb = int64(1) << 30
+	tests := []struct {
+		name    string
+		free    int64
+		deletes int
+	}{
+		{"enough space", 35 * gb, 0},
+		{"one removal is enough", 25 * gb, 1},
+		{"two removals are needed", 15 * gb, 2},
+		{"all removals are not enough", 0, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := newTestDB(t)
+			client := &fakeClient{id: "qb-1", free: test.free}
+			for _, hash := range []string{testHashA, testHashB, "3333333333333333333333333333333333333333"} {
+				client.torrents = append(client.torrents, models.UnifiedTorrent{
+					ClientID: "qb-1", Name: hash[:1], Hash: hash, State: models.StateSeeding, Progress: 1, Size: 10 * gb,
+				})
+			}
+			engine := NewHousekeepingEngine(NewTorrentService([]clients.DownloaderClient{client}, db), db, time.Minute)
+
+			maxFree := 35 * gb
+			rule := models.HousekeepingRule{
+				Name:         "free space",
+				MaxFreeSpace: &maxFree,
+				Action:       models.HousekeepingRemoveWithData,
+				Enabled:      true,
+			}
+			if err := engine.CreateRule(nil, &rule); err != nil {
+				t.Fatalf("CreateRule() error = %v", err)
+			}
+
+			engine.evaluate(time.Now())
+			if calls := client.Calls(); len(calls) != test.deletes {
+				t.Errorf("client calls = %v, want %d deletions", calls, test.deletes)
+			}
+		})
+	}
+}
@@ -0,0 +1,484 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+const (
+	// defaultMigrationVerifyTimeout 单个种子等待目标客户端开始做种的默认时长
+	defaultMigrationVerifyTimeout = 30 * time.Minute
+	// migrationPollInterval 校验阶段查询目标客户端的间隔
+	migrationPollInterval = 2 * time.Second
+	// migrationSettlePolls 校验结束后完成度持续不足多少次才判定数据不完整，
+	// 避免把校验开始前的初始状态误判为失败
+	migrationSettlePolls = 3
+	// maxMigrationJobs 内存中保留的任务数量，超出时丢弃最早的已结束任务
+	maxMigrationJobs = 50
+)
+
+// MigrationRequest 将种子从一个客户端迁移到另一个客户端的请求
+type MigrationRequest struct {
+	SourceClient string
+	TargetClient string
+	// Hashes 为空表示迁移源客户端上的全部种子
+	Hashes []string
+	// PathMap 保存路径映射，键为源客户端中的路径前缀，值为目标客户端中对应的路径，按最长前缀匹配；
+	// 未命中时使用相同路径，适用于两个客户端以相同路径挂载数据目录的情况
+	PathMap map[string]string
+	// SkipHashCheck 目标客户端跳过哈希校验，仅 qBittorrent 支持
+	SkipHashCheck bool
+	// DryRun 只导出种子并计算目标路径，不修改任何客户端
+	DryRun bool
+	// VerifyTimeout 每个种子等待目标客户端开始做种的最长时间，为 0 时使用默认值
+	VerifyTimeout time.Duration
+}
+
+// migrationRegistry 迁移任务的内存记录，所有权限视图共享
+type migrationRegistry struct {
+	mutex  sync.Mutex
+	nextID uint
+	jobs   []*models.MigrationJob
+	// active 正在迁移的种子，键为 torrentKey，值为任务 ID
+	active map[string]uint
+}
+
+func newMigrationRegistry() *migrationRegistry {
+	return &migrationRegistry{active: make(map[string]uint)}
+}
+
+// snapshot 返回任务的副本，避免读取时与执行中的更新竞争
+func (r *migrationRegistry) snapshot(job *models.MigrationJob) models.MigrationJob {
+	copied := *job
+	copied.Items = append([]models.MigrationItem(nil), job.Items...)
+	return copied
+}
+
+// update 在锁内修改任务中的一个种子
+func (r *migrationRegistry) update(job *models.MigrationJob, index int, modify func(*models.MigrationItem)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	modify(&job.Items[index])
+}
+
+// StartMigration 创建迁移任务并在后台执行，仅管理员可用
+// 种子按顺序逐个迁移：从源客户端导出 .torrent 文件，以映射后的保存路径暂停添加到目标客户端，
+// 校验数据并确认开始做种后才从源客户端移除（保留数据）；任一步骤失败时移除目标客户端上的副本，源种子保持不变
+func (ts *TorrentService) StartMigration(req MigrationRequest) (models.MigrationJob, error) {
+	if err := ts.requireAdmin("migrate torrents"); err != nil {
+		return models.MigrationJob{}, err
+	}
+	if req.SourceClient == req.TargetClient {
+		return models.MigrationJob{}, &InvalidMigrationError{Reason: "source and target client must differ"}
+	}
+	source, err := ts.getClient(req.SourceClient)
+	if err != nil {
+		return models.MigrationJob{}, err
+	}
+	target, err := ts.getClient(req.TargetClient)
+	if err != nil {
+		return models.MigrationJob{}, err
+	}
+	for from, to := range req.PathMap {
+		if !path.IsAbs(from) || !path.IsAbs(to) {
+			return models.MigrationJob{}, &InvalidMigrationError{Reason: "path map entries must be absolute paths"}
+		}
+	}
+	if req.VerifyTimeout <= 0 {
+		req.VerifyTimeout = defaultMigrationVerifyTimeout
+	}
+
+	// 实时查询两端的种子列表，不使用可能过期的快照
+	sourceTorrents, err := source.GetTorrents()
+	if err != nil {
+		return models.MigrationJob{}, fmt.Errorf("list torrents on %s: %w", req.SourceClient, err)
+	}
+	targetTorrents, err := target.GetTorrents()
+	if err != nil {
+		return models.MigrationJob{}, fmt.Errorf("list torrents on %s: %w", req.TargetClient, err)
+	}
+
+	job := &models.MigrationJob{
+		SourceClient:  req.SourceClient,
+		TargetClient:  req.TargetClient,
+		DryRun:        req.DryRun,
+		SkipHashCheck: req.SkipHashCheck,
+		CreatedBy:     models.AuditActorSystem,
+		CreatedAt:     time.Now(),
+		Items:         planMigration(req, sourceTorrents, targetTorrents),
+	}
+	job.Total = len(job.Items)
+	if ts.access != nil {
+		job.CreatedBy = ts.access.Username
+	}
+
+	sources := make(map[string]models.UnifiedTorrent, len(sourceTorrents))
+	for _, torrent := range sourceTorrents {
+		sources[strings.ToLower(torrent.Hash)] = torrent
+	}
+
+	registry := ts.migrations
+	registry.mutex.Lock()
+	registry.nextID++
+	job.ID = registry.nextID
+	for i := range job.Items {
+		item := &job.Items[i]
+		if item.Step != models.MigrationPending {
+			continue
+		}
+		key := torrentKey(req.SourceClient, item.Hash)
+		if other, ok := registry.active[key]; ok {
+			item.Step = models.MigrationFailed
+			item.Error = fmt.Sprintf("already being migrated by job %d", other)
+			continue
+		}
+		if !req.DryRun {
+			registry.active[key] = job.ID
+		}
+	}
+	registry.jobs = append(registry.jobs, job)
+	registry.prune()
+	snapshot := registry.snapshot(job)
+	registry.mutex.Unlock()
+
+	go ts.runMigration(job, req, source, target, sources)
+	return snapshot, nil
+}
+
+// planMigration 确定要迁移的种子，无法迁移的种子直接标记为失败
+func planMigration(req MigrationRequest, sourceTorrents, targetTorrents []models.UnifiedTorrent) []models.MigrationItem {
+	onTarget := make(map[string]bool, len(targetTorrents))
+	for _, torrent := range targetTorrents {
+		onTarget[strings.ToLower(torrent.Hash)] = true
+	}
+	bySourceHash := make(map[string]models.UnifiedTorrent, len(sourceTorrents))
+	for _, torrent := range sourceTorrents {
+		bySourceHash[strings.ToLower(torrent.Hash)] = torrent
+	}
+
+	hashes := req.Hashes
+	if len(hashes) == 0 {
+		for hash := range bySourceHash {
+			hashes = append(hashes, hash)
+		}
+		sort.Slice(hashes, func(i, j int) bool {
+			return bySourceHash[hashes[i]].Name < bySourceHash[hashes[j]].Name
+		})
+	}
+
+	items := make([]models.MigrationItem, 0, len(hashes))
+	seen := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		hash = strings.ToLower(hash)
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		item := models.MigrationItem{Hash: hash, Step: models.MigrationPending}
+		torrent, ok := bySourceHash[hash]
+		switch {
+		case !ok:
+			item.Step, item.Error = models.MigrationFailed, "torrent not found on source client"
+		case onTarget[hash]:
+			item.Step, item.Error = models.MigrationFailed, "torrent already exists on target client"
+		case torrent.Progress < 1:
+			item.Step, item.Error = models.MigrationFailed, "torrent is not complete"
+		}
+		if ok {
+			item.Name, item.Size = torrent.Name, torrent.Size
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// runMigration 依次迁移任务中的种子
+func (ts *TorrentService) runMigration(job *models.MigrationJob, req MigrationRequest, source, target clients.DownloaderClient, sources map[string]models.UnifiedTorrent) {
+	registry := ts.migrations
+	for i := range job.Items {
+		registry.mutex.Lock()
+		item := job.Items[i]
+		registry.mutex.Unlock()
+		if item.Step != models.MigrationPending {
+			continue
+		}
+
+		err := ts.migrateTorrent(job, i, req, source, target, sources[item.Hash])
+		registry.mutex.Lock()
+		now := time.Now()
+		result := &job.Items[i]
+		result.FinishedAt = &now
+		if err != nil {
+			result.Step = models.MigrationFailed
+			result.Error = err.Error()
+			job.Failed++
+			log.Printf("⚠️  迁移种子失败 [%s → %s/%s]: %v", req.SourceClient, req.TargetClient, item.Hash, err)
+		} else if req.DryRun {
+			result.Step = models.MigrationPlanned
+			job.Done++
+		} else {
+			result.Step = models.MigrationDone
+			job.Done++
+		}
+		delete(registry.active, torrentKey(req.SourceClient, item.Hash))
+		registry.mutex.Unlock()
+	}
+
+	registry.mutex.Lock()
+	now := time.Now()
+	job.FinishedAt = &now
+	done, failed := job.Done, job.Failed
+	registry.mutex.Unlock()
+	log.Printf("✅ 迁移任务 %d 结束 [%s → %s]: 成功 %d，失败 %d", job.ID, req.SourceClient, req.TargetClient, done, failed)
+}
+
+// migrateTorrent 迁移单个种子，返回错误时源客户端上的种子保持不变
+func (ts *TorrentService) migrateTorrent(job *models.MigrationJob, index int, req MigrationRequest, source, target clients.DownloaderClient, torrent models.UnifiedTorrent) (err error) {
+	registry := ts.migrations
+	setStep := func(step models.MigrationStep) {
+		registry.update(job, index, func(item *models.MigrationItem) { item.Step = step })
+	}
+
+	now := time.Now()
+	var hash, targetPath string
+	registry.update(job, index, func(item *models.MigrationItem) {
+		item.StartedAt = &now
+		item.Step = models.MigrationExporting
+		hash = item.Hash
+	})
+
+	if !req.DryRun {
+		defer func() {
+			params := map[string]interface{}{"targetClient": req.TargetClient, "targetPath": targetPath, "skipHashCheck": req.SkipHashCheck}
+			ts.audit(models.AuditActionMigrate, req.SourceClient, hash, params, err)
+		}()
+	}
+
+	export, err := source.ExportTorrent(hash)
+	if err != nil {
+		return fmt.Errorf("export from source client: %w", err)
+	}
+	targetPath = mapSavePath(export.SavePath, req.PathMap)
+	registry.update(job, index, func(item *models.MigrationItem) {
+		item.SourcePath = export.SavePath
+		item.TargetPath = targetPath
+	})
+	if req.DryRun {
+		return nil
+	}
+
+	setStep(models.MigrationAdding)
+	options := models.ImportOptions{SavePath: targetPath, SkipChecking: req.SkipHashCheck, Paused: true}
+	if _, err := target.ImportTorrent(export.MetaInfo, options); err != nil {
+		return fmt.Errorf("add to target client: %w", err)
+	}
+	ts.invalidate(req.TargetClient, hash, false)
+
+	setStep(models.MigrationVerifying)
+	// 源种子处于暂停状态时，目标客户端上同样保持暂停
+	err = awaitSeeding(target, hash, !req.SkipHashCheck, !isPausedState(torrent.State), req.VerifyTimeout, func(progress float64) {
+		registry.update(job, index, func(item *models.MigrationItem) { item.Progress = progress })
+	})
+	if err != nil {
+		if removeErr := target.DeleteTorrent(hash, false); removeErr != nil {
+			log.Printf("⚠️  迁移失败后移除目标客户端上的种子失败 [%s/%s]: %v", req.TargetClient, hash, removeErr)
+		}
+		ts.invalidate(req.TargetClient, hash, true)
+		return fmt.Errorf("verify on target client: %w", err)
+	}
+
+	ts.copyLabels(target, hash, torrent)
+
+	setStep(models.MigrationRemoving)
+	if err := source.DeleteTorrent(hash, false); err != nil {
+		// 此时两个客户端都在做种，不回滚已验证的目标副本
+		return fmt.Errorf("remove from source client (torrent is now seeding on both clients): %w", err)
+	}
+	ts.invalidate(req.SourceClient, hash, true)
+	ts.moveOwnership(req.SourceClient, req.TargetClient, hash)
+	return nil
+}
+
+// copyLabels 在目标客户端上恢复源种子的分类与标签，失败只记录日志，不影响迁移
+func (ts *TorrentService) copyLabels(target clients.DownloaderClient, hash string, torrent models.UnifiedTorrent) {
+	targetID := target.GetClientID()
+	if torrent.Category != "" {
+		// 由 Down-Nexus 管理的分类按目标客户端的保存路径创建，其余分类需已存在于目标客户端
+		if ts.db != nil {
+			if definition, err := ts.GetCategory(torrent.Category); err == nil {
+				if err := target.EnsureCategory(definition.Name, definition.SavePathFor(targetID)); err != nil {
+					log.Printf("⚠️  同步分类 %q 到客户端 [%s] 失败: %v", definition.Name, targetID, err)
+				}
+			}
+		}
+		if err := target.SetCategory([]string{hash}, torrent.Category); err != nil {
+			log.Printf("⚠️  迁移后设置分类失败 [%s/%s]: %v", targetID, hash, err)
+		}
+	}
+	if len(torrent.Tags) > 0 {
+		if err := target.AddTags([]string{hash}, torrent.Tags); err != nil {
+			log.Printf("⚠️  迁移后添加标签失败 [%s/%s]: %v", targetID, hash, err)
+		}
+	}
+}
+
+// awaitSeeding 等待目标客户端上的种子校验完成并开始做种
+// recheck 为 true 时先触发重新校验；resume 为 false 时（源种子处于暂停状态）校验完成即视为成功，种子保持暂停
+func awaitSeeding(target clients.DownloaderClient, hash string, recheck, resume bool, timeout time.Duration, report func(float64)) error {
+	deadline := time.Now().Add(timeout)
+	rechecked, resumed := !recheck, false
+	incomplete := 0
+
+	for {
+		torrent, found, err := findClientTorrent(target, hash)
+		switch {
+		case err != nil:
+			log.Printf("⚠️  查询目标客户端 [%s] 失败: %v", target.GetClientID(), err)
+		case !found:
+			// qBittorrent 异步添加种子，可能尚未出现在列表中
+		case !rechecked:
+			if err := target.RecheckTorrent(hash); err != nil {
+				return fmt.Errorf("recheck: %w", err)
+			}
+			rechecked = true
+		default:
+			report(torrent.Progress)
+			state := models.NormalizeState(torrent.State)
+			switch {
+			case state == models.StateError:
+				return fmt.Errorf("target client reported state %q", torrent.State)
+			case state == models.StateChecking:
+				incomplete = 0
+			case torrent.Progress < 1:
+				incomplete++
+				if incomplete >= migrationSettlePolls {
+					return fmt.Errorf("data at the target path is incomplete (%.1f%%)", torrent.Progress*100)
+				}
+			case !resume:
+				return nil
+			case !resumed:
+				if err := target.ResumeTorrent(hash); err != nil {
+					return fmt.Errorf("resume: %w", err)
+				}
+				resumed = true
+			case state == models.StateSeeding, state == models.StateStalled, state == models.StateQueued:
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("torrent did not start seeding within %s", timeout)
+		}
+		time.Sleep(migrationPollInterval)
+	}
+}
+
+// findClientTorrent 直接从客户端查询指定哈希的种子
+func findClientTorrent(client clients.DownloaderClient, hash string) (models.UnifiedTorrent, bool, error) {
+	torrents, err := client.GetTorrents()
+	if err != nil {
+		return models.UnifiedTorrent{}, false, err
+	}
+	for _, torrent := range torrents {
+		if strings.EqualFold(torrent.Hash, hash) {
+			return torrent, true, nil
+		}
+	}
+	return models.UnifiedTorrent{}, false, nil
+}
+
+// mapSavePath 按最长前缀将源客户端的保存路径映射为目标客户端的路径，只匹配完整的路径段
+func mapSavePath(savePath string, pathMap map[string]string) string {
+	found := false
+	var matched, replacement string
+	for from, to := range pathMap {
+		prefix := strings.TrimSuffix(from, "/")
+		if savePath != prefix && !strings.HasPrefix(savePath, prefix+"/") {
+			continue
+		}
+		if !found || len(prefix) > len(matched) {
+			found, matched, replacement = true, prefix, strings.TrimSuffix(to, "/")
+		}
+	}
+	if !found {
+		return savePath
+	}
+	return replacement + strings.TrimPrefix(savePath, matched)
+}
+
+// ListMigrations 列出内存中的迁移任务，最近的在前，仅管理员可用
+func (ts *TorrentService) ListMigrations() ([]models.MigrationJob, error) {
+	if err := ts.requireAdmin("view migrations"); err != nil {
+		return nil, err
+	}
+
+	registry := ts.migrations
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	jobs := make([]models.MigrationJob, 0, len(registry.jobs))
+	for i := len(registry.jobs) - 1; i >= 0; i-- {
+		jobs = append(jobs, registry.snapshot(registry.jobs[i]))
+	}
+	return jobs, nil
+}
+
+// GetMigration 获取迁移任务及每个种子的进度，仅管理员可用
+func (ts *TorrentService) GetMigration(id uint) (models.MigrationJob, error) {
+	if err := ts.requireAdmin("view migrations"); err != nil {
+		return models.MigrationJob{}, err
+	}
+
+	registry := ts.migrations
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	for _, job := range registry.jobs {
+		if job.ID == id {
+			return registry.snapshot(job), nil
+		}
+	}
+	return models.MigrationJob{}, &MigrationNotFoundError{ID: id}
+}
+
+// prune 丢弃超出数量上限的最早的已结束任务，调用方需持有锁
+func (r *migrationRegistry) prune() {
+	for len(r.jobs) > maxMigrationJobs {
+		index := -1
+		for i, job := range r.jobs {
+			if job.FinishedAt != nil {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return
+		}
+		r.jobs = append(r.jobs[:index], r.jobs[index+1:]...)
+	}
+}
+
+// InvalidMigrationError 迁移请求不合法
+type InvalidMigrationError struct {
+	Reason string
+}
+
+func (e *InvalidMigrationError) Error() string {
+	return "invalid migration: " + e.Reason
+}
+
+// MigrationNotFoundError 迁移任务不存在
+type MigrationNotFoundError struct {
+	ID uint
+}
+
+func (e *MigrationNotFoundError) Error() string {
+	return fmt.Sprintf("migration not found: %d", e.ID)
+}
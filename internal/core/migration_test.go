@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestMapSavePath(t *testing.T) {
+	pathMap := map[string]string{
+		"/data":           "/mnt/data",
+		"/data/movies/":   "/mnt/movies/",
+		"/downloads/tv":   "/tv",
+		"/downloads/tvhd": "/hd",
+	}
+	tests := []struct {
+		savePath string
+		want     string
+	}{
+		{"/data", "/mnt/data"},
+		{"/data/music", "/mnt/data/music"},
+		{"/data/movies", "/mnt/movies"},
+		{"/data/movies/2024", "/mnt/movies/2024"},
+		{"/downloads/tv/show", "/tv/show"},
+		{"/downloads/tvhd/show", "/hd/show"},
+		{"/downloads/tv2", "/downloads/tv2"},
+		{"/database", "/database"},
+		{"/other", "/other"},
+	}
+	for _, test := range tests {
+		t.Run(test.savePath, func(t *testing.T) {
+			if got := mapSavePath(test.savePath, pathMap); got != test.want {
+				t.Errorf("mapSavePath(%s) = %s, want %s", test.savePath, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPlanMigration(t *testing.T) {
+	const testHashC = "3333333333333333333333333333333333333333"
+	source := []models.UnifiedTorrent{
+		{Hash: testHashA, Name: "b", Progress: 1, Size: 10},
+		{Hash: testHashB, Name: "a", Progress: 0.5},
+		{Hash: testHashC, Name: "c", Progress: 1},
+	}
+	target := []models.UnifiedTorrent{{Hash: testHashC}}
+
+	tests := []struct {
+		name   string
+		hashes []string
+		want   []models.MigrationItem
+	}{
+		{
+			name: "all torrents by name",
+			want: []models.MigrationItem{
+				{Hash: testHashB, Name: "a", Step: models.MigrationFailed, Error: "torrent is not complete"},
+				{Hash: testHashA, Name: "b", Size: 10, Step: models.MigrationPending},
+				{Hash: testHashC, Name: "c", Step: models.MigrationFailed, Error: "torrent already exists on target client"},
+			},
+		},
+		{
+			name:   "selected hashes once",
+			hashes: []string{testHashA, testHashA, "4444444444444444444444444444444444444444"},
+			want: []models.MigrationItem{
+				{Hash: testHashA, Name: "b", Size: 10, Step: models.MigrationPending},
+				{Hash: "4444444444444444444444444444444444444444", Step: models.MigrationFailed, Error: "torrent not found on source client"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items := planMigration(MigrationRequest{Hashes: test.hashes}, source, target)
+			if len(items) != len(test.want) {
+				t.Fatalf("planMigration() = %+v, want %+v", items, test.want)
+			}
+			for i := range items {
+				if items[i] != test.want[i] {
+					t.Errorf("item %d = %+v, want %+v", i, items[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStartMigrationValidation(t *testing.T) {
+	service := NewTorrentService([]clients.DownloaderClient{&fakeClient{id: "qb-1"}, &fakeClient{id: "tr-1"}}, newTestDB(t))
+	operator := service.WithAccess(&Access{UserID: 1, Username: "bob", Role: models.RoleOperator})
+
+	tests := []struct {
+		name    string
+		service *TorrentService
+		req     MigrationRequest
+		wantErr interface{}
+	}{
+		{"operator", operator, MigrationRequest{SourceClient: "qb-1", TargetClient: "tr-1"}, new(*PermissionDeniedError)},
+		{"same client", service, MigrationRequest{SourceClient: "qb-1", TargetClient: "qb-1"}, new(*InvalidMigrationError)},
+		{"relative path map", service, MigrationRequest{SourceClient: "qb-1", TargetClient: "tr-1", PathMap: map[string]string{"data": "/data"}}, new(*InvalidMigrationError)},
+		{"unknown client", service, MigrationRequest{SourceClient: "qb-1", TargetClient: "de-1"}, new(*ClientNotFoundError)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := test.service.StartMigration(test.req); !errors.As(err, test.wantErr) {
+				t.Errorf("StartMigration() error = %v, want %T", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ownershipIndex 种子归属的内存索引，首次使用时从数据库加载
+type ownershipIndex struct {
+	mutex  sync.RWMutex
+	loaded bool
+	// owners 键为 torrentKey
+	owners map[string]torrentOwner
+}
+
+type torrentOwner struct {
+	userID   uint
+	username string
+}
+
+func newOwnershipIndex() *ownershipIndex {
+	return &ownershipIndex{
+		owners: make(map[string]torrentOwner),
+	}
+}
+
+// RestrictToOwnTorrents 开启后非管理员只能管理自己添加的种子，查看不受影响
+func (ts *TorrentService) RestrictToOwnTorrents(enabled bool) {
+	ts.restrictOwnership = enabled
+}
+
+// ensureOwners 加载种子归属，失败时下次调用重试
+func (ts *TorrentService) ensureOwners() {
+	index := ts.owners
+	index.mutex.RLock()
+	loaded := index.loaded
+	index.mutex.RUnlock()
+	if loaded || ts.db == nil {
+		return
+	}
+
+	var rows []struct {
+		ClientID string
+		Hash     string
+		UserID   uint
+		Username string
+	}
+	err := ts.db.Table("torrent_ownerships").
+		Select("torrent_ownerships.client_id, torrent_ownerships.hash, torrent_ownerships.user_id, users.username").
+		Joins("LEFT JOIN users ON users.id = torrent_ownerships.user_id").
+		Scan(&rows).Error
+	if err != nil {
+		log.Printf("⚠️  加载种子归属失败: %v", err)
+		return
+	}
+
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	if index.loaded {
+		return
+	}
+	for _, row := range rows {
+		index.owners[torrentKey(row.ClientID, row.Hash)] = torrentOwner{userID: row.UserID, username: row.Username}
+	}
+	index.loaded = true
+}
+
+// recordOwnership 记录当前用户添加了种子，同一种子重复添加时更新为最近的用户
+func (ts *TorrentService) recordOwnership(clientID, hash, source string) {
+	if ts.access == nil || ts.db == nil {
+		return
+	}
+	hash = strings.ToLower(hash)
+
+	ownership := models.TorrentOwnership{
+		UserID:   ts.access.UserID,
+		ClientID: clientID,
+		Hash:     hash,
+		Source:   source,
+		AddedAt:  time.Now(),
+	}
+	err := ts.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_id"}, {Name: "hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "source", "added_at"}),
+	}).Create(&ownership).Error
+	if err != nil {
+		log.Printf("⚠️  记录种子归属失败 [%s/%s]: %v", clientID, hash, err)
+		return
+	}
+
+	ts.owners.mutex.Lock()
+	ts.owners.owners[torrentKey(clientID, hash)] = torrentOwner{userID: ts.access.UserID, username: ts.access.Username}
+	ts.owners.mutex.Unlock()
+}
+
+// moveOwnership 种子迁移到其他客户端后转移其归属记录
+func (ts *TorrentService) moveOwnership(fromClientID, toClientID, hash string) {
+	if ts.db == nil {
+		return
+	}
+	hash = strings.ToLower(hash)
+
+	err := ts.db.Transaction(func(tx *gorm.DB) error {
+		// 目标客户端上可能残留同一种子的旧记录
+		if err := tx.Where("client_id = ? AND hash = ?", toClientID, hash).Delete(&models.TorrentOwnership{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.TorrentOwnership{}).
+			Where("client_id = ? AND hash = ?", fromClientID, hash).
+			Update("client_id", toClientID).Error
+	})
+	if err != nil {
+		log.Printf("⚠️  转移种子归属失败 [%s → %s/%s]: %v", fromClientID, toClientID, hash, err)
+		return
+	}
+
+	ts.owners.mutex.Lock()
+	delete(ts.owners.owners, torrentKey(toClientID, hash))
+	if owner, ok := ts.owners.owners[torrentKey(fromClientID, hash)]; ok {
+		delete(ts.owners.owners, torrentKey(fromClientID, hash))
+		ts.owners.owners[torrentKey(toClientID, hash)] = owner
+	}
+	ts.owners.mutex.Unlock()
+}
+
+// annotateOwners 为种子填充 AddedBy
+func (ts *TorrentService) annotateOwners(torrents []models.UnifiedTorrent) {
+	ts.ensureOwners()
+
+	ts.owners.mutex.RLock()
+	defer ts.owners.mutex.RUnlock()
+	for i := range torrents {
+		if owner, ok := ts.owners.owners[torrentKey(torrents[i].ClientID, torrents[i].Hash)]; ok {
+			torrents[i].AddedBy = owner.username
+		}
+	}
+}
+
+// AuthorizeTorrent 在客户端权限的基础上检查种子归属
+// 开启归属限制时，非管理员只能操作自己添加的种子
+func (ts *TorrentService) AuthorizeTorrent(clientID, hash, action string) error {
+	if err := ts.authorize(clientID, action); err != nil {
+		return err
+	}
+	if !ts.restrictOwnership || ts.access.IsAdmin() {
+		return nil
+	}
+
+	ts.ensureOwners()
+	ts.owners.mutex.RLock()
+	owner, ok := ts.owners.owners[torrentKey(clientID, hash)]
+	ts.owners.mutex.RUnlock()
+	if !ok || owner.userID != ts.access.UserID {
+		return &PermissionDeniedError{ClientID: clientID, Action: action + " on torrents added by other users"}
+	}
+	return nil
+}
+
+// torrentClientFor 检查种子权限后返回对应的下载器客户端
+func (ts *TorrentService) torrentClientFor(clientID, hash, action string) (clients.DownloaderClient, error) {
+	if err := ts.AuthorizeTorrent(clientID, hash, action); err != nil {
+		return nil, err
+	}
+	return ts.getClient(clientID)
+}
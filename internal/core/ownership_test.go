@@ -0,0 +1,64 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestTorrentOwnership(t *testing.T) {
+	auth, _ := newTestAuth(t)
+	bob, err := auth.CreateUser(nil, "bob", testPassword, models.RoleOperator)
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	carol, err := auth.CreateUser(nil, "carol", testPassword, models.RoleOperator)
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	client := &fakeClient{id: "qb-1"}
+	service := NewTorrentService([]clients.DownloaderClient{client}, auth.db)
+	service.RestrictToOwnTorrents(true)
+	owner := service.WithAccess(NewAccess(bob))
+	other := service.WithAccess(NewAccess(carol))
+
+	if _, err := owner.AddTorrent("magnet:?xt=urn:btih:"+testHashA, "qb-1"); err != nil {
+		t.Fatalf("AddTorrent() error = %v", err)
+	}
+	client.torrents = []models.UnifiedTorrent{{ClientID: "qb-1", Hash: testHashA}, {ClientID: "qb-1", Hash: testHashB}}
+	torrents := service.GetAllTorrents()
+	if len(torrents) != 2 || torrents[0].AddedBy != "bob" || torrents[1].AddedBy != "" {
+		t.Errorf("GetAllTorrents() = %+v, want %s added by bob", torrents, testHashA)
+	}
+
+	tests := []struct {
+		name    string
+		service *TorrentService
+		hash    string
+		wantErr bool
+	}{
+		{"owner", owner, testHashA, false},
+		{"other user", other, testHashA, true},
+		{"torrent without owner", owner, testHashB, true},
+		{"admin", service.WithAccess(NewAccess(&models.User{Role: models.RoleAdmin})), testHashB, false},
+		{"internal call", service, testHashB, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.service.PauseTorrent("qb-1", test.hash)
+			var denied *PermissionDeniedError
+			if test.wantErr != errors.As(err, &denied) {
+				t.Errorf("PauseTorrent() error = %v, want PermissionDeniedError %t", err, test.wantErr)
+			}
+		})
+	}
+
+	// 未开启归属限制时只检查客户端权限
+	service.RestrictToOwnTorrents(false)
+	if err := service.WithAccess(NewAccess(carol)).PauseTorrent("qb-1", testHashA); err != nil {
+		t.Errorf("PauseTorrent() without restriction error = %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetQuota 获取用户的配额，未设置时返回空配额（不限制）
+func (ts *TorrentService) GetQuota(userID uint) (models.UserQuota, error) {
+	quota := models.UserQuota{UserID: userID}
+	err := ts.db.Where("user_id = ?", userID).First(&quota).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return quota, err
+	}
+	return quota, nil
+}
+
+// SetQuota 设置用户的配额，仅管理员可用
+func (ts *TorrentService) SetQuota(userID uint, quota models.UserQuota) (_ models.UserQuota, err error) {
+	defer func() {
+		params := map[string]interface{}{"userID": userID, "quota": quota}
+		ts.audit(models.AuditActionConfig, "", "", params, err)
+	}()
+
+	if err := ts.requireAdmin("set quota"); err != nil {
+		return quota, err
+	}
+	if err := quota.Validate(); err != nil {
+		return quota, err
+	}
+	if err := ts.db.Select("id").First(&models.User{}, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return quota, &UserNotFoundError{ID: userID}
+		}
+		return quota, err
+	}
+
+	quota.ID = 0
+	quota.UserID = userID
+	err = ts.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_active_downloads", "max_total_size", "max_adds_per_day"}),
+	}).Create(&quota).Error
+	return quota, err
+}
+
+// GetQuotaStatus 获取当前用户的配额与使用情况
+func (ts *TorrentService) GetQuotaStatus() (models.QuotaStatus, error) {
+	if ts.access == nil {
+		return models.QuotaStatus{Exempt: true}, nil
+	}
+
+	quota, err := ts.GetQuota(ts.access.UserID)
+	if err != nil {
+		return models.QuotaStatus{}, err
+	}
+	usage, err := ts.quotaUsage(ts.access.UserID)
+	if err != nil {
+		return models.QuotaStatus{}, err
+	}
+	return models.QuotaStatus{
+		Limits: quota,
+		Usage:  usage,
+		Exempt: ts.access.IsAdmin(),
+	}, nil
+}
+
+// quotaUsage 统计用户名下的种子
+// 下载中与总大小只计入客户端上仍存在的种子；添加次数按过去 24 小时只追加的审计日志中成功的添加操作统计，
+// 删除后重新添加同一种子同样计入
+func (ts *TorrentService) quotaUsage(userID uint) (models.QuotaUsage, error) {
+	var usage models.QuotaUsage
+
+	ts.ensureOwners()
+	torrents := ts.WithAccess(nil).GetAllTorrents()
+	ts.owners.mutex.RLock()
+	for _, torrent := range torrents {
+		owner, ok := ts.owners.owners[torrentKey(torrent.ClientID, torrent.Hash)]
+		if !ok || owner.userID != userID {
+			continue
+		}
+		usage.TotalSize += torrent.Size
+		if isActiveDownload(torrent) {
+			usage.ActiveDownloads++
+		}
+	}
+	ts.owners.mutex.RUnlock()
+
+	var adds int64
+	err := ts.db.Model(&models.AuditLog{}).
+		Where("user_id = ? AND action = ? AND success = ? AND created_at > ?", userID, models.AuditActionAdd, true, time.Now().Add(-24*time.Hour)).
+		Count(&adds).Error
+	usage.AddsLastDay = int(adds)
+	return usage, err
+}
+
+// isActiveDownload 判断种子是否占用下载配额：未完成且未暂停或出错
+func isActiveDownload(torrent models.UnifiedTorrent) bool {
+	if torrent.Progress >= 1 {
+		return false
+	}
+	state := models.NormalizeState(torrent.State)
+	return state != models.StatePaused && state != models.StateError
+}
+
+// checkQuota 添加种子前检查当前用户的配额，管理员与内部调用不受限制
+// 新种子的大小在添加前未知，总大小只检查是否已达到上限
+func (ts *TorrentService) checkQuota() error {
+	if ts.access.IsAdmin() || ts.db == nil {
+		return nil
+	}
+
+	quota, err := ts.GetQuota(ts.access.UserID)
+	if err != nil {
+		return err
+	}
+	if !quota.Limited() {
+		return nil
+	}
+
+	usage, err := ts.quotaUsage(ts.access.UserID)
+	if err != nil {
+		return err
+	}
+	if quota.MaxAddsPerDay != nil && usage.AddsLastDay >= *quota.MaxAddsPerDay {
+		return &QuotaExceededError{Quota: "max_adds_per_day", Limit: int64(*quota.MaxAddsPerDay), Used: int64(usage.AddsLastDay)}
+	}
+	if quota.MaxActiveDownloads != nil && usage.ActiveDownloads >= *quota.MaxActiveDownloads {
+		return &QuotaExceededError{Quota: "max_active_downloads", Limit: int64(*quota.MaxActiveDownloads), Used: int64(usage.ActiveDownloads)}
+	}
+	if quota.MaxTotalSize != nil && usage.TotalSize >= *quota.MaxTotalSize {
+		return &QuotaExceededError{Quota: "max_total_size", Limit: *quota.MaxTotalSize, Used: usage.TotalSize}
+	}
+	return nil
+}
+
+// requiresOwnership 当前用户受归属限制或配额限制时返回 true
+// 此时添加的每个种子都必须确定哈希并记录归属，否则用户无法管理该种子，配额也无法统计
+func (ts *TorrentService) requiresOwnership() bool {
+	if ts.access.IsAdmin() || ts.db == nil {
+		return false
+	}
+	if ts.restrictOwnership {
+		return true
+	}
+	quota, err := ts.GetQuota(ts.access.UserID)
+	return err != nil || quota.Limited()
+}
+
+// QuotaExceededError 超出用户配额
+type QuotaExceededError struct {
+	Quota string
+	Limit int64
+	Used  int64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return "quota exceeded: " + e.Quota + " (used " + strconv.FormatInt(e.Used, 10) +
+		", limit " + strconv.FormatInt(e.Limit, 10) + ")"
+}
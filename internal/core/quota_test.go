@@ -0,0 +1,123 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestIsActiveDownload(t *testing.T) {
+	tests := []struct {
+		name    string
+		torrent models.UnifiedTorrent
+		want    bool
+	}{
+		{"downloading", models.UnifiedTorrent{State: "downloading", Progress: 0.5}, true},
+		{"stalled", models.UnifiedTorrent{State: "stalledDL", Progress: 0.5}, true},
+		{"queued", models.UnifiedTorrent{State: "queuedDL"}, true},
+		{"paused", models.UnifiedTorrent{State: "pausedDL", Progress: 0.5}, false},
+		{"error", models.UnifiedTorrent{State: "error", Progress: 0.5}, false},
+		{"complete", models.UnifiedTorrent{State: "uploading", Progress: 1}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isActiveDownload(test.torrent); got != test.want {
+				t.Errorf("isActiveDownload(%+v) = %t, want %t", test.torrent, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	one, two := 1, 2
+	size := int64(1000)
+	tests := []struct {
+		name      string
+		quota     models.UserQuota
+		torrents  []models.UnifiedTorrent
+		wantQuota string
+	}{
+		{
+			name:     "no quota",
+			torrents: []models.UnifiedTorrent{{ClientID: "qb-1", Hash: testHashA, State: "downloading", Size: 5000}},
+		},
+		{
+			name:     "below limits",
+			quota:    models.UserQuota{MaxActiveDownloads: &two, MaxTotalSize: &size, MaxAddsPerDay: &two},
+			torrents: []models.UnifiedTorrent{{ClientID: "qb-1", Hash: testHashA, State: "downloading", Size: 500}},
+		},
+		{
+			name:      "active downloads",
+			quota:     models.UserQuota{MaxActiveDownloads: &one},
+			torrents:  []models.UnifiedTorrent{{ClientID: "qb-1", Hash: testHashA, State: "downloading"}},
+			wantQuota: "max_active_downloads",
+		},
+		{
+			name:     "paused torrents are not active",
+			quota:    models.UserQuota{MaxActiveDownloads: &one},
+			torrents: []models.UnifiedTorrent{{ClientID: "qb-1", Hash: testHashA, State: "pausedDL"}},
+		},
+		{
+			name:      "total size",
+			quota:     models.UserQuota{MaxTotalSize: &size},
+			torrents:  []models.UnifiedTorrent{{ClientID: "qb-1", Hash: testHashA, State: "uploading", Progress: 1, Size: 1000}},
+			wantQuota: "max_total_size",
+		},
+		{
+			name:      "adds per day",
+			quota:     models.UserQuota{MaxAddsPerDay: &one},
+			wantQuota: "max_adds_per_day",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			auth, _ := newTestAuth(t)
+			user, err := auth.CreateUser(nil, "bob", testPassword, models.RoleOperator)
+			if err != nil {
+				t.Fatalf("CreateUser() error = %v", err)
+			}
+			client := &fakeClient{id: "qb-1"}
+			service := NewTorrentService([]clients.DownloaderClient{client}, auth.db)
+			if _, err := service.SetQuota(user.ID, test.quota); err != nil {
+				t.Fatalf("SetQuota() error = %v", err)
+			}
+
+			// 第一次添加只用于产生归属记录，此时尚无种子占用配额
+			scoped := service.WithAccess(NewAccess(user))
+			if _, err := scoped.AddTorrent("magnet:?xt=urn:btih:"+testHashA, "qb-1"); err != nil {
+				t.Fatalf("first AddTorrent() error = %v", err)
+			}
+			client.torrents = test.torrents
+
+			_, err = scoped.AddTorrent("magnet:?xt=urn:btih:"+testHashB, "qb-1")
+			var exceeded *QuotaExceededError
+			switch {
+			case test.wantQuota == "" && err != nil:
+				t.Errorf("AddTorrent() error = %v", err)
+			case test.wantQuota != "" && (!errors.As(err, &exceeded) || exceeded.Quota != test.wantQuota):
+				t.Errorf("AddTorrent() error = %v, want %s exceeded", err, test.wantQuota)
+			}
+		})
+	}
+}
+
+func TestSetQuota(t *testing.T) {
+	auth, admin := newTestAuth(t)
+	service := NewTorrentService(nil, auth.db)
+	negative := -1
+
+	var denied *PermissionDeniedError
+	operator := service.WithAccess(NewAccess(&models.User{Role: models.RoleOperator}))
+	if _, err := operator.SetQuota(admin.ID, models.UserQuota{}); !errors.As(err, &denied) {
+		t.Errorf("SetQuota() as operator error = %v, want PermissionDeniedError", err)
+	}
+	if _, err := service.SetQuota(admin.ID, models.UserQuota{MaxAddsPerDay: &negative}); err == nil {
+		t.Error("SetQuota(negative) succeeded, want error")
+	}
+	var notFound *UserNotFoundError
+	if _, err := service.SetQuota(admin.ID+100, models.UserQuota{}); !errors.As(err, &notFound) {
+		t.Errorf("SetQuota(unknown user) error = %v, want UserNotFoundError", err)
+	}
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+// RenameTorrent 修改种子的显示名称
+func (ts *TorrentService) RenameTorrent(clientID, hash, name string) (err error) {
+	defer func() {
+		ts.audit(models.AuditActionRename, clientID, hash, map[string]interface{}{"name": name}, err)
+	}()
+
+	if err := validateName(name); err != nil {
+		return err
+	}
+
+	client, err := ts.torrentClientFor(clientID, hash, models.ActionEdit)
+	if err != nil {
+		return err
+	}
+	err = client.RenameTorrent(hash, name)
+	ts.invalidate(clientID, hash, false)
+	return err
+}
+
+// RenameFile 修改种子内文件或文件夹的路径
+func (ts *TorrentService) RenameFile(clientID, hash, oldPath, newPath string) (err error) {
+	defer func() {
+		params := map[string]interface{}{"oldPath": oldPath, "newPath": newPath}
+		ts.audit(models.AuditActionRename, clientID, hash, params, err)
+	}()
+
+	if err := validateRelativePath(oldPath); err != nil {
+		return err
+	}
+	if err := validateRelativePath(newPath); err != nil {
+		return err
+	}
+
+	client, err := ts.torrentClientFor(clientID, hash, models.ActionEdit)
+	if err != nil {
+		return err
+	}
+	err = client.RenameFile(hash, oldPath, newPath)
+	ts.invalidate(clientID, hash, false)
+	if errors.Is(err, clients.ErrCrossDirectoryRename) {
+		return &InvalidPathError{Path: newPath, Reason: "must be in the same directory as " + `"` + oldPath + `"` + " on this client"}
+	}
+	return err
+}
+
+// MoveTorrent 将种子数据移动到 location，location 须为客户端所在主机上的绝对路径
+func (ts *TorrentService) MoveTorrent(clientID, hash, location string) (err error) {
+	defer func() {
+		ts.audit(models.AuditActionMove, clientID, hash, map[string]interface{}{"location": location}, err)
+	}()
+
+	if err := validateLocation(location); err != nil {
+		return err
+	}
+
+	client, err := ts.torrentClientFor(clientID, hash, models.ActionEdit)
+	if err != nil {
+		return err
+	}
+	err = client.MoveTorrent(hash, location)
+	ts.invalidate(clientID, hash, false)
+	return err
+}
+
+// validateName 校验单级名称，禁止路径分隔符和 . / ..
+func validateName(name string) error {
+	switch {
+	case name == "":
+		return &InvalidPathError{Path: name, Reason: "must not be empty"}
+	case strings.ContainsAny(name, "/\\\x00"):
+		return &InvalidPathError{Path: name, Reason: "must not contain path separators"}
+	case name == "." || name == "..":
+		return &InvalidPathError{Path: name, Reason: "must not be a relative path element"}
+	}
+	return nil
+}
+
+// validateRelativePath 校验相对于种子根目录的路径，防止路径穿越
+func validateRelativePath(p string) error {
+	switch {
+	case p == "":
+		return &InvalidPathError{Path: p, Reason: "must not be empty"}
+	case strings.ContainsAny(p, "\\\x00"):
+		return &InvalidPathError{Path: p, Reason: "must use '/' as separator"}
+	case strings.HasPrefix(p, "/"):
+		return &InvalidPathError{Path: p, Reason: "must be relative"}
+	}
+
+	for _, segment := range strings.Split(p, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return &InvalidPathError{Path: p, Reason: "must not contain empty, '.' or '..' segments"}
+		}
+	}
+	return nil
+}
+
+// validateLocation 校验保存路径，与迁移的路径映射一样要求为绝对路径
+func validateLocation(location string) error {
+	switch {
+	case location == "":
+		return &InvalidPathError{Path: location, Reason: "must not be empty"}
+	case strings.ContainsRune(location, '\x00'):
+		return &InvalidPathError{Path: location, Reason: "must not contain NUL"}
+	case !path.IsAbs(location):
+		return &InvalidPathError{Path: location, Reason: "must be absolute"}
+	}
+
+	for _, segment := range strings.Split(location, "/") {
+		if segment == ".." {
+			return &InvalidPathError{Path: location, Reason: "must not contain '..' segments"}
+		}
+	}
+	return nil
+}
+
+// InvalidPathError 名称或路径不合法
+type InvalidPathError struct {
+	Path   string
+	Reason string
+}
+
+func (e *InvalidPathError) Error() string {
+	return "invalid path " + `"` + e.Path + `"` + ": " + e.Reason
+}
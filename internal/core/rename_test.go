@@ -0,0 +1,108 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"down-nexus-api/pkg/clients"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"movie.mkv", false},
+		{"Season 1", false},
+		{"", true},
+		{".", true},
+		{"..", true},
+		{"a/b", true},
+		{"a\\b", true},
+		{"a\x00b", true},
+	}
+	for _, test := range tests {
+		err := validateName(test.name)
+		if (err != nil) != test.wantErr {
+			t.Errorf("validateName(%q) error = %v, wantErr %t", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestValidateRelativePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"movie.mkv", false},
+		{"Season 1/e01.mkv", false},
+		{"", true},
+		{"/etc/passwd", true},
+		{"../outside", true},
+		{"a/../../outside", true},
+		{"a/./b", true},
+		{"a//b", true},
+		{"a/", true},
+		{"a\\b", true},
+	}
+	for _, test := range tests {
+		err := validateRelativePath(test.path)
+		var invalid *InvalidPathError
+		if test.wantErr != errors.As(err, &invalid) {
+			t.Errorf("validateRelativePath(%q) error = %v, wantErr %t", test.path, err, test.wantErr)
+		}
+	}
+}
+
+func TestRenameFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		sameDirRenames bool
+		oldPath        string
+		newPath        string
+		wantCalls      []string
+		wantInvalid    bool
+	}{
+		{
+			name:      "rename in place",
+			oldPath:   "dir/a.mkv",
+			newPath:   "dir/b.mkv",
+			wantCalls: []string{"renamefile " + testHashA + " dir/a.mkv dir/b.mkv"},
+		},
+		{
+			name:      "move to another directory",
+			oldPath:   "dir/a.mkv",
+			newPath:   "other/a.mkv",
+			wantCalls: []string{"renamefile " + testHashA + " dir/a.mkv other/a.mkv"},
+		},
+		{
+			name:           "cross-directory rename is rejected by the client",
+			sameDirRenames: true,
+			oldPath:        "dir/a.mkv",
+			newPath:        "other/a.mkv",
+			wantInvalid:    true,
+		},
+		{
+			name:        "path traversal",
+			oldPath:     "dir/a.mkv",
+			newPath:     "../a.mkv",
+			wantInvalid: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &fakeClient{id: "qb-1", sameDirRenames: test.sameDirRenames}
+			service := NewTorrentService([]clients.DownloaderClient{client}, newTestDB(t))
+
+			err := service.RenameFile("qb-1", testHashA, test.oldPath, test.newPath)
+			var invalid *InvalidPathError
+			if test.wantInvalid != errors.As(err, &invalid) {
+				t.Fatalf("RenameFile() error = %v, want InvalidPathError %t", err, test.wantInvalid)
+			}
+			if calls := client.Calls(); !reflect.DeepEqual(calls, test.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, test.wantCalls)
+			}
+		})
+	}
+}
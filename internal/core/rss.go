@@ -0,0 +1,555 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/rss"
+	"gorm.io/gorm"
+)
+
+const (
+	// rssCheckInterval 检查哪些订阅源到期需要抓取的周期
+	rssCheckInterval = 30 * time.Second
+	// rssRetryDelay 条目添加失败后首次重试的延迟，之后每次翻倍
+	rssRetryDelay = 5 * time.Minute
+	// rssMaxAttempts 条目最多尝试添加的次数
+	rssMaxAttempts = 5
+)
+
+// RSSPoller RSS 订阅抓取器
+// 按订阅源的间隔抓取 RSS/Atom 订阅，条目依次与过滤规则匹配，命中第一条规则时以内部调用的身份添加到客户端；
+// 处理过的条目按订阅源与 GUID 去重，之后的抓取不再处理；添加失败的条目按退避间隔重试，不依赖它是否仍在订阅中
+type RSSPoller struct {
+	service  *TorrentService
+	db       *gorm.DB
+	client   *http.Client
+	interval time.Duration
+
+	// mutex 串行执行抓取，避免定时抓取与手动刷新重复添加同一条目
+	mutex   sync.Mutex
+	trigger chan struct{}
+}
+
+// NewRSSPoller 创建订阅抓取器，client 为 nil 时使用默认超时的 HTTP 客户端，interval 为未设置间隔的订阅源的抓取间隔
+func NewRSSPoller(service *TorrentService, db *gorm.DB, client *http.Client, interval time.Duration) *RSSPoller {
+	if client == nil {
+		client = &http.Client{Timeout: downloadTimeout}
+	}
+	return &RSSPoller{
+		service:  service,
+		db:       db,
+		client:   client,
+		interval: interval,
+		trigger:  make(chan struct{}, 1),
+	}
+}
+
+// Run 启动抓取循环，直到 ctx 被取消
+func (p *RSSPoller) Run(ctx context.Context) {
+	ticker := time.NewTicker(rssCheckInterval)
+	defer ticker.Stop()
+
+	p.poll(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.trigger:
+		}
+		p.poll(time.Now())
+	}
+}
+
+// poll 抓取所有到期的订阅源
+func (p *RSSPoller) poll(now time.Time) {
+	var feeds []models.RSSFeed
+	if err := p.db.Where("enabled = ?", true).Order("id").Find(&feeds).Error; err != nil {
+		log.Printf("⚠️  加载 RSS 订阅源失败: %v", err)
+		return
+	}
+
+	for _, feed := range feeds {
+		interval := p.interval
+		if feed.Interval > 0 {
+			interval = time.Duration(feed.Interval) * time.Minute
+		}
+		if feed.LastFetchedAt != nil && now.Sub(*feed.LastFetchedAt) < interval {
+			continue
+		}
+		if _, err := p.fetch(feed); err != nil {
+			log.Printf("⚠️  抓取 RSS 订阅源 %s 失败: %v", feed.Name, err)
+		}
+	}
+	p.retry(now)
+}
+
+// retry 重新添加到期重试的失败条目，规则已删除或停用、或同一剧集已成功添加时不再重试
+func (p *RSSPoller) retry(now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var records []models.RSSItem
+	err := p.db.Where("success = ? AND next_retry_at <= ?", false, now).Order("id").Find(&records).Error
+	if err != nil {
+		log.Printf("⚠️  加载待重试的 RSS 条目失败: %v", err)
+		return
+	}
+
+	for i := range records {
+		record := &records[i]
+		var rule models.RSSRule
+		err := p.db.Where("id = ? AND enabled = ?", record.RuleID, true).First(&rule).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			record.NextRetryAt = nil
+		case err != nil:
+			log.Printf("⚠️  加载 RSS 规则失败 [%d]: %v", record.RuleID, err)
+			continue
+		case p.grabbedEpisode(rule, record.Episode):
+			record.NextRetryAt = nil
+		default:
+			p.attempt(rule, record)
+		}
+		if err := p.db.Save(record).Error; err != nil {
+			log.Printf("⚠️  记录 RSS 条目处理结果失败 [%s]: %v", record.Title, err)
+		}
+	}
+}
+
+// FetchFeed 立即抓取订阅源，返回本次新处理的条目
+func (p *RSSPoller) FetchFeed(id uint) (*models.RSSFetchResult, error) {
+	feed, err := p.GetFeed(id)
+	if err != nil {
+		return nil, err
+	}
+	return p.fetch(*feed)
+}
+
+// fetch 抓取订阅源并处理匹配规则的新条目，抓取时间与错误记录到订阅源
+func (p *RSSPoller) fetch(feed models.RSSFeed) (*models.RSSFetchResult, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var items []rss.Item
+	data, err := download(p.client, feed.URL)
+	if err == nil {
+		items, err = rss.Parse(data)
+	}
+	status := map[string]interface{}{"last_fetched_at": time.Now(), "last_error": ""}
+	if err != nil {
+		status["last_error"] = err.Error()
+	}
+	if err := p.db.Model(&models.RSSFeed{}).Where("id = ?", feed.ID).UpdateColumns(status).Error; err != nil {
+		log.Printf("⚠️  记录 RSS 订阅源 %s 的抓取状态失败: %v", feed.Name, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.process(feed, items)
+}
+
+// process 将未处理过的条目与规则匹配，并添加命中的条目
+func (p *RSSPoller) process(feed models.RSSFeed, items []rss.Item) (*models.RSSFetchResult, error) {
+	result := &models.RSSFetchResult{FeedID: feed.ID, Items: len(items), Grabbed: []models.RSSItem{}}
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	var rules []models.RSSRule
+	if err := p.db.Where("enabled = ? AND feed_id IN ?", true, []uint{0, feed.ID}).Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	matchers := make([]rssMatcher, 0, len(rules))
+	for _, rule := range rules {
+		include, exclude, err := rule.Patterns()
+		if err != nil {
+			log.Printf("⚠️  RSS 规则 %s 无效: %v", rule.Name, err)
+			continue
+		}
+		matchers = append(matchers, rssMatcher{rule: rule, include: include, exclude: exclude})
+	}
+	if len(matchers) == 0 {
+		return result, nil
+	}
+
+	guids := make([]string, 0, len(items))
+	for _, item := range items {
+		guids = append(guids, item.GUID)
+	}
+	var processed []string
+	if err := p.db.Model(&models.RSSItem{}).Where("feed_id = ? AND guid IN ?", feed.ID, guids).Pluck("guid", &processed).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(processed))
+	for _, guid := range processed {
+		seen[guid] = true
+	}
+
+	for _, item := range items {
+		if seen[item.GUID] {
+			continue
+		}
+		for _, matcher := range matchers {
+			episode, ok := matcher.matches(item)
+			if !ok || p.grabbedEpisode(matcher.rule, episode) {
+				continue
+			}
+			seen[item.GUID] = true
+			result.Grabbed = append(result.Grabbed, p.grab(feed, matcher.rule, item, episode))
+			break
+		}
+	}
+	return result, nil
+}
+
+// grabbedEpisode 规则要求同一剧集只添加一次且该剧集已成功添加过时返回 true
+func (p *RSSPoller) grabbedEpisode(rule models.RSSRule, episode string) bool {
+	if !rule.SkipGrabbedEpisodes || episode == "" {
+		return false
+	}
+	var count int64
+	if err := p.db.Model(&models.RSSItem{}).Where("rule_id = ? AND episode = ? AND success = ?", rule.ID, episode, true).Count(&count).Error; err != nil {
+		log.Printf("⚠️  查询 RSS 规则 %s 已添加的剧集失败: %v", rule.Name, err)
+		return true
+	}
+	return count > 0
+}
+
+// grab 添加条目并记录结果，失败的条目同样记录并等待重试
+func (p *RSSPoller) grab(feed models.RSSFeed, rule models.RSSRule, item rss.Item, episode string) models.RSSItem {
+	record := models.RSSItem{
+		FeedID:  feed.ID,
+		GUID:    item.GUID,
+		RuleID:  rule.ID,
+		Title:   item.Title,
+		Link:    item.Link,
+		Size:    item.Size,
+		Episode: episode,
+	}
+	p.attempt(rule, &record)
+
+	if err := p.db.Create(&record).Error; err != nil {
+		log.Printf("⚠️  记录 RSS 条目处理结果失败 [%s]: %v", item.Title, err)
+	}
+	return record
+}
+
+// attempt 按规则添加条目并更新记录的结果，失败时按已尝试次数计算下次重试时间
+// 达到次数上限或种子已存在于其他客户端时不再重试
+func (p *RSSPoller) attempt(rule models.RSSRule, record *models.RSSItem) {
+	item := rss.Item{GUID: record.GUID, Title: record.Title, Link: record.Link, Size: record.Size}
+	clientID, hash, err := p.add(rule, item)
+	record.Attempts++
+	record.ClientID = clientID
+	record.Hash = hash
+	record.Success = err == nil
+	record.Error = ""
+	record.NextRetryAt = nil
+	if err == nil {
+		log.Printf("📡 RSS 条目 %s 已按规则 %s 添加到客户端 [%s]", record.Title, rule.Name, clientID)
+		return
+	}
+
+	record.Error = err.Error()
+	var duplicate *DuplicateTorrentError
+	if record.Attempts < rssMaxAttempts && !errors.As(err, &duplicate) {
+		next := time.Now().Add(rssRetryDelay << (record.Attempts - 1))
+		record.NextRetryAt = &next
+		log.Printf("⚠️  RSS 条目 %s 添加失败（第 %d 次），将于 %s 重试: %v", record.Title, record.Attempts, next.Format(time.DateTime), err)
+	} else {
+		log.Printf("⚠️  RSS 条目 %s 添加失败（第 %d 次），不再重试: %v", record.Title, record.Attempts, err)
+	}
+}
+
+// add 添加条目，磁力链接直接交给客户端，其他地址先下载种子文件再添加
+func (p *RSSPoller) add(rule models.RSSRule, item rss.Item) (string, string, error) {
+	options := AddOptions{ClientID: rule.ClientID, Category: rule.Category}
+	if strings.HasPrefix(item.Link, "magnet:") {
+		options.Link = item.Link
+	} else {
+		data, err := download(p.client, item.Link)
+		if err != nil {
+			return rule.ClientID, "", err
+		}
+		options.Data = data
+	}
+	result, err := p.service.AddWithOptions(options)
+	if err != nil {
+		return result.ClientID, result.Hash, err
+	}
+
+	// 设置分类失败不影响已添加的种子
+	if result.CategoryError != nil {
+		log.Printf("⚠️  RSS 规则 %s 添加的种子设置分类失败: %v", rule.Name, result.CategoryError)
+	}
+	return result.ClientID, result.Hash, nil
+}
+
+// rssMatcher 编译后的过滤规则
+type rssMatcher struct {
+	rule    models.RSSRule
+	include *regexp.Regexp
+	exclude *regexp.Regexp
+}
+
+// matches 判断条目是否满足规则，返回识别出的剧集（S01E02 格式，未识别时为空）
+func (m rssMatcher) matches(item rss.Item) (string, bool) {
+	rule := m.rule
+	if m.include != nil && !m.include.MatchString(item.Title) {
+		return "", false
+	}
+	if m.exclude != nil && m.exclude.MatchString(item.Title) {
+		return "", false
+	}
+	if rule.MinSize > 0 || rule.MaxSize > 0 {
+		if item.Size <= 0 || item.Size < rule.MinSize || (rule.MaxSize > 0 && item.Size > rule.MaxSize) {
+			return "", false
+		}
+	}
+
+	season, number, ok := rss.Episode(item.Title)
+	episode := ""
+	if ok {
+		episode = fmt.Sprintf("S%02dE%02d", season, number)
+	}
+	if rule.Season > 0 || rule.MinEpisode > 0 || rule.MaxEpisode > 0 {
+		if !ok || (rule.Season > 0 && season != rule.Season) {
+			return "", false
+		}
+		if number < rule.MinEpisode || (rule.MaxEpisode > 0 && number > rule.MaxEpisode) {
+			return "", false
+		}
+	}
+	return episode, true
+}
+
+// refresh 立即检查一轮到期的订阅源
+func (p *RSSPoller) refresh() {
+	select {
+	case p.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// ListFeeds 获取所有订阅源
+func (p *RSSPoller) ListFeeds() ([]models.RSSFeed, error) {
+	var feeds []models.RSSFeed
+	err := p.db.Order("id").Find(&feeds).Error
+	return feeds, err
+}
+
+// GetFeed 获取单个订阅源
+func (p *RSSPoller) GetFeed(id uint) (*models.RSSFeed, error) {
+	var feed models.RSSFeed
+	if err := p.db.First(&feed, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &RSSFeedNotFoundError{ID: id}
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// CreateFeed 创建订阅源，创建后立即抓取
+// 订阅地址可能包含私有 tracker 的 passkey，审计日志只记录订阅源名称
+func (p *RSSPoller) CreateFeed(access *Access, feed *models.RSSFeed) (err error) {
+	defer func() {
+		Audit(p.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"createRSSFeed": feed.Name}, err)
+	}()
+
+	if err := feed.Validate(); err != nil {
+		return &InvalidRSSFeedError{Reason: err.Error()}
+	}
+	if err := p.db.Create(feed).Error; err != nil {
+		return err
+	}
+	p.refresh()
+	return nil
+}
+
+// UpdateFeed 更新订阅源，保留最近一次抓取的状态
+func (p *RSSPoller) UpdateFeed(access *Access, id uint, feed *models.RSSFeed) (err error) {
+	defer func() {
+		Audit(p.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"updateRSSFeed": id, "name": feed.Name}, err)
+	}()
+
+	existing, err := p.GetFeed(id)
+	if err != nil {
+		return err
+	}
+	if err := feed.Validate(); err != nil {
+		return &InvalidRSSFeedError{Reason: err.Error()}
+	}
+
+	feed.Model = existing.Model
+	feed.LastFetchedAt = existing.LastFetchedAt
+	feed.LastError = existing.LastError
+	if err := p.db.Save(feed).Error; err != nil {
+		return err
+	}
+	p.refresh()
+	return nil
+}
+
+// DeleteFeed 删除订阅源及只作用于该订阅源的规则，已处理的条目保留
+func (p *RSSPoller) DeleteFeed(access *Access, id uint) (err error) {
+	defer func() {
+		Audit(p.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"deleteRSSFeed": id}, err)
+	}()
+
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Delete(&models.RSSFeed{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &RSSFeedNotFoundError{ID: id}
+		}
+		return tx.Unscoped().Where("feed_id = ?", id).Delete(&models.RSSRule{}).Error
+	})
+}
+
+// ListItems 获取订阅源最近处理的条目，按时间倒序
+func (p *RSSPoller) ListItems(feedID uint, limit int) ([]models.RSSItem, error) {
+	if _, err := p.GetFeed(feedID); err != nil {
+		return nil, err
+	}
+
+	var items []models.RSSItem
+	err := p.db.Where("feed_id = ?", feedID).Order("id DESC").Limit(limit).Find(&items).Error
+	return items, err
+}
+
+// ListRules 获取所有过滤规则
+func (p *RSSPoller) ListRules() ([]models.RSSRule, error) {
+	var rules []models.RSSRule
+	err := p.db.Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// GetRule 获取单条过滤规则
+func (p *RSSPoller) GetRule(id uint) (*models.RSSRule, error) {
+	var rule models.RSSRule
+	if err := p.db.First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &RSSRuleNotFoundError{ID: id}
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateRule 创建过滤规则，在订阅源下一次抓取时生效
+func (p *RSSPoller) CreateRule(access *Access, rule *models.RSSRule) (err error) {
+	defer func() {
+		Audit(p.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"createRSSRule": rule}, err)
+	}()
+
+	if err := p.validateRule(rule); err != nil {
+		return err
+	}
+	return p.db.Create(rule).Error
+}
+
+// UpdateRule 更新过滤规则
+func (p *RSSPoller) UpdateRule(access *Access, id uint, rule *models.RSSRule) (err error) {
+	defer func() {
+		Audit(p.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"updateRSSRule": id, "rule": rule}, err)
+	}()
+
+	existing, err := p.GetRule(id)
+	if err != nil {
+		return err
+	}
+	if err := p.validateRule(rule); err != nil {
+		return err
+	}
+
+	rule.Model = existing.Model
+	return p.db.Save(rule).Error
+}
+
+// DeleteRule 删除过滤规则
+func (p *RSSPoller) DeleteRule(access *Access, id uint) (err error) {
+	defer func() {
+		Audit(p.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"deleteRSSRule": id}, err)
+	}()
+
+	result := p.db.Unscoped().Delete(&models.RSSRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &RSSRuleNotFoundError{ID: id}
+	}
+	return nil
+}
+
+// validateRule 校验规则字段，以及订阅源、目标客户端与分类是否存在
+func (p *RSSPoller) validateRule(rule *models.RSSRule) error {
+	if err := rule.Validate(); err != nil {
+		return &InvalidRSSRuleError{Reason: err.Error()}
+	}
+	if rule.FeedID != 0 {
+		if _, err := p.GetFeed(rule.FeedID); err != nil {
+			return err
+		}
+	}
+	if rule.ClientID != "" {
+		if _, err := p.service.getClient(rule.ClientID); err != nil {
+			return err
+		}
+	}
+	if rule.Category != "" {
+		if _, err := p.service.GetCategory(rule.Category); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RSSFeedNotFoundError 订阅源不存在
+type RSSFeedNotFoundError struct {
+	ID uint
+}
+
+func (e *RSSFeedNotFoundError) Error() string {
+	return fmt.Sprintf("rss feed not found: %d", e.ID)
+}
+
+// RSSRuleNotFoundError 过滤规则不存在
+type RSSRuleNotFoundError struct {
+	ID uint
+}
+
+func (e *RSSRuleNotFoundError) Error() string {
+	return fmt.Sprintf("rss rule not found: %d", e.ID)
+}
+
+// InvalidRSSFeedError 订阅源配置不合法
+type InvalidRSSFeedError struct {
+	Reason string
+}
+
+func (e *InvalidRSSFeedError) Error() string {
+	return "invalid rss feed: " + e.Reason
+}
+
+// InvalidRSSRuleError 过滤规则不合法
+type InvalidRSSRuleError struct {
+	Reason string
+}
+
+func (e *InvalidRSSRuleError) Error() string {
+	return "invalid rss rule: " + e.Reason
+}
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+const (
+	testMagnetE01    = "magnet:?xt=urn:btih:1111111111111111111111111111111111111111"
+	testMagnetRepack = "magnet:?xt=urn:btih:2222222222222222222222222222222222222222"
+	testMagnetHEVC   = "magnet:?xt=urn:btih:3333333333333333333333333333333333333333"
+	testMagnetS02    = "magnet:?xt=urn:btih:4444444444444444444444444444444444444444"
+	testMagnetLarge  = "magnet:?xt=urn:btih:5555555555555555555555555555555555555555"
+	testMagnetOther  = "magnet:?xt=urn:btih:6666666666666666666666666666666666666666"
+)
+
+// testTorrentFile 只包含 info 字典的最小种子文件
+var testTorrentFile = []byte("d4:infod6:lengthi1e4:name8:show.mkv12:piece lengthi16384eee")
+
+// newTestFeedServer 返回提供订阅与种子文件的测试服务器，订阅内容由 feed 生成
+func newTestFeedServer(t *testing.T, feed func(url string) string) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprint(w, feed(server.URL))
+		case "/show.s01e04.torrent":
+			w.Write(testTorrentFile)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func rssItemXML(guid, title, link string, size int64) string {
+	return fmt.Sprintf(`<item><guid>%s</guid><title>%s</title><enclosure url="%s" length="%d"/></item>`,
+		guid, title, strings.ReplaceAll(link, "&", "&amp;"), size)
+}
+
+func newTestRSSPoller(t *testing.T, feedURL string, rule models.RSSRule) (*RSSPoller, *fakeClient, models.RSSFeed) {
+	t.Helper()
+	db := newTestDB(t)
+	client := &fakeClient{id: "qb-1"}
+	poller := NewRSSPoller(NewTorrentService([]clients.DownloaderClient{client}, db), db, nil, time.Minute)
+
+	feed := models.RSSFeed{Name: "test", URL: feedURL, Enabled: true}
+	if err := poller.CreateFeed(nil, &feed); err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	rule.Name = "rule"
+	rule.FeedID = feed.ID
+	rule.ClientID = client.id
+	rule.Enabled = true
+	if err := poller.CreateRule(nil, &rule); err != nil {
+		t.Fatalf("CreateRule() error = %v", err)
+	}
+	return poller, client, feed
+}
+
+func TestRSSPollerFiltersAndDeduplicates(t *testing.T) {
+	server := newTestFeedServer(t, func(url string) string {
+		return `<rss><channel>` +
+			rssItemXML("e01", "Show S01E01 1080p", testMagnetE01, 1<<30) +
+			rssItemXML("e01-repack", "Show S01E01 1080p REPACK", testMagnetRepack, 1<<30) +
+			rssItemXML("e02-hevc", "Show S01E02 1080p HEVC", testMagnetHEVC, 1<<30) +
+			rssItemXML("s02e01", "Show S02E01 1080p", testMagnetS02, 1<<30) +
+			rssItemXML("e03-large", "Show S01E03 1080p", testMagnetLarge, 8<<30) +
+			rssItemXML("other", "Other S01E05 1080p", testMagnetOther, 1<<30) +
+			rssItemXML("e04", "Show S01E04 1080p", url+"/show.s01e04.torrent", 1<<30) +
+			`</channel></rss>`
+	})
+	poller, client, feed := newTestRSSPoller(t, server.URL+"/feed", models.RSSRule{
+		Include:             "^show\\b",
+		Exclude:             "hevc",
+		MaxSize:             4 << 30,
+		Season:              1,
+		SkipGrabbedEpisodes: true,
+	})
+
+	result, err := poller.FetchFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("FetchFeed() error = %v", err)
+	}
+	if result.Items != 7 {
+		t.Errorf("Items = %d, want 7", result.Items)
+	}
+	var grabbed []string
+	for _, item := range result.Grabbed {
+		if !item.Success {
+			t.Errorf("item %s failed: %s", item.GUID, item.Error)
+		}
+		grabbed = append(grabbed, item.GUID+" "+item.Episode)
+	}
+	if want := []string{"e01 S01E01", "e04 S01E04"}; !reflect.DeepEqual(grabbed, want) {
+		t.Errorf("grabbed = %v, want %v", grabbed, want)
+	}
+
+	fileHash, _ := clients.TorrentFileInfoHash(testTorrentFile)
+	want := []string{"add " + testMagnetE01, "addFile " + fileHash}
+	if calls := client.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Fatalf("client calls = %v, want %v", calls, want)
+	}
+
+	// 同一条目只处理一次
+	result, err = poller.FetchFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("FetchFeed() error = %v", err)
+	}
+	if len(result.Grabbed) != 0 {
+		t.Errorf("second fetch grabbed %v, want none", result.Grabbed)
+	}
+	if calls := client.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Errorf("client calls after second fetch = %v, want %v", calls, want)
+	}
+}
+
+func TestRSSPollerRetriesFailedGrabs(t *testing.T) {
+	server := newTestFeedServer(t, func(string) string {
+		return `<rss><channel>` + rssItemXML("e01", "Show S01E01", testMagnetE01, 0) + `</channel></rss>`
+	})
+	poller, client, feed := newTestRSSPoller(t, server.URL+"/feed", models.RSSRule{})
+	client.failures = 1
+
+	result, err := poller.FetchFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("FetchFeed() error = %v", err)
+	}
+	if len(result.Grabbed) != 1 || result.Grabbed[0].Success || result.Grabbed[0].NextRetryAt == nil {
+		t.Fatalf("grabbed = %+v, want one failed item waiting for retry", result.Grabbed)
+	}
+	retryAt := *result.Grabbed[0].NextRetryAt
+
+	// 失败的条目不会因为再次出现在订阅中而重复处理，只按重试时间重试
+	if _, err := poller.FetchFeed(feed.ID); err != nil {
+		t.Fatalf("FetchFeed() error = %v", err)
+	}
+	poller.retry(retryAt.Add(-time.Second))
+	if calls := client.Calls(); len(calls) != 0 {
+		t.Fatalf("client calls before retry = %v, want none", calls)
+	}
+
+	poller.retry(retryAt)
+	if calls, want := client.Calls(), []string{"add " + testMagnetE01}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("client calls after retry = %v, want %v", calls, want)
+	}
+	items, err := poller.ListItems(feed.ID, 10)
+	if err != nil {
+		t.Fatalf("ListItems() error = %v", err)
+	}
+	if len(items) != 1 || !items[0].Success || items[0].Attempts != 2 || items[0].NextRetryAt != nil {
+		t.Errorf("items = %+v, want one successful item after 2 attempts", items)
+	}
+}
+
+func TestRSSPollerGivesUpAfterMaxAttempts(t *testing.T) {
+	server := newTestFeedServer(t, func(string) string {
+		return `<rss><channel>` + rssItemXML("e01", "Show S01E01", testMagnetE01, 0) + `</channel></rss>`
+	})
+	poller, client, feed := newTestRSSPoller(t, server.URL+"/feed", models.RSSRule{})
+	client.failures = rssMaxAttempts
+
+	if _, err := poller.FetchFeed(feed.ID); err != nil {
+		t.Fatalf("FetchFeed() error = %v", err)
+	}
+	now := time.Now()
+	for i := 1; i < rssMaxAttempts; i++ {
+		now = now.Add(rssRetryDelay << i)
+		poller.retry(now)
+	}
+
+	items, err := poller.ListItems(feed.ID, 10)
+	if err != nil {
+		t.Fatalf("ListItems() error = %v", err)
+	}
+	if len(items) != 1 || items[0].Success || items[0].Attempts != rssMaxAttempts || items[0].NextRetryAt != nil {
+		t.Fatalf("items = %+v, want one item that gave up after %d attempts", items, rssMaxAttempts)
+	}
+	poller.retry(now.Add(24 * time.Hour))
+	if calls := client.Calls(); len(calls) != 0 {
+		t.Errorf("client calls = %v, want none", calls)
+	}
+}
@@ -0,0 +1,308 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+	"gorm.io/gorm"
+)
+
+// schedulerInterval 调度器检查规则边界和客户端状态的周期
+const schedulerInterval = 30 * time.Second
+
+// Scheduler 带宽调度器
+// 按数据库中的每周规则在时间窗口边界为客户端应用全局限速或备用速度，
+// 窗口结束后恢复进入窗口前的限制；客户端断线重连后会重新应用当前规则
+type Scheduler struct {
+	service *TorrentService
+	db      *gorm.DB
+
+	// mutex 保护 states 与 generation
+	mutex  sync.Mutex
+	states map[string]*scheduleState
+	// generation 规则版本，每次规则变化时递增
+	generation uint64
+	trigger    chan struct{}
+}
+
+// scheduleState 记录某个客户端当前的调度状态
+type scheduleState struct {
+	// ruleID 当前生效的规则，0 表示没有规则生效
+	ruleID uint
+	// applied 当前规则是否已成功下发到客户端
+	applied bool
+	// baseline 进入调度窗口前客户端原有的限制，窗口结束后恢复，同时保存在 ScheduleBaseline 中
+	baseline *models.TransferLimits
+	// reachable 上一次探测时客户端是否可达
+	reachable bool
+}
+
+func NewScheduler(service *TorrentService, db *gorm.DB) *Scheduler {
+	return &Scheduler{
+		service: service,
+		db:      db,
+		states:  make(map[string]*scheduleState),
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+// Run 启动调度循环，直到 ctx 被取消
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	s.evaluate(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.trigger:
+		}
+		s.evaluate(time.Now())
+	}
+}
+
+// evaluate 计算每个客户端当前应生效的规则并在需要时下发
+// 只由调度循环调用；访问客户端期间不持有 mutex，规则的增删改不会被网络请求阻塞
+func (s *Scheduler) evaluate(now time.Time) {
+	var rules []models.ScheduleRule
+	if err := s.db.Where("enabled = ?", true).Order("id").Find(&rules).Error; err != nil {
+		log.Printf("⚠️  加载调度规则失败: %v", err)
+		return
+	}
+
+	s.mutex.Lock()
+	generation := s.generation
+	s.mutex.Unlock()
+
+	for _, client := range s.service.clients {
+		s.evaluateClient(client, rules, generation, now)
+	}
+}
+
+// evaluateClient 为单个客户端下发或撤销规则
+// generation 为本轮开始时的规则版本，期间规则发生变化时不将规则标记为已下发，下一轮会重新下发
+func (s *Scheduler) evaluateClient(client clients.DownloaderClient, rules []models.ScheduleRule, generation uint64, now time.Time) {
+	clientID := client.GetClientID()
+	state := s.state(clientID)
+
+	// 探测客户端，不可达时标记为需要重新下发
+	current, err := client.GetTransferLimits()
+	s.mutex.Lock()
+	if err != nil {
+		if state.reachable {
+			log.Printf("⚠️  调度器无法连接客户端 [%s]: %v", clientID, err)
+		}
+		state.reachable = false
+		state.applied = false
+		s.mutex.Unlock()
+		return
+	}
+	state.reachable = true
+	ruleID, applied, baseline := state.ruleID, state.applied, state.baseline
+	s.mutex.Unlock()
+
+	rule := activeRule(rules, clientID, now)
+
+	if rule == nil {
+		if baseline == nil {
+			return
+		}
+		// 窗口结束，恢复原有限制
+		if err := client.SetTransferLimits(*baseline); err != nil {
+			log.Printf("⚠️  恢复客户端 [%s] 限速失败: %v", clientID, err)
+			return
+		}
+		log.Printf("⏰ 客户端 [%s] 调度窗口结束，已恢复原有限速", clientID)
+		if err := s.db.Delete(&models.ScheduleBaseline{}, "client_id = ?", clientID).Error; err != nil {
+			log.Printf("⚠️  删除客户端 [%s] 的原有限速记录失败: %v", clientID, err)
+		}
+		s.mutex.Lock()
+		state.ruleID = 0
+		state.applied = false
+		state.baseline = nil
+		s.mutex.Unlock()
+		return
+	}
+
+	if ruleID == rule.ID && applied {
+		return
+	}
+
+	if baseline == nil {
+		baseline = &current
+		// 先保存原有限制再下发规则，保存失败时仍下发，只是重启后无法恢复
+		record := models.ScheduleBaseline{ClientID: clientID, TransferLimits: current}
+		if err := s.db.Save(&record).Error; err != nil {
+			log.Printf("⚠️  保存客户端 [%s] 的原有限速失败: %v", clientID, err)
+		}
+		s.mutex.Lock()
+		state.baseline = baseline
+		s.mutex.Unlock()
+	}
+
+	// 始终基于原有限制叠加规则，避免上一条规则的设置残留
+	if err := client.SetTransferLimits(rule.Apply(*baseline)); err != nil {
+		log.Printf("⚠️  应用调度规则 %q 到客户端 [%s] 失败: %v", rule.Name, clientID, err)
+		s.mutex.Lock()
+		state.applied = false
+		s.mutex.Unlock()
+		return
+	}
+	log.Printf("⏰ 客户端 [%s] 已应用调度规则 %q", clientID, rule.Name)
+	s.mutex.Lock()
+	state.ruleID = rule.ID
+	state.applied = s.generation == generation
+	s.mutex.Unlock()
+}
+
+// state 返回客户端的调度状态，首次访问时从数据库恢复进入窗口前的限制
+func (s *Scheduler) state(clientID string) *scheduleState {
+	s.mutex.Lock()
+	state, ok := s.states[clientID]
+	s.mutex.Unlock()
+	if ok {
+		return state
+	}
+
+	state = &scheduleState{reachable: true}
+	var record models.ScheduleBaseline
+	err := s.db.Where("client_id = ?", clientID).Limit(1).Find(&record).Error
+	switch {
+	case err != nil:
+		log.Printf("⚠️  加载客户端 [%s] 的原有限速失败: %v", clientID, err)
+	case record.ClientID != "":
+		state.baseline = &record.TransferLimits
+	}
+
+	s.mutex.Lock()
+	s.states[clientID] = state
+	s.mutex.Unlock()
+	return state
+}
+
+// activeRule 返回客户端在给定时间应生效的规则
+// 指定客户端的规则优先于全局规则，同一范围内以后创建的规则为准
+func activeRule(rules []models.ScheduleRule, clientID string, now time.Time) *models.ScheduleRule {
+	var global, specific *models.ScheduleRule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(now) {
+			continue
+		}
+		switch rule.ClientID {
+		case clientID:
+			specific = rule
+		case "":
+			global = rule
+		}
+	}
+	if specific != nil {
+		return specific
+	}
+	return global
+}
+
+// refresh 使所有客户端在下一轮重新下发规则，并立即触发一轮调度
+func (s *Scheduler) refresh() {
+	s.mutex.Lock()
+	s.generation++
+	for _, state := range s.states {
+		state.applied = false
+	}
+	s.mutex.Unlock()
+
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// ListRules 获取所有调度规则
+func (s *Scheduler) ListRules() ([]models.ScheduleRule, error) {
+	var rules []models.ScheduleRule
+	err := s.db.Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// GetRule 获取单条调度规则
+func (s *Scheduler) GetRule(id uint) (*models.ScheduleRule, error) {
+	var rule models.ScheduleRule
+	if err := s.db.First(&rule, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &ScheduleRuleNotFoundError{ID: id}
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateRule 创建调度规则
+func (s *Scheduler) CreateRule(access *Access, rule *models.ScheduleRule) (err error) {
+	defer func() {
+		Audit(s.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"createSchedule": rule}, err)
+	}()
+
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	if err := s.db.Create(rule).Error; err != nil {
+		return err
+	}
+	s.refresh()
+	return nil
+}
+
+// UpdateRule 更新调度规则
+func (s *Scheduler) UpdateRule(access *Access, id uint, rule *models.ScheduleRule) (err error) {
+	defer func() {
+		Audit(s.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"updateSchedule": id, "rule": rule}, err)
+	}()
+
+	existing, err := s.GetRule(id)
+	if err != nil {
+		return err
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	rule.Model = existing.Model
+	if err := s.db.Save(rule).Error; err != nil {
+		return err
+	}
+	s.refresh()
+	return nil
+}
+
+// DeleteRule 删除调度规则
+func (s *Scheduler) DeleteRule(access *Access, id uint) (err error) {
+	defer func() {
+		Audit(s.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"deleteSchedule": id}, err)
+	}()
+
+	result := s.db.Delete(&models.ScheduleRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &ScheduleRuleNotFoundError{ID: id}
+	}
+	s.refresh()
+	return nil
+}
+
+// ScheduleRuleNotFoundError 调度规则不存在
+type ScheduleRuleNotFoundError struct {
+	ID uint
+}
+
+func (e *ScheduleRuleNotFoundError) Error() string {
+	return fmt.Sprintf("schedule rule not found: %d", e.ID)
+}
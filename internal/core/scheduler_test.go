@@ -0,0 +1,171 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+	"gorm.io/gorm"
+)
+
+func TestActiveRule(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local)
+	rules := []models.ScheduleRule{
+		{Model: gorm.Model{ID: 1}, StartTime: "09:00", EndTime: "17:00"},
+		{Model: gorm.Model{ID: 2}, StartTime: "10:00", EndTime: "14:00"},
+		{Model: gorm.Model{ID: 3}, ClientID: "qb-1", StartTime: "11:00", EndTime: "13:00"},
+		{Model: gorm.Model{ID: 4}, ClientID: "qb-1", StartTime: "18:00", EndTime: "20:00"},
+	}
+	tests := []struct {
+		name     string
+		rules    []models.ScheduleRule
+		clientID string
+		want     uint
+	}{
+		{"client rule wins over global rules", rules, "qb-1", 3},
+		{"later global rule wins", rules, "tr-1", 2},
+		{"inactive client rule is ignored", rules[3:], "qb-1", 0},
+		{"no rules", nil, "qb-1", 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got uint
+			if rule := activeRule(test.rules, test.clientID, now); rule != nil {
+				got = rule.ID
+			}
+			if got != test.want {
+				t.Errorf("activeRule() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSchedulerAppliesAndRestoresLimits(t *testing.T) {
+	db := newTestDB(t)
+	client := &fakeClient{id: "qb-1", limits: models.TransferLimits{DownloadLimit: 100, UploadLimit: 200}}
+	scheduler := NewScheduler(NewTorrentService([]clients.DownloaderClient{client}, db), db)
+
+	download, upload := int64(10), int64(20)
+	rules := []models.ScheduleRule{
+		{Name: "daytime", StartTime: "10:00", EndTime: "14:00", DownloadLimit: &download, Enabled: true},
+		{Name: "lunch", StartTime: "12:00", EndTime: "13:00", UploadLimit: &upload, Enabled: true},
+	}
+	if err := db.Create(&rules).Error; err != nil {
+		t.Fatalf("create rules: %v", err)
+	}
+
+	day := func(hour int) time.Time { return time.Date(2026, 10, 19, hour, 0, 0, 0, time.Local) }
+	steps := []struct {
+		time  time.Time
+		calls []string
+	}{
+		{day(9), nil},
+		{day(10), []string{"limits 10 200 false"}},
+		{day(11), nil},
+		// 后一条规则基于原有限制叠加，而不是基于上一条规则的限制
+		{day(12), []string{"limits 100 20 false"}},
+		{day(13), []string{"limits 10 200 false"}},
+		{day(14), []string{"limits 100 200 false"}},
+		{day(15), nil},
+	}
+	for _, step := range steps {
+		client.calls = nil
+		scheduler.evaluate(step.time)
+		if calls := client.Calls(); !reflect.DeepEqual(calls, step.calls) {
+			t.Fatalf("at %s: client calls = %v, want %v", step.time.Format("15:04"), calls, step.calls)
+		}
+	}
+}
+
+func newTestScheduleRule(t *testing.T, scheduler *Scheduler) {
+	t.Helper()
+	download := int64(10)
+	rule := models.ScheduleRule{Name: "daytime", StartTime: "10:00", EndTime: "14:00", DownloadLimit: &download, Enabled: true}
+	if err := scheduler.CreateRule(nil, &rule); err != nil {
+		t.Fatalf("CreateRule() error = %v", err)
+	}
+}
+
+func TestSchedulerRestoresPersistedBaseline(t *testing.T) {
+	db := newTestDB(t)
+	client := &fakeClient{id: "qb-1", limits: models.TransferLimits{DownloadLimit: 100, UploadLimit: 200}}
+	service := NewTorrentService([]clients.DownloaderClient{client}, db)
+	inWindow := time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local)
+
+	scheduler := NewScheduler(service, db)
+	newTestScheduleRule(t, scheduler)
+	scheduler.evaluate(inWindow)
+	if calls, want := client.Calls(), []string{"limits 10 200 false"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("client calls = %v, want %v", calls, want)
+	}
+
+	// 模拟服务在窗口内重启：新的调度器从数据库恢复原有限制，而不是把规则的限制当作原有限制
+	client.calls = nil
+	restarted := NewScheduler(service, db)
+	restarted.evaluate(inWindow)
+	if calls, want := client.Calls(), []string{"limits 10 200 false"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("client calls after restart = %v, want %v", calls, want)
+	}
+
+	client.calls = nil
+	restarted.evaluate(inWindow.Add(3 * time.Hour))
+	if calls, want := client.Calls(), []string{"limits 100 200 false"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("client calls after window = %v, want %v", calls, want)
+	}
+	var count int64
+	db.Model(&models.ScheduleBaseline{}).Count(&count)
+	if count != 0 {
+		t.Errorf("baselines after window = %d, want 0", count)
+	}
+}
+
+// blockingClient 在查询限速时阻塞，直到 release 被关闭
+type blockingClient struct {
+	*fakeClient
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingClient) GetTransferLimits() (models.TransferLimits, error) {
+	close(c.entered)
+	<-c.release
+	return c.fakeClient.GetTransferLimits()
+}
+
+func TestSchedulerDoesNotLockDuringClientCalls(t *testing.T) {
+	db := newTestDB(t)
+	client := &blockingClient{fakeClient: &fakeClient{id: "qb-1"}, entered: make(chan struct{}), release: make(chan struct{})}
+	scheduler := NewScheduler(NewTorrentService([]clients.DownloaderClient{client}, db), db)
+	newTestScheduleRule(t, scheduler)
+
+	done := make(chan struct{})
+	go func() {
+		scheduler.evaluate(time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local))
+		close(done)
+	}()
+	<-client.entered
+
+	// 客户端请求未返回时，规则变化不应被阻塞
+	refreshed := make(chan struct{})
+	go func() {
+		scheduler.refresh()
+		close(refreshed)
+	}()
+	select {
+	case <-refreshed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("refresh() blocked while the scheduler was waiting for the client")
+	}
+	close(client.release)
+	<-done
+
+	// 规则在下发期间发生了变化，下一轮需要重新下发
+	scheduler.mutex.Lock()
+	applied := scheduler.states["qb-1"].applied
+	scheduler.mutex.Unlock()
+	if applied {
+		t.Error("rule marked as applied although the rules changed during evaluation")
+	}
+}
@@ -0,0 +1,283 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// shareLimitInterval 分享目标检查周期
+const shareLimitInterval = time.Minute
+
+// ShareLimitEnforcer 分享目标执行器
+// 将种子级和分类级的分享目标下发到支持原生执行的客户端，
+// 对不支持的客户端在后台周期性检查并执行暂停或删除
+type ShareLimitEnforcer struct {
+	service *TorrentService
+	db      *gorm.DB
+
+	mutex   sync.Mutex
+	pushed  map[string]pushedShareLimits
+	trigger chan struct{}
+}
+
+// pushedShareLimits 记录已下发到客户端的策略版本
+type pushedShareLimits struct {
+	version string
+	native  bool
+}
+
+func NewShareLimitEnforcer(service *TorrentService, db *gorm.DB) *ShareLimitEnforcer {
+	return &ShareLimitEnforcer{
+		service: service,
+		db:      db,
+		pushed:  make(map[string]pushedShareLimits),
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+// Run 启动检查循环，直到 ctx 被取消
+func (e *ShareLimitEnforcer) Run(ctx context.Context) {
+	ticker := time.NewTicker(shareLimitInterval)
+	defer ticker.Stop()
+
+	e.enforce()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-e.trigger:
+		}
+		e.enforce()
+	}
+}
+
+// enforce 下发变更的策略，并对客户端不能原生执行的策略执行动作
+func (e *ShareLimitEnforcer) enforce() {
+	var policies []models.SharePolicy
+	if err := e.db.Find(&policies).Error; err != nil {
+		log.Printf("⚠️  加载分享目标失败: %v", err)
+		return
+	}
+
+	byTorrent := make(map[string]*models.SharePolicy)
+	byCategory := make(map[string]*models.SharePolicy)
+	for i := range policies {
+		policy := &policies[i]
+		if policy.Category != "" {
+			byCategory[policy.Category] = policy
+		} else {
+			byTorrent[torrentKey(policy.ClientID, policy.Hash)] = policy
+		}
+	}
+
+	torrents := e.service.GetAllTorrents()
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	seen := make(map[string]bool, len(torrents))
+	for _, torrent := range torrents {
+		key := torrentKey(torrent.ClientID, torrent.Hash)
+		seen[key] = true
+
+		policy := byTorrent[key]
+		if policy == nil && torrent.Category != "" {
+			policy = byCategory[torrent.Category]
+		}
+
+		client, err := e.service.getClient(torrent.ClientID)
+		if err != nil {
+			continue
+		}
+
+		pushed, ok := e.pushed[key]
+		if policy == nil {
+			// 策略已删除，恢复客户端的全局设置
+			if ok {
+				if _, err := client.SetShareLimits([]string{torrent.Hash}, models.ShareLimits{}); err != nil {
+					log.Printf("⚠️  清除种子 %s [%s] 的分享目标失败: %v", torrent.Name, torrent.ClientID, err)
+					continue
+				}
+				delete(e.pushed, key)
+			}
+			continue
+		}
+
+		version := fmt.Sprintf("%d@%d", policy.ID, policy.UpdatedAt.UnixNano())
+		if !ok || pushed.version != version {
+			native, err := client.SetShareLimits([]string{torrent.Hash}, policy.ShareLimits)
+			if err != nil {
+				log.Printf("⚠️  下发种子 %s [%s] 的分享目标失败: %v", torrent.Name, torrent.ClientID, err)
+				continue
+			}
+			pushed = pushedShareLimits{version: version, native: native}
+			e.pushed[key] = pushed
+		}
+
+		if pushed.native || !policy.Reached(torrent) {
+			continue
+		}
+
+		e.apply(torrent, policy)
+	}
+
+	// 清理已不存在的种子
+	for key := range e.pushed {
+		if !seen[key] {
+			delete(e.pushed, key)
+		}
+	}
+}
+
+// apply 对达到分享目标的种子执行动作，种子删除后一并删除只作用于该种子的策略
+func (e *ShareLimitEnforcer) apply(torrent models.UnifiedTorrent, policy *models.SharePolicy) {
+	action := policy.Action
+	var err error
+	switch action {
+	case models.ShareLimitActionPause:
+		if isPausedState(torrent.State) {
+			return
+		}
+		err = e.service.PauseTorrent(torrent.ClientID, torrent.Hash)
+	case models.ShareLimitActionRemove:
+		err = e.service.DeleteTorrent(torrent.ClientID, torrent.Hash, false)
+	case models.ShareLimitActionRemoveWithData:
+		err = e.service.DeleteTorrent(torrent.ClientID, torrent.Hash, true)
+	default:
+		return
+	}
+
+	if err != nil {
+		log.Printf("⚠️  对种子 %s [%s] 执行分享目标动作 %s 失败: %v", torrent.Name, torrent.ClientID, action, err)
+		return
+	}
+	log.Printf("🎯 种子 %s [%s] 已达到分享目标，执行动作: %s", torrent.Name, torrent.ClientID, action)
+
+	if action != models.ShareLimitActionPause && policy.Category == "" {
+		if err := e.db.Unscoped().Delete(&models.SharePolicy{}, policy.ID).Error; err != nil {
+			log.Printf("⚠️  删除种子 %s [%s] 的分享目标失败: %v", torrent.Name, torrent.ClientID, err)
+		}
+	}
+}
+
+// refresh 立即触发一轮检查
+func (e *ShareLimitEnforcer) refresh() {
+	select {
+	case e.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// ListPolicies 获取所有分享目标
+func (e *ShareLimitEnforcer) ListPolicies() ([]models.SharePolicy, error) {
+	var policies []models.SharePolicy
+	err := e.db.Order("id").Find(&policies).Error
+	return policies, err
+}
+
+// SetTorrentPolicy 设置单个种子的分享目标
+func (e *ShareLimitEnforcer) SetTorrentPolicy(access *Access, clientID, hash string, limits models.ShareLimits) (_ *models.SharePolicy, err error) {
+	defer func() {
+		Audit(e.db, access, models.AuditActionConfig, clientID, hash, map[string]interface{}{"setSharePolicy": limits}, err)
+	}()
+
+	if _, err := e.service.getClient(clientID); err != nil {
+		return nil, err
+	}
+	return e.savePolicy(models.SharePolicy{ClientID: clientID, Hash: hash}, limits)
+}
+
+// SetCategoryPolicy 设置分类的分享目标
+func (e *ShareLimitEnforcer) SetCategoryPolicy(access *Access, category string, limits models.ShareLimits) (_ *models.SharePolicy, err error) {
+	defer func() {
+		Audit(e.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"setSharePolicy": limits, "category": category}, err)
+	}()
+
+	if _, err := e.service.GetCategory(category); err != nil {
+		return nil, err
+	}
+	return e.savePolicy(models.SharePolicy{Category: category}, limits)
+}
+
+// ClearTorrentPolicy 删除单个种子的分享目标
+func (e *ShareLimitEnforcer) ClearTorrentPolicy(access *Access, clientID, hash string) (err error) {
+	defer func() {
+		Audit(e.db, access, models.AuditActionConfig, clientID, hash, map[string]interface{}{"clearSharePolicy": true}, err)
+	}()
+
+	return e.deletePolicy(models.SharePolicy{ClientID: clientID, Hash: hash})
+}
+
+// ClearCategoryPolicy 删除分类的分享目标
+func (e *ShareLimitEnforcer) ClearCategoryPolicy(access *Access, category string) (err error) {
+	defer func() {
+		Audit(e.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"clearSharePolicy": true, "category": category}, err)
+	}()
+
+	return e.deletePolicy(models.SharePolicy{Category: category})
+}
+
+// savePolicy 按作用范围创建或更新分享目标
+func (e *ShareLimitEnforcer) savePolicy(scope models.SharePolicy, limits models.ShareLimits) (*models.SharePolicy, error) {
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+
+	var policy models.SharePolicy
+	err := e.db.Where("client_id = ? AND hash = ? AND category = ?", scope.ClientID, scope.Hash, scope.Category).First(&policy).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	scope.Model = policy.Model
+	scope.ShareLimits = limits
+	if err := e.db.Save(&scope).Error; err != nil {
+		return nil, err
+	}
+
+	e.refresh()
+	return &scope, nil
+}
+
+// deletePolicy 按作用范围删除分享目标
+func (e *ShareLimitEnforcer) deletePolicy(scope models.SharePolicy) error {
+	result := e.db.Unscoped().
+		Where("client_id = ? AND hash = ? AND category = ?", scope.ClientID, scope.Hash, scope.Category).
+		Delete(&models.SharePolicy{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &SharePolicyNotFoundError{}
+	}
+
+	e.refresh()
+	return nil
+}
+
+// torrentKey 生成跨客户端唯一的种子键
+func torrentKey(clientID, hash string) string {
+	return clientID + "/" + strings.ToLower(hash)
+}
+
+// isPausedState 判断客户端返回的状态是否为暂停
+// qBittorrent 为 pausedUP/pausedDL（v5 起为 stoppedUP/stoppedDL），Transmission 为 stopped
+func isPausedState(state string) bool {
+	return strings.HasPrefix(state, "paused") || strings.HasPrefix(state, "stopped")
+}
+
+// SharePolicyNotFoundError 分享目标不存在
+type SharePolicyNotFoundError struct{}
+
+func (e *SharePolicyNotFoundError) Error() string {
+	return "share policy not found"
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestShareLimitEnforcerAppliesPolicies(t *testing.T) {
+	ratio, higherRatio := 1.0, 5.0
+	torrentPolicy := func(limit *float64, action models.ShareLimitAction) models.SharePolicy {
+		return models.SharePolicy{ClientID: "qb-1", Hash: testHashA, ShareLimits: models.ShareLimits{RatioLimit: limit, Action: action}}
+	}
+	categoryPolicy := func(limit *float64, action models.ShareLimitAction) models.SharePolicy {
+		return models.SharePolicy{Category: "tv", ShareLimits: models.ShareLimits{RatioLimit: limit, Action: action}}
+	}
+	tests := []struct {
+		name     string
+		torrent  models.UnifiedTorrent
+		policies []models.SharePolicy
+		native   bool
+		calls    []string
+	}{
+		{
+			name:     "torrent policy reached",
+			torrent:  models.UnifiedTorrent{State: "uploading", Progress: 1, Ratio: 2},
+			policies: []models.SharePolicy{torrentPolicy(&ratio, models.ShareLimitActionPause)},
+			calls:    []string{"pause " + testHashA},
+		},
+		{
+			name:     "torrent policy not reached",
+			torrent:  models.UnifiedTorrent{State: "uploading", Progress: 1, Ratio: 0.5},
+			policies: []models.SharePolicy{torrentPolicy(&ratio, models.ShareLimitActionPause)},
+		},
+		{
+			name:     "already paused",
+			torrent:  models.UnifiedTorrent{State: "pausedUP", Progress: 1, Ratio: 2},
+			policies: []models.SharePolicy{torrentPolicy(&ratio, models.ShareLimitActionPause)},
+		},
+		{
+			name:     "category policy",
+			torrent:  models.UnifiedTorrent{State: "uploading", Category: "tv", Progress: 1, Ratio: 2},
+			policies: []models.SharePolicy{categoryPolicy(&ratio, models.ShareLimitActionRemove)},
+			calls:    []string{"delete " + testHashA},
+		},
+		{
+			name:     "torrent policy overrides category policy",
+			torrent:  models.UnifiedTorrent{State: "uploading", Category: "tv", Progress: 1, Ratio: 2},
+			policies: []models.SharePolicy{categoryPolicy(&ratio, models.ShareLimitActionRemove), torrentPolicy(&higherRatio, models.ShareLimitActionPause)},
+		},
+		{
+			name:     "client enforces the policy itself",
+			torrent:  models.UnifiedTorrent{State: "uploading", Progress: 1, Ratio: 2},
+			policies: []models.SharePolicy{torrentPolicy(&ratio, models.ShareLimitActionPause)},
+			native:   true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := newTestDB(t)
+			torrent := test.torrent
+			torrent.ClientID, torrent.Hash, torrent.Name = "qb-1", testHashA, "seeded"
+			client := &fakeClient{id: "qb-1", torrents: []models.UnifiedTorrent{torrent}, nativeShareLimits: test.native}
+			enforcer := NewShareLimitEnforcer(NewTorrentService([]clients.DownloaderClient{client}, db), db)
+			if err := db.Create(&test.policies).Error; err != nil {
+				t.Fatalf("create policies: %v", err)
+			}
+
+			enforcer.enforce()
+			if calls := client.Calls(); !reflect.DeepEqual(calls, test.calls) {
+				t.Errorf("client calls = %v, want %v", calls, test.calls)
+			}
+		})
+	}
+}
+
+func TestShareLimitEnforcerRemovesTorrentPolicyAfterRemoval(t *testing.T) {
+	ratio := 1.0
+	tests := []struct {
+		name       string
+		category   bool
+		action     models.ShareLimitAction
+		calls      []string
+		policyKept bool
+	}{
+		{"torrent remove", false, models.ShareLimitActionRemove, []string{"delete " + testHashA}, false},
+		{"torrent remove with data", false, models.ShareLimitActionRemoveWithData, []string{"delete " + testHashA}, false},
+		{"torrent pause", false, models.ShareLimitActionPause, []string{"pause " + testHashA}, true},
+		{"category remove", true, models.ShareLimitActionRemove, []string{"delete " + testHashA}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := newTestDB(t)
+			client := &fakeClient{
+				id: "qb-1",
+				torrents: []models.UnifiedTorrent{
+					{ClientID: "qb-1", Name: "seeded", Hash: testHashA, State: "uploading", Category: "tv", Progress: 1, Ratio: 2},
+				},
+			}
+			service := NewTorrentService([]clients.DownloaderClient{client}, db)
+			enforcer := NewShareLimitEnforcer(service, db)
+			limits := models.ShareLimits{RatioLimit: &ratio, Action: test.action}
+
+			var err error
+			if test.category {
+				if err := service.CreateCategory(&models.Category{Name: "tv"}); err != nil {
+					t.Fatalf("CreateCategory() error = %v", err)
+				}
+				_, err = enforcer.SetCategoryPolicy(nil, "tv", limits)
+			} else {
+				_, err = enforcer.SetTorrentPolicy(nil, "qb-1", testHashA, limits)
+			}
+			if err != nil {
+				t.Fatalf("set policy error = %v", err)
+			}
+
+			enforcer.enforce()
+			if calls := client.Calls(); !reflect.DeepEqual(calls, test.calls) {
+				t.Errorf("client calls = %v, want %v", calls, test.calls)
+			}
+			policies, err := enforcer.ListPolicies()
+			if err != nil {
+				t.Fatalf("ListPolicies() error = %v", err)
+			}
+			if kept := len(policies) == 1; kept != test.policyKept {
+				t.Errorf("policies = %+v, want kept = %t", policies, test.policyKept)
+			}
+		})
+	}
+}
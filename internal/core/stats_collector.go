@@ -0,0 +1,216 @@
+package core
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// 统计数据的保留时长
+const (
+	sampleRetention = 48 * time.Hour
+	hourlyRetention = 30 * 24 * time.Hour
+	dailyRetention  = 2 * 365 * 24 * time.Hour
+)
+
+// StatsCollector 传输统计采集器
+// 周期性采样客户端级和种子级的传输计数器，计算增量写入原始采样表，
+// 再汇总为小时和天粒度的统计，并按保留时长清理过期数据
+type StatsCollector struct {
+	service  *TorrentService
+	db       *gorm.DB
+	interval time.Duration
+
+	// last 每个序列最近一次的计数器，键为 clientID/hash，客户端级序列的 hash 为空
+	last   map[string]transferCounter
+	loaded bool
+}
+
+type transferCounter struct {
+	downloaded int64
+	uploaded   int64
+}
+
+func NewStatsCollector(service *TorrentService, db *gorm.DB, interval time.Duration) *StatsCollector {
+	return &StatsCollector{
+		service:  service,
+		db:       db,
+		interval: interval,
+		last:     make(map[string]transferCounter),
+	}
+}
+
+// Run 启动采集循环，直到 ctx 被取消
+func (c *StatsCollector) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.collect(time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// collect 采样一次并更新汇总
+func (c *StatsCollector) collect(now time.Time) {
+	if !c.loaded {
+		if err := c.loadLast(); err != nil {
+			log.Printf("⚠️  加载历史采样失败: %v", err)
+			return
+		}
+		c.loaded = true
+	}
+
+	var samples []models.TransferSample
+	for _, client := range c.service.clients {
+		stats, err := client.GetTransferStats()
+		if err != nil {
+			continue
+		}
+		if sample, ok := c.sample(client.GetClientID(), "", stats.Downloaded, stats.Uploaded, now); ok {
+			samples = append(samples, sample)
+		}
+	}
+	for _, torrent := range c.service.GetAllTorrents() {
+		if sample, ok := c.sample(torrent.ClientID, torrent.Hash, torrent.Downloaded, torrent.Uploaded, now); ok {
+			samples = append(samples, sample)
+		}
+	}
+
+	if len(samples) > 0 {
+		if err := c.db.CreateInBatches(samples, 500).Error; err != nil {
+			log.Printf("⚠️  写入传输采样失败: %v", err)
+			return
+		}
+	}
+
+	if err := c.rollup(now); err != nil {
+		log.Printf("⚠️  汇总传输统计失败: %v", err)
+	}
+	if err := c.prune(now); err != nil {
+		log.Printf("⚠️  清理过期统计失败: %v", err)
+	}
+}
+
+// loadLast 从数据库恢复每个序列最近一次的计数器，使重启前后的增量保持连续
+func (c *StatsCollector) loadLast() error {
+	var samples []models.TransferSample
+	err := c.db.Raw(`SELECT DISTINCT ON (client_id, hash) client_id, hash, downloaded, uploaded
+		FROM transfer_samples ORDER BY client_id, hash, sampled_at DESC`).Scan(&samples).Error
+	if err != nil {
+		return err
+	}
+
+	for _, sample := range samples {
+		c.last[sample.ClientID+"/"+sample.Hash] = transferCounter{
+			downloaded: sample.Downloaded,
+			uploaded:   sample.Uploaded,
+		}
+	}
+	return nil
+}
+
+// sample 计算序列的增量，计数器未变化时不产生采样
+// 序列首次出现时只记录基线，增量为 0
+func (c *StatsCollector) sample(clientID, hash string, downloaded, uploaded int64, now time.Time) (models.TransferSample, bool) {
+	hash = strings.ToLower(hash)
+	key := clientID + "/" + hash
+	previous, ok := c.last[key]
+	c.last[key] = transferCounter{downloaded: downloaded, uploaded: uploaded}
+
+	sample := models.TransferSample{
+		ClientID:   clientID,
+		Hash:       hash,
+		SampledAt:  now,
+		Downloaded: downloaded,
+		Uploaded:   uploaded,
+	}
+	if !ok {
+		return sample, true
+	}
+	if previous.downloaded == downloaded && previous.uploaded == uploaded {
+		return sample, false
+	}
+
+	sample.DownloadedDelta = counterDelta(previous.downloaded, downloaded)
+	sample.UploadedDelta = counterDelta(previous.uploaded, uploaded)
+	return sample, true
+}
+
+// counterDelta 计算计数器增量
+// 计数器变小说明客户端重启或种子被重新添加，此时当前值即为重置后的增量
+func counterDelta(previous, current int64) int64 {
+	if current >= previous {
+		return current - previous
+	}
+	return current
+}
+
+// rollup 将最近的采样重新汇总为小时统计，再将小时统计汇总为天统计
+// 每次都重算当前和上一个区间，结果幂等
+func (c *StatsCollector) rollup(now time.Time) error {
+	err := c.db.Exec(`INSERT INTO transfer_stats (client_id, hash, granularity, bucket_start, downloaded, uploaded)
+		SELECT client_id, hash, ?, date_trunc('hour', sampled_at), SUM(downloaded_delta), SUM(uploaded_delta)
+		FROM transfer_samples
+		WHERE sampled_at >= date_trunc('hour', ?::timestamptz - interval '1 hour')
+		GROUP BY client_id, hash, date_trunc('hour', sampled_at)
+		ON CONFLICT (client_id, hash, granularity, bucket_start)
+		DO UPDATE SET downloaded = EXCLUDED.downloaded, uploaded = EXCLUDED.uploaded`,
+		models.StatGranularityHour, now).Error
+	if err != nil {
+		return err
+	}
+
+	return c.db.Exec(`INSERT INTO transfer_stats (client_id, hash, granularity, bucket_start, downloaded, uploaded)
+		SELECT client_id, hash, ?, date_trunc('day', bucket_start), SUM(downloaded), SUM(uploaded)
+		FROM transfer_stats
+		WHERE granularity = ? AND bucket_start >= date_trunc('day', ?::timestamptz - interval '1 day')
+		GROUP BY client_id, hash, date_trunc('day', bucket_start)
+		ON CONFLICT (client_id, hash, granularity, bucket_start)
+		DO UPDATE SET downloaded = EXCLUDED.downloaded, uploaded = EXCLUDED.uploaded`,
+		models.StatGranularityDay, models.StatGranularityHour, now).Error
+}
+
+// prune 清理超过保留时长的采样与统计
+func (c *StatsCollector) prune(now time.Time) error {
+	if err := c.db.Where("sampled_at < ?", now.Add(-sampleRetention)).Delete(&models.TransferSample{}).Error; err != nil {
+		return err
+	}
+	if err := c.db.Where("granularity = ? AND bucket_start < ?", models.StatGranularityHour, now.Add(-hourlyRetention)).
+		Delete(&models.TransferStat{}).Error; err != nil {
+		return err
+	}
+	return c.db.Where("granularity = ? AND bucket_start < ?", models.StatGranularityDay, now.Add(-dailyRetention)).
+		Delete(&models.TransferStat{}).Error
+}
+
+// StatsHistoryQuery 历史统计查询条件
+// Hash 为空时返回客户端级统计，ClientID 为空时返回所有客户端
+type StatsHistoryQuery struct {
+	From        time.Time
+	To          time.Time
+	Granularity string
+	ClientID    string
+	Hash        string
+}
+
+// History 查询历史统计
+func (c *StatsCollector) History(query StatsHistoryQuery) ([]models.TransferStat, error) {
+	db := c.db.Where("granularity = ? AND bucket_start >= ? AND bucket_start < ? AND hash = ?",
+		query.Granularity, query.From, query.To, strings.ToLower(query.Hash))
+	if query.ClientID != "" {
+		db = db.Where("client_id = ?", query.ClientID)
+	}
+
+	var stats []models.TransferStat
+	err := db.Order("bucket_start, client_id").Find(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCounterDelta(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous int64
+		current  int64
+		want     int64
+	}{
+		{"unchanged", 100, 100, 0},
+		{"increased", 100, 250, 150},
+		{"reset", 500, 120, 120},
+		{"reset to zero", 500, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := counterDelta(test.previous, test.current); got != test.want {
+				t.Errorf("counterDelta(%d, %d) = %d, want %d", test.previous, test.current, got, test.want)
+			}
+		})
+	}
+}
+
+func TestStatsCollectorSample(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	collector := NewStatsCollector(nil, nil, time.Minute)
+
+	// 依次对同一序列采样
+	tests := []struct {
+		name           string
+		downloaded     int64
+		uploaded       int64
+		wantOK         bool
+		wantDownloaded int64
+		wantUploaded   int64
+	}{
+		{"first sample records the baseline", 1000, 500, true, 0, 0},
+		{"unchanged counters produce no sample", 1000, 500, false, 0, 0},
+		{"deltas since the previous sample", 1600, 700, true, 600, 200},
+		{"counter reset", 100, 800, true, 100, 100},
+	}
+	for _, test := range tests {
+		sample, ok := collector.sample("qb-1", "ABC", test.downloaded, test.uploaded, now)
+		if ok != test.wantOK {
+			t.Fatalf("%s: ok = %t, want %t", test.name, ok, test.wantOK)
+		}
+		if !ok {
+			continue
+		}
+		if sample.Hash != "abc" || sample.DownloadedDelta != test.wantDownloaded || sample.UploadedDelta != test.wantUploaded {
+			t.Errorf("%s: sample = %+v, want hash abc and deltas %d/%d", test.name, sample, test.wantDownloaded, test.wantUploaded)
+		}
+	}
+}
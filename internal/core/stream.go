@@ -0,0 +1,269 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"down-nexus-api/internal/models"
+)
+
+const (
+	// streamInterval 共享轮询器拉取种子列表的周期
+	streamInterval = time.Second
+	// subscriptionBuffer 每个订阅者的消息缓冲区大小
+	subscriptionBuffer = 16
+	// maxDroppedUpdates 连续丢弃这么多次更新后断开慢速订阅者
+	maxDroppedUpdates = 30
+)
+
+// Subscription 种子更新订阅
+// 消费者从 Updates 读取消息，Updates 被关闭表示订阅已结束
+type Subscription struct {
+	Updates <-chan models.TorrentUpdate
+
+	updates chan models.TorrentUpdate
+	filter  models.TorrentFilter
+	// resync 为 true 时表示有更新被丢弃，下一次需要发送全量快照
+	resync  bool
+	dropped int
+}
+
+// torrentStream 所有订阅者共享的种子轮询器
+// 仅在存在订阅者时运行，每轮计算与上一轮的差异并按订阅者的过滤条件分发
+type torrentStream struct {
+	service *TorrentService
+
+	mutex       sync.Mutex
+	subscribers map[*Subscription]struct{}
+	current     map[string]models.UnifiedTorrent
+	stop        chan struct{}
+}
+
+func newTorrentStream(service *TorrentService) *torrentStream {
+	return &torrentStream{
+		service:     service,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe 订阅种子更新，首条消息为满足过滤条件的全量快照
+// 受限用户的订阅只包含可见客户端，请求不可见的客户端时返回 PermissionDeniedError
+func (ts *TorrentService) Subscribe(filter models.TorrentFilter) (*Subscription, error) {
+	if !ts.access.IsAdmin() {
+		for _, clientID := range filter.ClientIDs {
+			if err := ts.authorize(clientID, models.ActionView); err != nil {
+				return nil, err
+			}
+		}
+		if len(filter.ClientIDs) == 0 {
+			filter.ClientIDs = ts.visibleClients()
+			if len(filter.ClientIDs) == 0 {
+				return nil, &PermissionDeniedError{Action: models.ActionView}
+			}
+		}
+	}
+	return ts.stream.subscribe(filter), nil
+}
+
+// Unsubscribe 取消订阅
+func (ts *TorrentService) Unsubscribe(sub *Subscription) {
+	ts.stream.unsubscribe(sub)
+}
+
+func (s *torrentStream) subscribe(filter models.TorrentFilter) *Subscription {
+	updates := make(chan models.TorrentUpdate, subscriptionBuffer)
+	sub := &Subscription{
+		Updates: updates,
+		updates: updates,
+		filter:  filter,
+		resync:  true,
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.subscribers[sub] = struct{}{}
+	if s.stop == nil {
+		// 第一个订阅者，启动轮询器
+		s.stop = make(chan struct{})
+		go s.run(s.stop)
+	} else if s.current != nil {
+		s.send(sub, s.current, time.Now())
+	}
+
+	return sub
+}
+
+func (s *torrentStream) unsubscribe(sub *Subscription) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.remove(sub)
+}
+
+// remove 移除订阅者，最后一个订阅者离开时停止轮询器，调用方需持有锁
+func (s *torrentStream) remove(sub *Subscription) {
+	if _, ok := s.subscribers[sub]; !ok {
+		return
+	}
+	delete(s.subscribers, sub)
+	close(sub.updates)
+
+	if len(s.subscribers) == 0 && s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+		s.current = nil
+	}
+}
+
+// run 轮询循环
+func (s *torrentStream) run(stop chan struct{}) {
+	ticker := time.NewTicker(streamInterval)
+	defer ticker.Stop()
+
+	for {
+		s.poll(stop)
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll 拉取一次种子列表并分发给所有订阅者
+func (s *torrentStream) poll(stop chan struct{}) {
+	torrents := s.service.GetAllTorrents()
+	next := make(map[string]models.UnifiedTorrent, len(torrents))
+	for _, torrent := range torrents {
+		next[torrentKey(torrent.ClientID, torrent.Hash)] = torrent
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// 轮询期间所有订阅者已离开
+	select {
+	case <-stop:
+		return
+	default:
+	}
+
+	previous := s.current
+	s.current = next
+	now := time.Now()
+
+	for sub := range s.subscribers {
+		if previous == nil || sub.resync {
+			s.send(sub, next, now)
+			continue
+		}
+
+		update := diffTorrents(previous, next, sub.filter)
+		if update.IsEmpty() {
+			continue
+		}
+		update.Timestamp = now
+		s.deliver(sub, update)
+	}
+}
+
+// send 向订阅者发送全量快照
+func (s *torrentStream) send(sub *Subscription, torrents map[string]models.UnifiedTorrent, now time.Time) {
+	update := newTorrentUpdate(models.TorrentUpdateSnapshot)
+	update.Timestamp = now
+	for _, torrent := range torrents {
+		if sub.filter.Match(torrent) {
+			update.Added = append(update.Added, torrent)
+		}
+	}
+
+	if s.deliver(sub, update) {
+		sub.resync = false
+	}
+}
+
+// deliver 非阻塞地投递消息
+// 缓冲区已满时丢弃本次更新并要求下次发送快照，连续丢弃过多时断开订阅者
+func (s *torrentStream) deliver(sub *Subscription, update models.TorrentUpdate) bool {
+	select {
+	case sub.updates <- update:
+		sub.dropped = 0
+		return true
+	default:
+		sub.resync = true
+		sub.dropped++
+		if sub.dropped >= maxDroppedUpdates {
+			s.remove(sub)
+		}
+		return false
+	}
+}
+
+// diffTorrents 计算两次快照中满足过滤条件的种子之间的差异
+// 因过滤条件相关字段变化而进入或离开过滤范围的种子视为新增或删除
+func diffTorrents(previous, next map[string]models.UnifiedTorrent, filter models.TorrentFilter) models.TorrentUpdate {
+	update := newTorrentUpdate(models.TorrentUpdateDiff)
+
+	for key, torrent := range next {
+		if !filter.Match(torrent) {
+			continue
+		}
+		old, ok := previous[key]
+		if !ok || !filter.Match(old) {
+			update.Added = append(update.Added, torrent)
+			continue
+		}
+		if fields := changedFields(old, torrent); len(fields) > 0 {
+			update.Changed = append(update.Changed, models.TorrentChange{
+				ClientID: torrent.ClientID,
+				Hash:     torrent.Hash,
+				Fields:   fields,
+			})
+		}
+	}
+
+	for key, old := range previous {
+		if !filter.Match(old) {
+			continue
+		}
+		if torrent, ok := next[key]; !ok || !filter.Match(torrent) {
+			update.Removed = append(update.Removed, models.TorrentRef{
+				ClientID: old.ClientID,
+				Hash:     old.Hash,
+			})
+		}
+	}
+
+	return update
+}
+
+func newTorrentUpdate(updateType string) models.TorrentUpdate {
+	return models.TorrentUpdate{
+		Type:    updateType,
+		Added:   []models.UnifiedTorrent{},
+		Removed: []models.TorrentRef{},
+		Changed: []models.TorrentChange{},
+	}
+}
+
+// changedFields 比较两个种子，返回以 JSON 字段名为键的新值
+func changedFields(old, current models.UnifiedTorrent) map[string]interface{} {
+	fields := make(map[string]interface{})
+	oldValue := reflect.ValueOf(old)
+	currentValue := reflect.ValueOf(current)
+	torrentType := oldValue.Type()
+
+	for i := 0; i < torrentType.NumField(); i++ {
+		name := strings.Split(torrentType.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), currentValue.Field(i).Interface()) {
+			fields[name] = currentValue.Field(i).Interface()
+		}
+	}
+
+	return fields
+}
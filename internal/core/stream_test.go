@@ -0,0 +1,96 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"down-nexus-api/internal/models"
+)
+
+func TestDiffTorrents(t *testing.T) {
+	downloading := models.UnifiedTorrent{ClientID: "qb-1", Hash: testHashA, State: "downloading", Progress: 0.5, Category: "tv"}
+	progressed := downloading
+	progressed.Progress = 0.75
+	seeding := downloading
+	seeding.State = "seeding"
+	other := models.UnifiedTorrent{ClientID: "tr-1", Hash: testHashB, State: "downloading", Category: "movies"}
+	snapshot := func(torrents ...models.UnifiedTorrent) map[string]models.UnifiedTorrent {
+		result := make(map[string]models.UnifiedTorrent)
+		for _, torrent := range torrents {
+			result[torrent.ClientID+"/"+torrent.Hash] = torrent
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		previous map[string]models.UnifiedTorrent
+		next     map[string]models.UnifiedTorrent
+		filter   models.TorrentFilter
+		added    []models.UnifiedTorrent
+		removed  []models.TorrentRef
+		changed  []models.TorrentChange
+	}{
+		{
+			name:     "no changes",
+			previous: snapshot(downloading, other),
+			next:     snapshot(downloading, other),
+		},
+		{
+			name:     "added",
+			previous: snapshot(downloading),
+			next:     snapshot(downloading, other),
+			added:    []models.UnifiedTorrent{other},
+		},
+		{
+			name:     "removed",
+			previous: snapshot(downloading, other),
+			next:     snapshot(downloading),
+			removed:  []models.TorrentRef{{ClientID: "tr-1", Hash: testHashB}},
+		},
+		{
+			name:     "changed fields only",
+			previous: snapshot(downloading),
+			next:     snapshot(progressed),
+			changed:  []models.TorrentChange{{ClientID: "qb-1", Hash: testHashA, Fields: map[string]interface{}{"progress": 0.75}}},
+		},
+		{
+			name:     "torrents outside the filter are ignored",
+			previous: snapshot(downloading),
+			next:     snapshot(progressed, other),
+			filter:   models.TorrentFilter{ClientIDs: []string{"tr-1"}},
+			added:    []models.UnifiedTorrent{other},
+		},
+		{
+			name:     "leaving the filter is a removal",
+			previous: snapshot(downloading),
+			next:     snapshot(seeding),
+			filter:   models.TorrentFilter{State: "downloading"},
+			removed:  []models.TorrentRef{{ClientID: "qb-1", Hash: testHashA}},
+		},
+		{
+			name:     "entering the filter is an addition",
+			previous: snapshot(downloading),
+			next:     snapshot(seeding),
+			filter:   models.TorrentFilter{State: "seeding"},
+			added:    []models.UnifiedTorrent{seeding},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			update := diffTorrents(test.previous, test.next, test.filter)
+			if update.Type != models.TorrentUpdateDiff {
+				t.Errorf("Type = %q, want %q", update.Type, models.TorrentUpdateDiff)
+			}
+			if len(update.Added) != len(test.added) || len(test.added) > 0 && !reflect.DeepEqual(update.Added, test.added) {
+				t.Errorf("Added = %+v, want %+v", update.Added, test.added)
+			}
+			if len(update.Removed) != len(test.removed) || len(test.removed) > 0 && !reflect.DeepEqual(update.Removed, test.removed) {
+				t.Errorf("Removed = %+v, want %+v", update.Removed, test.removed)
+			}
+			if len(update.Changed) != len(test.changed) || len(test.changed) > 0 && !reflect.DeepEqual(update.Changed, test.changed) {
+				t.Errorf("Changed = %+v, want %+v", update.Changed, test.changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"sync"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+// GetSummary 汇总每个客户端以及全部客户端的速度、状态分布、容量与分享率
+// 种子数据来自种子列表，速度、剩余空间与备用速度状态并发查询各客户端
+// 全局合计不包含剩余空间与备用速度状态，多个客户端可能共用同一块磁盘
+// 只统计当前权限可见的客户端
+func (ts *TorrentService) GetSummary() models.StatsSummary {
+	torrents := ts.GetAllTorrents()
+
+	var visible []clients.DownloaderClient
+	for _, client := range ts.clients {
+		if ts.access.Can(client.GetClientID(), models.ActionView) {
+			visible = append(visible, client)
+		}
+	}
+
+	summaries := make([]models.ClientSummary, len(visible))
+	index := make(map[string]int, len(visible))
+	var wg sync.WaitGroup
+	for i, client := range visible {
+		index[client.GetClientID()] = i
+		summaries[i] = models.ClientSummary{
+			ClientID:    client.GetClientID(),
+			StateCounts: make(map[string]int),
+		}
+
+		wg.Add(1)
+		go func(c clients.DownloaderClient, summary *models.ClientSummary) {
+			defer wg.Done()
+			collectClientStatus(c, summary)
+		}(client, &summaries[i])
+	}
+	wg.Wait()
+
+	total := models.ClientSummary{StateCounts: make(map[string]int)}
+	for _, torrent := range torrents {
+		i, ok := index[torrent.ClientID]
+		if !ok {
+			continue
+		}
+		addTorrent(&summaries[i], torrent)
+		addTorrent(&total, torrent)
+	}
+
+	for i := range summaries {
+		summaries[i].Ratio = shareRatio(summaries[i].Uploaded, summaries[i].Downloaded)
+		total.DownloadSpeed += summaries[i].DownloadSpeed
+		total.UploadSpeed += summaries[i].UploadSpeed
+	}
+	total.Ratio = shareRatio(total.Uploaded, total.Downloaded)
+
+	return models.StatsSummary{
+		Total:   total,
+		Clients: summaries,
+	}
+}
+
+// collectClientStatus 查询客户端的速度、剩余空间与备用速度状态，失败的项记录到 Errors
+func collectClientStatus(client clients.DownloaderClient, summary *models.ClientSummary) {
+	if stats, err := client.GetTransferStats(); err != nil {
+		summary.Errors = append(summary.Errors, "transfer stats: "+err.Error())
+	} else {
+		summary.DownloadSpeed = stats.DownloadSpeed
+		summary.UploadSpeed = stats.UploadSpeed
+	}
+
+	if space, err := client.GetFreeSpace(); err != nil {
+		summary.Errors = append(summary.Errors, "free space: "+err.Error())
+	} else {
+		summary.FreeSpace = &space
+	}
+
+	if limits, err := client.GetTransferLimits(); err != nil {
+		summary.Errors = append(summary.Errors, "transfer limits: "+err.Error())
+	} else {
+		summary.AltSpeedEnabled = &limits.AltSpeedEnabled
+	}
+}
+
+func addTorrent(summary *models.ClientSummary, torrent models.UnifiedTorrent) {
+	summary.TorrentCount++
+	summary.StateCounts[models.NormalizeState(torrent.State)]++
+	summary.TotalSize += torrent.Size
+	summary.Downloaded += torrent.Downloaded
+	summary.Uploaded += torrent.Uploaded
+}
+
+// shareRatio 计算分享率，尚未下载时为 0
+func shareRatio(uploaded, downloaded int64) float64 {
+	if downloaded == 0 {
+		return 0
+	}
+	return float64(uploaded) / float64(downloaded)
+}
@@ -0,0 +1,21 @@
+package core
+
+import "testing"
+
+func TestShareRatio(t *testing.T) {
+	tests := []struct {
+		uploaded   int64
+		downloaded int64
+		want       float64
+	}{
+		{0, 0, 0},
+		{100, 0, 0},
+		{0, 100, 0},
+		{150, 100, 1.5},
+	}
+	for _, test := range tests {
+		if got := shareRatio(test.uploaded, test.downloaded); got != test.want {
+			t.Errorf("shareRatio(%d, %d) = %v, want %v", test.uploaded, test.downloaded, got, test.want)
+		}
+	}
+}
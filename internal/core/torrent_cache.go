@@ -0,0 +1,180 @@
+package core
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+// fullSyncInterval 增量同步之外定期进行全量同步的周期，用于纠正可能的偏差
+const fullSyncInterval = 5 * time.Minute
+
+// torrentCache 种子列表的内存快照
+// 每个客户端一个后台同步循环，API 读取直接返回快照，变更操作后立即失效并触发同步
+type torrentCache struct {
+	interval time.Duration
+
+	mutex   sync.RWMutex
+	entries map[string]*clientCache
+}
+
+// clientCache 单个客户端的快照与同步状态
+type clientCache struct {
+	client   clients.DownloaderClient
+	torrents map[string]models.UnifiedTorrent
+	// updatedAt 最近一次成功同步的时间
+	updatedAt time.Time
+	lastFull  time.Time
+	err       error
+	needFull  bool
+	trigger   chan struct{}
+}
+
+func newTorrentCache(clientList []clients.DownloaderClient, interval time.Duration) *torrentCache {
+	cache := &torrentCache{
+		interval: interval,
+		entries:  make(map[string]*clientCache, len(clientList)),
+	}
+	for _, client := range clientList {
+		cache.entries[client.GetClientID()] = &clientCache{
+			client:   client,
+			torrents: make(map[string]models.UnifiedTorrent),
+			needFull: true,
+			trigger:  make(chan struct{}, 1),
+		}
+	}
+	return cache
+}
+
+// StartSync 启动后台同步，此后 GetAllTorrents 从内存快照读取
+// recently-active 只覆盖最近 60 秒的活动，因此间隔不小于 1 分钟时每次都做全量同步
+func (ts *TorrentService) StartSync(ctx context.Context, interval time.Duration) {
+	cache := newTorrentCache(ts.clients, interval)
+	for _, entry := range cache.entries {
+		go cache.run(ctx, entry)
+	}
+	ts.cache = cache
+}
+
+// run 单个客户端的同步循环
+func (c *torrentCache) run(ctx context.Context, entry *clientCache) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.refresh(entry)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-entry.trigger:
+		}
+	}
+}
+
+// refresh 同步一次客户端的种子列表
+func (c *torrentCache) refresh(entry *clientCache) {
+	c.mutex.RLock()
+	full := entry.needFull || time.Since(entry.lastFull) >= fullSyncInterval || c.interval >= time.Minute
+	c.mutex.RUnlock()
+
+	result, err := entry.client.SyncTorrents(full)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	clientID := entry.client.GetClientID()
+	if err != nil {
+		if entry.err == nil {
+			log.Printf("⚠️  同步客户端 [%s] 失败: %v", clientID, err)
+		}
+		entry.err = err
+		entry.needFull = true
+		return
+	}
+
+	now := time.Now()
+	if result.Full {
+		entry.torrents = make(map[string]models.UnifiedTorrent, len(result.Torrents))
+		entry.lastFull = now
+	}
+	for _, torrent := range result.Torrents {
+		entry.torrents[strings.ToLower(torrent.Hash)] = torrent
+	}
+	for _, hash := range result.Removed {
+		delete(entry.torrents, strings.ToLower(hash))
+	}
+
+	if entry.err != nil {
+		log.Printf("✨ 客户端 [%s] 同步已恢复", clientID)
+	}
+	entry.err = nil
+	entry.needFull = false
+	entry.updatedAt = now
+}
+
+// snapshot 返回所有客户端的种子及其新鲜度
+func (c *torrentCache) snapshot() ([]models.UnifiedTorrent, []models.ClientFreshness) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	var torrents []models.UnifiedTorrent
+	freshness := make([]models.ClientFreshness, 0, len(c.entries))
+	for clientID, entry := range c.entries {
+		for _, torrent := range entry.torrents {
+			torrents = append(torrents, torrent)
+		}
+
+		status := models.ClientFreshness{ClientID: clientID}
+		if !entry.updatedAt.IsZero() {
+			updatedAt := entry.updatedAt
+			status.UpdatedAt = &updatedAt
+		}
+		if entry.err != nil {
+			status.Error = entry.err.Error()
+		}
+		freshness = append(freshness, status)
+	}
+
+	return torrents, freshness
+}
+
+// locate 返回各客户端快照中指定 info-hash 的种子
+// 快照按小写哈希索引，查找只需检查每个客户端一次
+func (c *torrentCache) locate(hash string) []models.UnifiedTorrent {
+	hash = strings.ToLower(hash)
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	var copies []models.UnifiedTorrent
+	for _, entry := range c.entries {
+		if torrent, ok := entry.torrents[hash]; ok {
+			copies = append(copies, torrent)
+		}
+	}
+	return copies
+}
+
+// invalidate 使客户端的快照失效并立即触发同步
+// removed 为 true 时直接从快照中移除对应种子，hash 为空表示无法确定具体种子
+func (c *torrentCache) invalidate(clientID, hash string, removed bool) {
+	c.mutex.Lock()
+	entry, ok := c.entries[clientID]
+	if ok && removed && hash != "" {
+		delete(entry.torrents, strings.ToLower(hash))
+	}
+	c.mutex.Unlock()
+
+	if !ok {
+		return
+	}
+	select {
+	case entry.trigger <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestTorrentCacheRefresh(t *testing.T) {
+	torrentA := models.UnifiedTorrent{ClientID: "qb-1", Hash: testHashA, Progress: 0.5}
+	torrentB := models.UnifiedTorrent{ClientID: "qb-1", Hash: testHashB}
+	updatedA := torrentA
+	updatedA.Progress = 1
+
+	// 每一步执行一次 refresh，检查请求的同步类型和同步后的快照
+	type step struct {
+		sync       models.TorrentSync
+		fail       bool
+		wantCall   string
+		wantHashes []string
+		wantError  bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "incremental updates and removals",
+			steps: []step{
+				{sync: models.TorrentSync{Full: true, Torrents: []models.UnifiedTorrent{torrentA, torrentB}}, wantCall: "sync true", wantHashes: []string{testHashA, testHashB}},
+				{sync: models.TorrentSync{Torrents: []models.UnifiedTorrent{updatedA}}, wantCall: "sync false", wantHashes: []string{testHashA, testHashB}},
+				{sync: models.TorrentSync{Removed: []string{testHashB}}, wantCall: "sync false", wantHashes: []string{testHashA}},
+			},
+		},
+		{
+			name: "full sync replaces the snapshot",
+			steps: []step{
+				{sync: models.TorrentSync{Full: true, Torrents: []models.UnifiedTorrent{torrentA, torrentB}}, wantCall: "sync true", wantHashes: []string{testHashA, testHashB}},
+				{sync: models.TorrentSync{Full: true, Torrents: []models.UnifiedTorrent{torrentB}}, wantCall: "sync false", wantHashes: []string{testHashB}},
+			},
+		},
+		{
+			name: "failure keeps stale data and forces a full sync",
+			steps: []step{
+				{sync: models.TorrentSync{Full: true, Torrents: []models.UnifiedTorrent{torrentA}}, wantCall: "sync true", wantHashes: []string{testHashA}},
+				{fail: true, wantHashes: []string{testHashA}, wantError: true},
+				{sync: models.TorrentSync{Full: true, Torrents: []models.UnifiedTorrent{torrentB}}, wantCall: "sync true", wantHashes: []string{testHashB}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &fakeClient{id: "qb-1"}
+			cache := newTorrentCache([]clients.DownloaderClient{client}, time.Second)
+			entry := cache.entries["qb-1"]
+
+			for i, step := range test.steps {
+				client.mutex.Lock()
+				client.calls = nil
+				client.syncs = []models.TorrentSync{step.sync}
+				if step.fail {
+					client.failures = 1
+				}
+				client.mutex.Unlock()
+
+				cache.refresh(entry)
+
+				var wantCalls []string
+				if step.wantCall != "" {
+					wantCalls = []string{step.wantCall}
+				}
+				if calls := client.Calls(); !reflect.DeepEqual(calls, wantCalls) {
+					t.Errorf("step %d: calls = %v, want %v", i, calls, wantCalls)
+				}
+				torrents, freshness := cache.snapshot()
+				var hashes []string
+				for _, torrent := range torrents {
+					hashes = append(hashes, torrent.Hash)
+				}
+				sort.Strings(hashes)
+				if !reflect.DeepEqual(hashes, step.wantHashes) {
+					t.Errorf("step %d: hashes = %v, want %v", i, hashes, step.wantHashes)
+				}
+				if len(freshness) != 1 || freshness[0].UpdatedAt == nil || (freshness[0].Error != "") != step.wantError {
+					t.Errorf("step %d: freshness = %+v, want error %t", i, freshness, step.wantError)
+				}
+			}
+		})
+	}
+}
+
+func TestTorrentCacheInvalidate(t *testing.T) {
+	client := &fakeClient{id: "qb-1"}
+	cache := newTorrentCache([]clients.DownloaderClient{client}, time.Second)
+	entry := cache.entries["qb-1"]
+	client.syncs = []models.TorrentSync{{Full: true, Torrents: []models.UnifiedTorrent{{ClientID: "qb-1", Hash: testHashA}}}}
+	cache.refresh(entry)
+
+	cache.invalidate("qb-1", testHashA, true)
+	if torrents, _ := cache.snapshot(); len(torrents) != 0 {
+		t.Errorf("snapshot after removal = %+v, want empty", torrents)
+	}
+	select {
+	case <-entry.trigger:
+	default:
+		t.Error("invalidate did not trigger a sync")
+	}
+	cache.invalidate("unknown", testHashA, true)
+}
@@ -1,7 +1,10 @@
 package core
 
 import (
+	"log"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"down-nexus-api/internal/models"
 	"down-nexus-api/pkg/clients"
 	"gorm.io/gorm"
@@ -10,22 +13,53 @@ import (
 type TorrentService struct {
 	clients []clients.DownloaderClient
 	db      *gorm.DB
+	stream  *torrentStream
+	cache   *torrentCache
+	owners  *ownershipIndex
+	// access 当前视图的访问权限，nil 表示不受限制
+	access *Access
+	// restrictOwnership 为 true 时非管理员只能管理自己添加的种子
+	restrictOwnership bool
+	// rotation 轮询选择目标客户端的计数器，所有权限视图共享
+	rotation *atomic.Uint64
+	// duplicatePolicy 添加已存在于其他客户端的种子时的处理方式
+	duplicatePolicy models.DuplicatePolicy
+	// migrations 迁移任务记录，所有权限视图共享
+	migrations *migrationRegistry
+	// crossSeedDir 辅种扫描的 .torrent 文件目录，为空表示未启用
+	crossSeedDir string
 }
 
 func NewTorrentService(clients []clients.DownloaderClient, db *gorm.DB) *TorrentService {
-	return &TorrentService{
-		clients: clients,
-		db:      db,
+	ts := &TorrentService{
+		clients:    clients,
+		db:         db,
+		owners:     newOwnershipIndex(),
+		rotation:   &atomic.Uint64{},
+		migrations: newMigrationRegistry(),
 	}
+	ts.stream = newTorrentStream(ts)
+	return ts
 }
 
 func (ts *TorrentService) GetAllTorrents() []models.UnifiedTorrent {
+	// 已启动后台同步时直接读取内存快照
+	if ts.cache != nil {
+		torrents, _ := ts.cache.snapshot()
+		torrents = ts.filterTorrents(torrents)
+		ts.annotateOwners(torrents)
+		return torrents
+	}
+
 	var allTorrents []models.UnifiedTorrent
 	var mutex sync.Mutex
 	var wg sync.WaitGroup
 
 	// 并发调用每个下载器的 GetTorrents 方法
 	for _, client := range ts.clients {
+		if !ts.access.Can(client.GetClientID(), models.ActionView) {
+			continue
+		}
 		wg.Add(1)
 		go func(c clients.DownloaderClient) {
 			defer wg.Done()
@@ -47,67 +81,227 @@ func (ts *TorrentService) GetAllTorrents() []models.UnifiedTorrent {
 	// 等待所有 goroutine 完成
 	wg.Wait()
 
+	ts.annotateOwners(allTorrents)
 	return allTorrents
 }
 
-func (ts *TorrentService) AddTorrent(magnetURL string, clientID string) error {
-	// 遍历所有客户端，找到匹配的 clientID
-	for _, client := range ts.clients {
-		// 由于我们需要检查 clientID，我们需要先获取种子来确定客户端ID
-		// 但更好的方法是在客户端接口中添加 GetClientID() 方法
-		// 目前我们通过获取一个种子来检查 ClientID
-		torrents, err := client.GetTorrents()
+// AddOptions 添加种子的参数，Link（磁力链接或种子 URL）与 Data（.torrent 文件内容）二选一
+type AddOptions struct {
+	Link string
+	Data []byte
+	// ClientID 目标客户端，为空时按目标客户端选择策略选择
+	ClientID string
+	// Category 添加后设置的分类，同时作为选择目标客户端的依据
+	Category string
+	// ResolveHash 调用方添加后还要操作该种子（如添加标签、暂停）时为 true，设置了 Category 时视为 true
+	// 此时种子 URL 由本服务下载后以文件添加，仍无法得知哈希时返回错误，而不是静默跳过后续操作
+	ResolveHash bool
+}
+
+// AddResult 添加种子的结果
+type AddResult struct {
+	ClientID string
+	// Hash 种子的 info-hash，无法确定时为空
+	Hash string
+	// CategoryError 设置分类失败的错误，此时种子本身已添加成功
+	CategoryError error
+}
+
+// AddWithOptions 选择目标客户端、添加种子并设置分类，供 API、兼容接口、监视目录与 RSS 共用
+// 出错时仍返回已选择的客户端，便于调用方记录
+func (ts *TorrentService) AddWithOptions(options AddOptions) (AddResult, error) {
+	result := AddResult{ClientID: options.ClientID}
+	resolve := options.ResolveHash || options.Category != ""
+	if resolve && options.Data == nil && clients.MagnetInfoHash(options.Link) == "" {
+		if strings.HasPrefix(options.Link, "magnet:") {
+			return result, &UnknownHashError{Link: options.Link}
+		}
+		data, err := ts.fetchTorrent(options.Link)
 		if err != nil {
-			continue
+			return result, err
+		}
+		options.Data = data
+	}
+
+	var err error
+	if result.ClientID == "" {
+		if options.Data != nil {
+			result.ClientID, err = ts.SelectClientForFile(options.Data, options.Category)
+		} else {
+			result.ClientID, err = ts.SelectClientForURL(options.Link, options.Category)
 		}
-		
-		// 如果这个客户端有种子且 ClientID 匹配
-		if len(torrents) > 0 && torrents[0].ClientID == clientID {
-			return client.AddTorrent(magnetURL)
+		if err != nil {
+			return result, err
 		}
-		
-		// 如果客户端没有种子，我们需要创建一个临时种子来检查 ClientID
-		// 这种情况比较复杂，可能需要修改接口设计
 	}
-	
-	// 如果没有找到匹配的客户端，返回错误
-	return &ClientNotFoundError{ClientID: clientID}
+
+	if options.Data != nil {
+		result.Hash, err = ts.AddTorrentFile(options.Data, result.ClientID)
+	} else {
+		result.Hash, err = ts.AddTorrent(options.Link, result.ClientID)
+	}
+	if err != nil {
+		return result, err
+	}
+	if resolve && result.Hash == "" {
+		return result, &UnknownHashError{Link: options.Link}
+	}
+
+	if options.Category != "" {
+		refs := []models.TorrentRef{{ClientID: result.ClientID, Hash: result.Hash}}
+		result.CategoryError = ts.AssignCategory(refs, options.Category)
+	}
+	return result, nil
 }
 
-func (ts *TorrentService) PauseTorrent(clientID string, hash string) error {
-	// 遍历所有客户端，找到匹配的 clientID
-	for _, client := range ts.clients {
-		if client.GetClientID() == clientID {
-			return client.PauseTorrent(hash)
-		}
+// AddTorrent 通过磁力链接或种子 URL 添加种子，返回种子的 info-hash，无法确定时为空字符串
+// 种子 URL 在添加前无法得知哈希，当前用户受归属或配额限制时由本服务下载种子文件后添加，以便记录归属
+func (ts *TorrentService) AddTorrent(magnetURL string, clientID string) (string, error) {
+	params := map[string]interface{}{"magnetURL": magnetURL}
+	knownHash := clients.MagnetInfoHash(magnetURL)
+	if knownHash == "" && ts.requiresOwnership() {
+		return ts.addTorrent(clientID, "", params, func(client clients.DownloaderClient) (string, error) {
+			data, err := ts.fetchTorrent(magnetURL)
+			if err != nil {
+				return "", err
+			}
+			hash, err := clients.TorrentFileInfoHash(data)
+			if err != nil {
+				return "", err
+			}
+			if err := ts.checkDuplicate(hash, clientID); err != nil {
+				return hash, err
+			}
+			_, err = client.AddTorrentFile(data)
+			return hash, err
+		})
 	}
-	
-	// 如果没有找到匹配的客户端，返回错误
-	return &ClientNotFoundError{ClientID: clientID}
+
+	return ts.addTorrent(clientID, knownHash, params, func(client clients.DownloaderClient) (string, error) {
+		hash, err := client.AddTorrent(magnetURL)
+		if hash == "" {
+			hash = knownHash
+		}
+		return hash, err
+	})
 }
 
-func (ts *TorrentService) ResumeTorrent(clientID string, hash string) error {
-	// 遍历所有客户端，找到匹配的 clientID
-	for _, client := range ts.clients {
-		if client.GetClientID() == clientID {
-			return client.ResumeTorrent(hash)
+// AddTorrentFile 通过 .torrent 文件内容添加种子，返回种子的 info-hash
+func (ts *TorrentService) AddTorrentFile(data []byte, clientID string) (string, error) {
+	hash, err := clients.TorrentFileInfoHash(data)
+	params := map[string]interface{}{"torrentFile": len(data)}
+	if err != nil {
+		ts.audit(models.AuditActionAdd, clientID, "", params, err)
+		return "", err
+	}
+	return ts.addTorrent(clientID, hash, params, func(client clients.DownloaderClient) (string, error) {
+		if _, err := client.AddTorrentFile(data); err != nil {
+			return hash, err
 		}
+		return hash, nil
+	})
+}
+
+// addTorrent 检查权限、配额与重复后通过 add 添加种子，并记录归属与审计日志
+// knownHash 为添加前已知的 info-hash，种子 URL 在添加前无法得知哈希，此时为空且不做重复检查
+func (ts *TorrentService) addTorrent(clientID, knownHash string, params map[string]interface{}, add func(clients.DownloaderClient) (string, error)) (hash string, err error) {
+	defer func() {
+		ts.audit(models.AuditActionAdd, clientID, hash, params, err)
+	}()
+
+	client, err := ts.clientFor(clientID, models.ActionAdd)
+	if err != nil {
+		return "", err
+	}
+	if err := ts.checkQuota(); err != nil {
+		return "", err
+	}
+	if err := ts.checkDuplicate(knownHash, clientID); err != nil {
+		return knownHash, err
+	}
+
+	hash, err = add(client)
+	ts.invalidate(clientID, hash, false)
+	if err != nil {
+		return hash, err
+	}
+
+	if hash != "" {
+		ts.recordOwnership(clientID, hash, models.SourceAPI)
+	} else {
+		log.Printf("⚠️  无法确定新种子的哈希，未记录归属 [%s]", clientID)
 	}
-	
-	// 如果没有找到匹配的客户端，返回错误
-	return &ClientNotFoundError{ClientID: clientID}
+	return hash, nil
 }
 
-func (ts *TorrentService) DeleteTorrent(clientID string, hash string, deleteFiles bool) error {
-	// 遍历所有客户端，找到匹配的 clientID
-	for _, client := range ts.clients {
-		if client.GetClientID() == clientID {
-			return client.DeleteTorrent(hash, deleteFiles)
+func (ts *TorrentService) PauseTorrent(clientID string, hash string) (err error) {
+	defer func() { ts.audit(models.AuditActionPause, clientID, hash, nil, err) }()
+
+	client, err := ts.torrentClientFor(clientID, hash, models.ActionPause)
+	if err != nil {
+		return err
+	}
+
+	err = client.PauseTorrent(hash)
+	ts.invalidate(clientID, hash, false)
+	return err
+}
+
+func (ts *TorrentService) ResumeTorrent(clientID string, hash string) (err error) {
+	defer func() { ts.audit(models.AuditActionResume, clientID, hash, nil, err) }()
+
+	client, err := ts.torrentClientFor(clientID, hash, models.ActionPause)
+	if err != nil {
+		return err
+	}
+
+	err = client.ResumeTorrent(hash)
+	ts.invalidate(clientID, hash, false)
+	return err
+}
+
+func (ts *TorrentService) DeleteTorrent(clientID string, hash string, deleteFiles bool) (err error) {
+	defer func() {
+		ts.audit(models.AuditActionDelete, clientID, hash, map[string]interface{}{"deleteFiles": deleteFiles}, err)
+	}()
+
+	action := models.ActionDelete
+	if deleteFiles {
+		action = models.ActionDeleteFiles
+	}
+	client, err := ts.torrentClientFor(clientID, hash, action)
+	if err != nil {
+		return err
+	}
+
+	err = client.DeleteTorrent(hash, deleteFiles)
+	ts.invalidate(clientID, hash, err == nil)
+	return err
+}
+
+// GetTorrentsWithFreshness 获取所有种子以及每个客户端数据的新鲜度
+// 未启动后台同步时实时查询，新鲜度为空
+func (ts *TorrentService) GetTorrentsWithFreshness() ([]models.UnifiedTorrent, []models.ClientFreshness) {
+	if ts.cache != nil {
+		torrents, freshness := ts.cache.snapshot()
+		visible := make([]models.ClientFreshness, 0, len(freshness))
+		for _, status := range freshness {
+			if ts.access.Can(status.ClientID, models.ActionView) {
+				visible = append(visible, status)
+			}
 		}
+		torrents = ts.filterTorrents(torrents)
+		ts.annotateOwners(torrents)
+		return torrents, visible
+	}
+	return ts.GetAllTorrents(), nil
+}
+
+// invalidate 变更操作后使对应客户端的快照失效
+func (ts *TorrentService) invalidate(clientID, hash string, removed bool) {
+	if ts.cache != nil {
+		ts.cache.invalidate(clientID, hash, removed)
 	}
-	
-	// 如果没有找到匹配的客户端，返回错误
-	return &ClientNotFoundError{ClientID: clientID}
 }
 
 // 自定义错误类型
@@ -119,9 +313,81 @@ func (e *ClientNotFoundError) Error() string {
 	return "client not found: " + e.ClientID
 }
 
-// GetClientConfigs 获取所有客户端配置
+// UnknownHashError 添加后需要操作该种子，但无法得知其哈希（如磁力链接缺少 btih）
+type UnknownHashError struct {
+	Link string
+}
+
+func (e *UnknownHashError) Error() string {
+	return "cannot determine the info-hash of " + e.Link
+}
+
+// GetClientConfigs 获取所有可见的客户端配置
 func (ts *TorrentService) GetClientConfigs() ([]models.ClientConfig, error) {
 	var configs []models.ClientConfig
-	err := ts.db.Find(&configs).Error
-	return configs, err
-}
\ No newline at end of file
+	if err := ts.db.Find(&configs).Error; err != nil {
+		return nil, err
+	}
+
+	visible := make([]models.ClientConfig, 0, len(configs))
+	for _, config := range configs {
+		if ts.access.Can(config.ClientID, models.ActionView) {
+			visible = append(visible, config)
+		}
+	}
+	return visible, nil
+}
+
+// getClient 根据 clientID 查找对应的下载器客户端
+func (ts *TorrentService) getClient(clientID string) (clients.DownloaderClient, error) {
+	for _, client := range ts.clients {
+		if client.GetClientID() == clientID {
+			return client, nil
+		}
+	}
+	return nil, &ClientNotFoundError{ClientID: clientID}
+}
+
+// clientFor 检查权限后返回对应的下载器客户端
+func (ts *TorrentService) clientFor(clientID, action string) (clients.DownloaderClient, error) {
+	if err := ts.authorize(clientID, action); err != nil {
+		return nil, err
+	}
+	return ts.getClient(clientID)
+}
+
+// GetClientLimits 获取客户端的全局速度限制
+func (ts *TorrentService) GetClientLimits(clientID string) (models.TransferLimits, error) {
+	client, err := ts.clientFor(clientID, models.ActionView)
+	if err != nil {
+		return models.TransferLimits{}, err
+	}
+	return client.GetTransferLimits()
+}
+
+// SetClientLimits 设置客户端的全局速度限制
+func (ts *TorrentService) SetClientLimits(clientID string, limits models.TransferLimits) (err error) {
+	defer func() {
+		ts.audit(models.AuditActionLimits, clientID, "", map[string]interface{}{"limits": limits}, err)
+	}()
+
+	client, err := ts.clientFor(clientID, models.ActionManage)
+	if err != nil {
+		return err
+	}
+	return client.SetTransferLimits(limits)
+}
+
+// SetTorrentLimits 设置单个种子的速度限制
+func (ts *TorrentService) SetTorrentLimits(clientID string, hash string, downloadLimit, uploadLimit int64) (err error) {
+	defer func() {
+		params := map[string]interface{}{"downloadLimit": downloadLimit, "uploadLimit": uploadLimit}
+		ts.audit(models.AuditActionLimits, clientID, hash, params, err)
+	}()
+
+	client, err := ts.torrentClientFor(clientID, hash, models.ActionEdit)
+	if err != nil {
+		return err
+	}
+	return client.SetTorrentLimits(hash, downloadLimit, uploadLimit)
+}
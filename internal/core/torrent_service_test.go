@@ -0,0 +1,153 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestAddTorrentResolvesURLHash(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testTorrentFile)
+	}))
+	defer server.Close()
+	// 测试服务器监听在环回地址，这里允许非管理员的下载访问它
+	defer func(client *http.Client) { publicDownloadClient = client }(publicDownloadClient)
+	publicDownloadClient = downloadClient
+
+	client := &fakeClient{id: "qb-1"}
+	service := NewTorrentService([]clients.DownloaderClient{client}, newTestDB(t))
+	service.RestrictToOwnTorrents(true)
+	user := service.WithAccess(&Access{UserID: 1, Username: "bob", Role: models.RoleOperator})
+	hash, _ := clients.TorrentFileInfoHash(testTorrentFile)
+	url := server.URL + "/a.torrent"
+
+	tests := []struct {
+		name     string
+		service  *TorrentService
+		link     string
+		wantHash string
+		calls    []string
+	}{
+		{"admin url goes to the client", service, url, "", []string{"add " + url}},
+		{"restricted url is downloaded first", user, url, hash, []string{"addFile " + hash}},
+		{"magnet is never downloaded", user, "magnet:?xt=urn:btih:" + testHashA, testHashA, []string{"add magnet:?xt=urn:btih:" + testHashA}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client.calls = nil
+			got, err := test.service.AddTorrent(test.link, "qb-1")
+			if err != nil {
+				t.Fatalf("AddTorrent() error = %v", err)
+			}
+			if got != test.wantHash {
+				t.Errorf("AddTorrent() = %q, want %q", got, test.wantHash)
+			}
+			if calls := client.Calls(); !reflect.DeepEqual(calls, test.calls) {
+				t.Errorf("client calls = %v, want %v", calls, test.calls)
+			}
+		})
+	}
+}
+
+func TestAddWithOptions(t *testing.T) {
+	client := &fakeClient{id: "qb-1"}
+	service := NewTorrentService([]clients.DownloaderClient{client}, newTestDB(t))
+	if err := service.CreateCategory(&models.Category{Name: "tv"}); err != nil {
+		t.Fatalf("CreateCategory() error = %v", err)
+	}
+	magnet := "magnet:?xt=urn:btih:" + testHashA
+
+	tests := []struct {
+		name         string
+		options      AddOptions
+		wantCategory bool
+		calls        []string
+	}{
+		{"client selected by policy", AddOptions{Link: magnet}, false, []string{"add " + magnet}},
+		{"category set after adding", AddOptions{Link: magnet, ClientID: "qb-1", Category: "tv"}, false, []string{"add " + magnet, "category " + testHashA + " tv"}},
+		{"unknown category", AddOptions{Link: magnet, Category: "movies"}, true, []string{"add " + magnet}},
+		{"torrent file", AddOptions{Data: testTorrentFile}, false, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client.calls = nil
+			result, err := service.AddWithOptions(test.options)
+			if err != nil {
+				t.Fatalf("AddWithOptions() error = %v", err)
+			}
+			if result.ClientID != "qb-1" {
+				t.Errorf("ClientID = %s, want qb-1", result.ClientID)
+			}
+			if (result.CategoryError != nil) != test.wantCategory {
+				t.Errorf("CategoryError = %v, want error %t", result.CategoryError, test.wantCategory)
+			}
+			calls := test.calls
+			if test.options.Data != nil {
+				calls = []string{"addFile " + result.Hash}
+			}
+			if got := client.Calls(); !reflect.DeepEqual(got, calls) {
+				t.Errorf("client calls = %v, want %v", got, calls)
+			}
+		})
+	}
+}
+
+func TestAddWithOptionsResolvesURLHash(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testTorrentFile)
+	}))
+	defer server.Close()
+
+	client := &fakeClient{id: "qb-1"}
+	service := NewTorrentService([]clients.DownloaderClient{client}, newTestDB(t))
+	if err := service.CreateCategory(&models.Category{Name: "tv"}); err != nil {
+		t.Fatalf("CreateCategory() error = %v", err)
+	}
+	hash, _ := clients.TorrentFileInfoHash(testTorrentFile)
+	link := server.URL + "/a.torrent"
+
+	tests := []struct {
+		name    string
+		options AddOptions
+		calls   []string
+	}{
+		{"plain url goes to the client", AddOptions{Link: link, ClientID: "qb-1"}, []string{"add " + link}},
+		{"url with category is downloaded first", AddOptions{Link: link, ClientID: "qb-1", Category: "tv"}, []string{"addFile " + hash, "category " + hash + " tv"}},
+		{"url that needs its hash is downloaded first", AddOptions{Link: link, ClientID: "qb-1", ResolveHash: true}, []string{"addFile " + hash}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client.calls = nil
+			result, err := service.AddWithOptions(test.options)
+			if err != nil {
+				t.Fatalf("AddWithOptions() error = %v", err)
+			}
+			if result.CategoryError != nil {
+				t.Errorf("CategoryError = %v", result.CategoryError)
+			}
+			if calls := client.Calls(); !reflect.DeepEqual(calls, test.calls) {
+				t.Errorf("client calls = %v, want %v", calls, test.calls)
+			}
+		})
+	}
+}
+
+func TestAddWithOptionsRequiresHash(t *testing.T) {
+	client := &fakeClient{id: "qb-1"}
+	service := NewTorrentService([]clients.DownloaderClient{client}, newTestDB(t))
+
+	_, err := service.AddWithOptions(AddOptions{Link: "magnet:?dn=no-hash", ClientID: "qb-1", ResolveHash: true})
+	var unknown *UnknownHashError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("AddWithOptions() error = %v, want UnknownHashError", err)
+	}
+	if calls := client.Calls(); len(calls) != 0 {
+		t.Errorf("client calls = %v, want none", calls)
+	}
+}
@@ -0,0 +1,407 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"github.com/fsnotify/fsnotify"
+	"gorm.io/gorm"
+)
+
+const (
+	// watchSettleTime 文件大小与修改时间保持不变多久后才处理，避免读取未写完的文件
+	watchSettleTime = 2 * time.Second
+	// watchSettleInterval 检查有变化的目录的周期
+	watchSettleInterval = time.Second
+	// maxWatchFileSize 监视目录中可处理的最大文件大小，与 qBittorrent 兼容接口的上传限制一致
+	maxWatchFileSize = 10 << 20
+)
+
+// FolderWatcher 监视目录
+// 按数据库中的配置监视目录，将新放入的 .torrent 与 .magnet 文件以内部调用的身份添加到客户端，
+// 处理后移入 done 或 failed 子目录并记录结果；inotify 不可用或配置为轮询的目录按 pollInterval 定期扫描
+type FolderWatcher struct {
+	service      *TorrentService
+	db           *gorm.DB
+	pollInterval time.Duration
+
+	mutex   sync.Mutex
+	trigger chan struct{}
+	// seen 上一次扫描时观察到的文件状态，键为文件路径
+	seen map[string]watchObservation
+}
+
+// watchObservation 文件在某次扫描时的大小与修改时间
+type watchObservation struct {
+	size    int64
+	modTime time.Time
+	since   time.Time
+	// processed 已处理但未能移出目录
+	processed bool
+}
+
+func NewFolderWatcher(service *TorrentService, db *gorm.DB, pollInterval time.Duration) *FolderWatcher {
+	return &FolderWatcher{
+		service:      service,
+		db:           db,
+		pollInterval: pollInterval,
+		trigger:      make(chan struct{}, 1),
+		seen:         make(map[string]watchObservation),
+	}
+}
+
+// Run 启动监视循环，直到 ctx 被取消；目录配置变化时重新建立监视
+func (w *FolderWatcher) Run(ctx context.Context) {
+	for {
+		if !w.watch(ctx) {
+			return
+		}
+	}
+}
+
+// watch 按当前配置监视目录，配置变化时返回 true，ctx 取消时返回 false
+func (w *FolderWatcher) watch(ctx context.Context) bool {
+	var folders []models.WatchFolder
+	if err := w.db.Where("enabled = ?", true).Order("id").Find(&folders).Error; err != nil {
+		log.Printf("⚠️  加载监视目录失败: %v", err)
+	}
+
+	notifier, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("⚠️  无法创建文件系统监视，所有目录改为轮询: %v", err)
+	} else {
+		defer notifier.Close()
+	}
+
+	// dirty 需要在下一轮检查的目录，polled 只能轮询的目录
+	dirty := make(map[uint]bool)
+	polled := make(map[uint]bool)
+	byPath := make(map[string]uint)
+	for _, folder := range folders {
+		dirty[folder.ID] = true
+		byPath[folder.Path] = folder.ID
+		if folder.Poll || notifier == nil {
+			polled[folder.ID] = true
+			continue
+		}
+		if err := notifier.Add(folder.Path); err != nil {
+			log.Printf("⚠️  无法监视目录 %s，改为轮询: %v", folder.Path, err)
+			polled[folder.ID] = true
+		}
+	}
+
+	var events <-chan fsnotify.Event
+	var watchErrors <-chan error
+	if notifier != nil {
+		events, watchErrors = notifier.Events, notifier.Errors
+	}
+
+	settle := time.NewTicker(watchSettleInterval)
+	defer settle.Stop()
+	poll := time.NewTicker(w.pollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-w.trigger:
+			return true
+		case event := <-events:
+			if id, ok := byPath[filepath.Dir(event.Name)]; ok {
+				dirty[id] = true
+			}
+		case err := <-watchErrors:
+			log.Printf("⚠️  文件系统监视出错: %v", err)
+		case <-poll.C:
+			for id := range polled {
+				dirty[id] = true
+			}
+		case <-settle.C:
+			for _, folder := range folders {
+				if dirty[folder.ID] {
+					dirty[folder.ID] = w.scan(folder)
+				}
+			}
+		}
+	}
+}
+
+// scan 扫描目录并处理已写完的文件，仍有文件在写入时返回 true
+func (w *FolderWatcher) scan(folder models.WatchFolder) bool {
+	entries, err := os.ReadDir(folder.Path)
+	if err != nil {
+		log.Printf("⚠️  读取监视目录 %s 失败: %v", folder.Path, err)
+		return false
+	}
+
+	now := time.Now()
+	pending := false
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.Type().IsRegular() || (ext != ".torrent" && ext != ".magnet") {
+			continue
+		}
+		file := filepath.Join(folder.Path, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		w.mutex.Lock()
+		previous, ok := w.seen[file]
+		if !ok || previous.size != info.Size() || !previous.modTime.Equal(info.ModTime()) {
+			w.seen[file] = watchObservation{size: info.Size(), modTime: info.ModTime(), since: now}
+			w.mutex.Unlock()
+			pending = true
+			continue
+		}
+		if previous.processed {
+			w.mutex.Unlock()
+			continue
+		}
+		if now.Sub(previous.since) < watchSettleTime {
+			w.mutex.Unlock()
+			pending = true
+			continue
+		}
+		delete(w.seen, file)
+		w.mutex.Unlock()
+
+		w.ingest(folder, entry.Name(), previous)
+	}
+	return pending
+}
+
+// ingest 添加单个文件，移入 done 或 failed 子目录并记录结果
+func (w *FolderWatcher) ingest(folder models.WatchFolder, name string, observed watchObservation) {
+	clientID, hash, err := w.add(folder, filepath.Join(folder.Path, name))
+	record := models.WatchIngestion{FolderID: folder.ID, File: name, ClientID: clientID, Hash: hash, Success: err == nil}
+	subdir := models.WatchDoneDir
+	if err != nil {
+		record.Error = err.Error()
+		subdir = models.WatchFailedDir
+		log.Printf("⚠️  监视目录 %s 中的 %s 添加失败: %v", folder.Path, name, err)
+	} else {
+		log.Printf("📥 监视目录 %s 中的 %s 已添加到客户端 [%s]", folder.Path, name, clientID)
+	}
+
+	record.MovedTo, err = moveProcessed(folder.Path, subdir, name)
+	if err != nil {
+		// 无法移走的文件在内容变化前不再处理，避免重复添加
+		log.Printf("⚠️  移动已处理的文件 %s 失败: %v", name, err)
+		observed.processed = true
+		w.mutex.Lock()
+		w.seen[filepath.Join(folder.Path, name)] = observed
+		w.mutex.Unlock()
+	}
+
+	if err := w.db.Create(&record).Error; err != nil {
+		log.Printf("⚠️  记录监视目录处理结果失败 [%s]: %v", name, err)
+	}
+}
+
+// add 读取文件并添加种子，.magnet 文件的第一个非空行为磁力链接或种子 URL
+func (w *FolderWatcher) add(folder models.WatchFolder, file string) (string, string, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return folder.ClientID, "", err
+	}
+	if info.Size() > maxWatchFileSize {
+		return folder.ClientID, "", fmt.Errorf("file is larger than %d bytes", maxWatchFileSize)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return folder.ClientID, "", err
+	}
+
+	options := AddOptions{ClientID: folder.ClientID, Category: folder.Category}
+	if strings.EqualFold(filepath.Ext(file), ".magnet") {
+		if options.Link = firstLine(data); options.Link == "" {
+			return folder.ClientID, "", errors.New("magnet file is empty")
+		}
+	} else {
+		options.Data = data
+	}
+	result, err := w.service.AddWithOptions(options)
+	if err != nil {
+		return result.ClientID, result.Hash, err
+	}
+
+	// 设置分类失败不影响已添加的种子
+	if result.CategoryError != nil {
+		log.Printf("⚠️  监视目录 %s 添加的种子设置分类失败: %v", folder.Path, result.CategoryError)
+	}
+	return result.ClientID, result.Hash, nil
+}
+
+// firstLine 返回第一个非空行
+func firstLine(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// moveProcessed 将文件移入 dir 下的子目录，重名时追加时间戳
+func moveProcessed(dir, subdir, name string) (string, error) {
+	target := filepath.Join(dir, subdir)
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return "", err
+	}
+
+	destination := filepath.Join(target, name)
+	if _, err := os.Stat(destination); err == nil {
+		ext := filepath.Ext(name)
+		destination = filepath.Join(target, fmt.Sprintf("%s.%d%s", strings.TrimSuffix(name, ext), time.Now().UnixNano(), ext))
+	}
+	if err := os.Rename(filepath.Join(dir, name), destination); err != nil {
+		return "", err
+	}
+	return destination, nil
+}
+
+// refresh 使监视循环按最新配置重新建立监视
+func (w *FolderWatcher) refresh() {
+	select {
+	case w.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// ListFolders 获取所有监视目录
+func (w *FolderWatcher) ListFolders() ([]models.WatchFolder, error) {
+	var folders []models.WatchFolder
+	err := w.db.Order("id").Find(&folders).Error
+	return folders, err
+}
+
+// GetFolder 获取单个监视目录
+func (w *FolderWatcher) GetFolder(id uint) (*models.WatchFolder, error) {
+	var folder models.WatchFolder
+	if err := w.db.First(&folder, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &WatchFolderNotFoundError{ID: id}
+		}
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// CreateFolder 创建监视目录
+func (w *FolderWatcher) CreateFolder(access *Access, folder *models.WatchFolder) (err error) {
+	defer func() {
+		Audit(w.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"createWatchFolder": folder}, err)
+	}()
+
+	if err := w.validate(folder); err != nil {
+		return err
+	}
+	if err := w.db.Create(folder).Error; err != nil {
+		return err
+	}
+	w.refresh()
+	return nil
+}
+
+// UpdateFolder 更新监视目录
+func (w *FolderWatcher) UpdateFolder(access *Access, id uint, folder *models.WatchFolder) (err error) {
+	defer func() {
+		Audit(w.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"updateWatchFolder": id, "folder": folder}, err)
+	}()
+
+	existing, err := w.GetFolder(id)
+	if err != nil {
+		return err
+	}
+	if err := w.validate(folder); err != nil {
+		return err
+	}
+
+	folder.Model = existing.Model
+	if err := w.db.Save(folder).Error; err != nil {
+		return err
+	}
+	w.refresh()
+	return nil
+}
+
+// DeleteFolder 删除监视目录，已记录的处理结果保留
+func (w *FolderWatcher) DeleteFolder(access *Access, id uint) (err error) {
+	defer func() {
+		Audit(w.db, access, models.AuditActionConfig, "", "", map[string]interface{}{"deleteWatchFolder": id}, err)
+	}()
+
+	result := w.db.Unscoped().Delete(&models.WatchFolder{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &WatchFolderNotFoundError{ID: id}
+	}
+	w.refresh()
+	return nil
+}
+
+// ListIngestions 获取监视目录最近的处理结果，按时间倒序
+func (w *FolderWatcher) ListIngestions(id uint, limit int) ([]models.WatchIngestion, error) {
+	if _, err := w.GetFolder(id); err != nil {
+		return nil, err
+	}
+
+	var ingestions []models.WatchIngestion
+	err := w.db.Where("folder_id = ?", id).Order("id DESC").Limit(limit).Find(&ingestions).Error
+	return ingestions, err
+}
+
+// validate 校验目录字段、目标客户端与分类是否存在，以及目录是否可读
+func (w *FolderWatcher) validate(folder *models.WatchFolder) error {
+	if err := folder.Validate(); err != nil {
+		return &InvalidWatchFolderError{Reason: err.Error()}
+	}
+	if folder.ClientID != "" {
+		if _, err := w.service.getClient(folder.ClientID); err != nil {
+			return err
+		}
+	}
+	if folder.Category != "" {
+		if _, err := w.service.GetCategory(folder.Category); err != nil {
+			return err
+		}
+	}
+	if info, err := os.Stat(folder.Path); err != nil || !info.IsDir() {
+		return &InvalidWatchFolderError{Reason: "path is not an accessible directory"}
+	}
+	return nil
+}
+
+// WatchFolderNotFoundError 监视目录不存在
+type WatchFolderNotFoundError struct {
+	ID uint
+}
+
+func (e *WatchFolderNotFoundError) Error() string {
+	return fmt.Sprintf("watch folder not found: %d", e.ID)
+}
+
+// InvalidWatchFolderError 监视目录配置不合法
+type InvalidWatchFolderError struct {
+	Reason string
+}
+
+func (e *InvalidWatchFolderError) Error() string {
+	return "invalid watch folder: " + e.Reason
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+func TestFirstLine(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"magnet:?xt=urn:btih:abc\n", "magnet:?xt=urn:btih:abc"},
+		{"\r\n  \n  https://example.com/a.torrent  \r\nignored", "https://example.com/a.torrent"},
+		{"", ""},
+		{" \n\t\n", ""},
+	}
+	for _, test := range tests {
+		if got := firstLine([]byte(test.data)); got != test.want {
+			t.Errorf("firstLine(%q) = %q, want %q", test.data, got, test.want)
+		}
+	}
+}
+
+func TestFolderWatcherScan(t *testing.T) {
+	db := newTestDB(t)
+	client := &fakeClient{id: "qb-1"}
+	service := NewTorrentService([]clients.DownloaderClient{client}, db)
+	watcher := NewFolderWatcher(service, db, time.Minute)
+
+	dir := t.TempDir()
+	folder := &models.WatchFolder{Path: dir, ClientID: "qb-1", Enabled: true}
+	if err := watcher.CreateFolder(nil, folder); err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+	files := map[string]string{
+		"a.torrent":   string(testTorrentFile),
+		"b.magnet":    "\nmagnet:?xt=urn:btih:" + testHashA + "\n",
+		"c.magnet":    "\n\n",
+		"d.torrent":   "not bencode",
+		"ignored.txt": "magnet:?xt=urn:btih:" + testHashB,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 第一次扫描只记录文件状态，保持不变 watchSettleTime 后才处理
+	if pending := watcher.scan(*folder); !pending {
+		t.Fatalf("first scan() = false, want pending files")
+	}
+	if calls := client.Calls(); len(calls) != 0 {
+		t.Fatalf("client calls = %v after the first scan, want none", calls)
+	}
+	watcher.mutex.Lock()
+	for file, observed := range watcher.seen {
+		observed.since = observed.since.Add(-watchSettleTime)
+		watcher.seen[file] = observed
+	}
+	watcher.mutex.Unlock()
+	if pending := watcher.scan(*folder); pending {
+		t.Errorf("second scan() = true, want all files processed")
+	}
+
+	hash, _ := clients.TorrentFileInfoHash(testTorrentFile)
+	wantCalls := []string{"addFile " + hash, "add magnet:?xt=urn:btih:" + testHashA}
+	if calls := client.Calls(); !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("client calls = %v, want %v", calls, wantCalls)
+	}
+
+	ingestions, err := watcher.ListIngestions(folder.ID, 10)
+	if err != nil {
+		t.Fatalf("ListIngestions() error = %v", err)
+	}
+	results := make(map[string]bool)
+	for _, ingestion := range ingestions {
+		results[ingestion.File] = ingestion.Success
+		subdir := models.WatchFailedDir
+		if ingestion.Success {
+			subdir = models.WatchDoneDir
+		}
+		if want := filepath.Join(dir, subdir, ingestion.File); ingestion.MovedTo != want {
+			t.Errorf("%s moved to %q, want %q", ingestion.File, ingestion.MovedTo, want)
+		}
+	}
+	wantResults := map[string]bool{"a.torrent": true, "b.magnet": true, "c.magnet": false, "d.torrent": false}
+	if !reflect.DeepEqual(results, wantResults) {
+		t.Errorf("ingestions = %v, want %v", results, wantResults)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ignored.txt")); err != nil {
+		t.Errorf("ignored.txt was moved: %v", err)
+	}
+}
+
+func TestCreateWatchFolderValidation(t *testing.T) {
+	db := newTestDB(t)
+	service := NewTorrentService([]clients.DownloaderClient{&fakeClient{id: "qb-1"}}, db)
+	watcher := NewFolderWatcher(service, db, time.Minute)
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		folder  models.WatchFolder
+		wantErr interface{}
+	}{
+		{"relative path", models.WatchFolder{Path: "watch"}, new(*InvalidWatchFolderError)},
+		{"missing directory", models.WatchFolder{Path: filepath.Join(dir, "missing")}, new(*InvalidWatchFolderError)},
+		{"unknown client", models.WatchFolder{Path: dir, ClientID: "de-1"}, new(*ClientNotFoundError)},
+		{"unknown category", models.WatchFolder{Path: dir, Category: "tv"}, new(*CategoryNotFoundError)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := watcher.CreateFolder(nil, &test.folder); !errors.As(err, test.wantErr) {
+				t.Errorf("CreateFolder() error = %v, want %T", err, test.wantErr)
+			}
+		})
+	}
+
+	disabled := &models.WatchFolder{Path: dir}
+	if err := watcher.CreateFolder(nil, disabled); err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+	if folder, err := watcher.GetFolder(disabled.ID); err != nil || folder.Enabled {
+		t.Errorf("GetFolder() = %+v, %v, want a disabled folder", folder, err)
+	}
+}
@@ -0,0 +1,209 @@
+package metrics
+
+import (
+	"time"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+)
+
+// InstrumentClient 包装客户端适配器，记录每次调用的耗时与错误，并登记客户端类型
+func (m *Metrics) InstrumentClient(client clients.DownloaderClient, clientType string) clients.DownloaderClient {
+	m.mutex.Lock()
+	m.clientTypes[client.GetClientID()] = clientType
+	m.mutex.Unlock()
+
+	return &instrumentedClient{
+		client:     client,
+		metrics:    m,
+		clientID:   client.GetClientID(),
+		clientType: clientType,
+	}
+}
+
+type instrumentedClient struct {
+	client     clients.DownloaderClient
+	metrics    *Metrics
+	clientID   string
+	clientType string
+}
+
+// observe 记录一次调用的耗时，调用失败时累加错误计数
+func (ic *instrumentedClient) observe(method string, start time.Time, err error) {
+	ic.metrics.clientDuration.WithLabelValues(ic.clientID, ic.clientType, method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		ic.metrics.clientErrors.WithLabelValues(ic.clientID, ic.clientType, method).Inc()
+	}
+}
+
+func (ic *instrumentedClient) GetTorrents() ([]models.UnifiedTorrent, error) {
+	start := time.Now()
+	torrents, err := ic.client.GetTorrents()
+	ic.observe("GetTorrents", start, err)
+	return torrents, err
+}
+
+func (ic *instrumentedClient) AddTorrent(magnetURL string) (string, error) {
+	start := time.Now()
+	hash, err := ic.client.AddTorrent(magnetURL)
+	ic.observe("AddTorrent", start, err)
+	return hash, err
+}
+
+func (ic *instrumentedClient) AddTorrentFile(data []byte) (string, error) {
+	start := time.Now()
+	hash, err := ic.client.AddTorrentFile(data)
+	ic.observe("AddTorrentFile", start, err)
+	return hash, err
+}
+
+func (ic *instrumentedClient) PauseTorrent(hash string) error {
+	start := time.Now()
+	err := ic.client.PauseTorrent(hash)
+	ic.observe("PauseTorrent", start, err)
+	return err
+}
+
+func (ic *instrumentedClient) ResumeTorrent(hash string) error {
+	start := time.Now()
+	err := ic.client.ResumeTorrent(hash)
+	ic.observe("ResumeTorrent", start, err)
+	return err
+}
+
+func (ic *instrumentedClient) DeleteTorrent(hash string, deleteFiles bool) error {
+	start := time.Now()
+	err := ic.client.DeleteTorrent(hash, deleteFiles)
+	ic.observe("DeleteTorrent", start, err)
+	return err
+}
+
+func (ic *instrumentedClient) GetClientID() string {
+	return ic.clientID
+}
+
+func (ic *instrumentedClient) SyncTorrents(full bool) (models.TorrentSync, error) {
+	start := time.Now()
+	sync, err := ic.client.SyncTorrents(full)
+	ic.observe("SyncTorrents", start, err)
+	return sync, err
+}
+
+func (ic *instrumentedClient) GetTransferStats() (models.TransferStats, error) {
+	start := time.Now()
+	stats, err := ic.client.GetTransferStats()
+	ic.observe("GetTransferStats", start, err)
+	return stats, err
+}
+
+func (ic *instrumentedClient) GetFreeSpace() (int64, error) {
+	start := time.Now()
+	space, err := ic.client.GetFreeSpace()
+	ic.observe("GetFreeSpace", start, err)
+	return space, err
+}
+
+func (ic *instrumentedClient) GetTransferLimits() (models.TransferLimits, error) {
+	start := time.Now()
+	limits, err := ic.client.GetTransferLimits()
+	ic.observe("GetTransferLimits", start, err)
+	return limits, err
+}
+
+func (ic *instrumentedClient) SetTransferLimits(limits models.TransferLimits) error {
+	start := time.Now()
+	err := ic.client.SetTransferLimits(limits)
+	ic.observe("SetTransferLimits", start, err)
+	return err
+}
+
+func (ic *instrumentedClient) SetTorrentLimits(hash string, downloadLimit, uploadLimit int64) error {
+	start := time.Now()
+	err := ic.client.SetTorrentLimits(hash, downloadLimit, uploadLimit)
+	ic.observe("SetTorrentLimits", start, err)
+	return err
+}
+
+func (ic *instrumentedClient) EnsureCategory(name, savePath string) error {
+	start := time.Now()
+	err := ic.client.EnsureCategory(name, savePath)
+	ic.observe("EnsureCategory", start, err)
+	return err
+}
+
+func (ic *instrumentedClient) SetCategory(hashes []string, category string) error {
+	start := time.Now()
+	err := ic.client.SetCategory(hashes, category)
+	ic.observe("SetCategory", start, err)
+	return err
+}
+
+func (ic *instrumentedClient) AddTags(hashes []string, tags []string) error {
+	start := time.Now()
+	err := ic.client.AddTags(hashes, tags)
+	ic.observe("AddTags", start, err)
+	return err
+}
+
+func (ic *instrumentedClient) RemoveTags(hashes []string, tags []string) error {
+	start := time.Now()
+	err := ic.client.RemoveTags(hashes, tags)
+	ic.observe("RemoveTags", start, err)
+	return err
+}
+
+func (ic *instrumentedClient) SetShareLimits(hashes []string, limits models.ShareLimits) (bool, error) {
+	start := time.Now()
+	native, err := ic.client.SetShareLimits(hashes, limits)
+	ic.observe("SetShareLimits", start, err)
+	return native, err
+}
+
+func (ic *instrumentedClient) RenameTorrent(hash, name string) error {
+	start := time.Now()
+	err := ic.client.RenameTorrent(hash, name)
+	ic.observe("RenameTorrent", start, err)
+	return err
+}
+
+func (ic *instrumentedClient) RenameFile(hash, oldPath, newPath string) error {
+	start := time.Now()
+	err := ic.client.RenameFile(hash, oldPath, newPath)
+	ic.observe("RenameFile", start, err)
+	return err
+}
+
+func (ic *instrumentedClient) ExportTorrent(hash string) (models.TorrentExport, error) {
+	start := time.Now()
+	export, err := ic.client.ExportTorrent(hash)
+	ic.observe("ExportTorrent", start, err)
+	return export, err
+}
+
+func (ic *instrumentedClient) ImportTorrent(data []byte, options models.ImportOptions) (string, error) {
+	start := time.Now()
+	hash, err := ic.client.ImportTorrent(data, options)
+	ic.observe("ImportTorrent", start, err)
+	return hash, err
+}
+
+func (ic *instrumentedClient) RecheckTorrent(hash string) error {
+	start := time.Now()
+	err := ic.client.RecheckTorrent(hash)
+	ic.observe("RecheckTorrent", start, err)
+	return err
+}
+
+func (ic *instrumentedClient) MoveTorrent(hash, location string) error {
+	start := time.Now()
+	err := ic.client.MoveTorrent(hash, location)
+	ic.observe("MoveTorrent", start, err)
+	return err
+}
+
+func (ic *instrumentedClient) GetTorrentFiles(hash string) (models.TorrentContent, error) {
+	start := time.Now()
+	content, err := ic.client.GetTorrentFiles(hash)
+	ic.observe("GetTorrentFiles", start, err)
+	return content, err
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "downnexus"
+
+// Options 指标配置
+// PerTorrent 开启后导出单个种子的指标，MaxTorrentSeries 限制导出的种子数量以控制基数
+type Options struct {
+	PerTorrent       bool
+	MaxTorrentSeries int
+}
+
+// Metrics Prometheus 指标注册表
+// 包括 HTTP 请求指标、客户端调用指标以及抓取时从种子快照计算的种子指标
+type Metrics struct {
+	registry *prometheus.Registry
+	options  Options
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	clientDuration *prometheus.HistogramVec
+	clientErrors   *prometheus.CounterVec
+
+	// clientTypes 客户端 ID 到客户端类型的映射，由 InstrumentClient 登记
+	mutex       sync.RWMutex
+	clientTypes map[string]string
+}
+
+func New(options Options) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		options:  options,
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests handled by the API.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		clientDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "client_call_duration_seconds",
+			Help:      "Latency of calls to downloader clients in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"client_id", "client_type", "method"}),
+		clientErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "client_call_errors_total",
+			Help:      "Total number of failed calls to downloader clients.",
+		}, []string{"client_id", "client_type", "method"}),
+		clientTypes: make(map[string]string),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.clientDuration,
+		m.clientErrors,
+	)
+	return m
+}
+
+// Handler 返回 /metrics 的 HTTP 处理器
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware 记录 HTTP 请求数与耗时
+// 路由标签使用注册的路由模板而非实际路径，未匹配的请求统一记为 unmatched
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		m.httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.httpDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// clientType 获取客户端类型，未登记时为空
+func (m *Metrics) clientType(clientID string) string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.clientTypes[clientID]
+}
+
+// clientIDs 获取所有已登记的客户端
+func (m *Metrics) clientIDs() map[string]string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	ids := make(map[string]string, len(m.clientTypes))
+	for id, clientType := range m.clientTypes {
+		ids[id] = clientType
+	}
+	return ids
+}
@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+	"github.com/gin-gonic/gin"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// fakeClient 只实现指标测试用到的方法
+type fakeClient struct {
+	clients.DownloaderClient
+
+	id       string
+	torrents []models.UnifiedTorrent
+	err      error
+}
+
+func (c *fakeClient) GetClientID() string {
+	return c.id
+}
+
+func (c *fakeClient) GetTorrents() ([]models.UnifiedTorrent, error) {
+	return c.torrents, c.err
+}
+
+// gather 抓取一次，返回指定指标的全部序列
+func gather(t *testing.T, m *Metrics, name string) []*dto.Metric {
+	t.Helper()
+	families, err := m.registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()
+		}
+	}
+	return nil
+}
+
+// labels 将序列的标签转换为 map
+func labels(metric *dto.Metric) map[string]string {
+	result := make(map[string]string)
+	for _, label := range metric.GetLabel() {
+		result[label.GetName()] = label.GetValue()
+	}
+	return result
+}
+
+func TestMiddlewareUsesRouteTemplates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New(Options{})
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/torrents/:hash", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/torrents/a", "/torrents/b", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	want := map[string]float64{"/torrents/:hash 200": 2, "unmatched 404": 1}
+	got := make(map[string]float64)
+	for _, metric := range gather(t, m, "downnexus_http_requests_total") {
+		l := labels(metric)
+		got[l["route"]+" "+l["status"]] = metric.GetCounter().GetValue()
+	}
+	if len(got) != len(want) {
+		t.Fatalf("series = %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %v, want %v", key, got[key], value)
+		}
+	}
+}
+
+func TestInstrumentClientCountsErrors(t *testing.T) {
+	m := New(Options{})
+	failing := m.InstrumentClient(&fakeClient{id: "qb-1", err: errors.New("unreachable")}, "qbittorrent")
+	working := m.InstrumentClient(&fakeClient{id: "tr-1"}, "transmission")
+	failing.GetTorrents()
+	failing.GetTorrents()
+	working.GetTorrents()
+
+	errorsByClient := make(map[string]float64)
+	for _, metric := range gather(t, m, "downnexus_client_call_errors_total") {
+		l := labels(metric)
+		if l["method"] != "GetTorrents" {
+			t.Errorf("method = %q, want GetTorrents", l["method"])
+		}
+		errorsByClient[l["client_id"]+" "+l["client_type"]] = metric.GetCounter().GetValue()
+	}
+	if len(errorsByClient) != 1 || errorsByClient["qb-1 qbittorrent"] != 2 {
+		t.Errorf("errors = %v, want qb-1 qbittorrent: 2", errorsByClient)
+	}
+	if calls := gather(t, m, "downnexus_client_call_duration_seconds"); len(calls) != 2 {
+		t.Errorf("duration series = %d, want 2", len(calls))
+	}
+}
+
+func TestTorrentCollector(t *testing.T) {
+	torrents := []models.UnifiedTorrent{
+		{ClientID: "qb-1", Hash: "c", State: "uploading", Size: 100},
+		{ClientID: "qb-1", Hash: "a", State: "downloading", Size: 200, DownloadSpeed: 50},
+		{ClientID: "qb-1", Hash: "b", State: "pausedDL", Size: 300},
+	}
+	tests := []struct {
+		name        string
+		options     Options
+		wantSeries  []string
+		wantDropped float64
+	}{
+		{
+			name:    "per-torrent metrics disabled",
+			options: Options{},
+		},
+		{
+			name:       "all torrents",
+			options:    Options{PerTorrent: true},
+			wantSeries: []string{"a", "b", "c"},
+		},
+		{
+			name:        "series limit keeps the first torrents by hash",
+			options:     Options{PerTorrent: true, MaxTorrentSeries: 2},
+			wantSeries:  []string{"a", "b"},
+			wantDropped: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := New(test.options)
+			client := m.InstrumentClient(&fakeClient{id: "qb-1", torrents: torrents}, "qbittorrent")
+			m.RegisterTorrentCollector(core.NewTorrentService([]clients.DownloaderClient{client}, nil))
+
+			states := make(map[string]float64)
+			for _, metric := range gather(t, m, "downnexus_torrents") {
+				states[labels(metric)["state"]] = metric.GetGauge().GetValue()
+			}
+			if len(states) != len(models.NormalizedStates) || states["downloading"] != 1 || states["seeding"] != 1 || states["paused"] != 1 {
+				t.Errorf("torrents by state = %v", states)
+			}
+			if size := gather(t, m, "downnexus_torrents_size_bytes"); len(size) != 1 || size[0].GetGauge().GetValue() != 600 {
+				t.Errorf("size = %v, want 600", size)
+			}
+
+			var hashes []string
+			for _, metric := range gather(t, m, "downnexus_torrent_size_bytes") {
+				hashes = append(hashes, labels(metric)["hash"])
+			}
+			if len(hashes) != len(test.wantSeries) {
+				t.Fatalf("per-torrent series = %v, want %v", hashes, test.wantSeries)
+			}
+			for i := range hashes {
+				if hashes[i] != test.wantSeries[i] {
+					t.Errorf("per-torrent series = %v, want %v", hashes, test.wantSeries)
+					break
+				}
+			}
+			if dropped := gather(t, m, "downnexus_torrent_series_dropped"); test.options.PerTorrent &&
+				(len(dropped) != 1 || dropped[0].GetGauge().GetValue() != test.wantDropped) {
+				t.Errorf("dropped = %v, want %v", dropped, test.wantDropped)
+			}
+		})
+	}
+}
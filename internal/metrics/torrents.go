@@ -0,0 +1,164 @@
+package metrics
+
+import (
+	"sort"
+
+	"down-nexus-api/internal/core"
+	"down-nexus-api/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	clientLabels  = []string{"client_id", "client_type"}
+	torrentLabels = []string{"client_id", "client_type", "hash"}
+
+	clientUpDesc = prometheus.NewDesc(namespace+"_client_up",
+		"Whether the last sync with the client succeeded (1) or failed (0).", clientLabels, nil)
+	clientLastSyncDesc = prometheus.NewDesc(namespace+"_client_last_sync_timestamp_seconds",
+		"Unix time of the last successful sync with the client.", clientLabels, nil)
+	torrentsDesc = prometheus.NewDesc(namespace+"_torrents",
+		"Number of torrents by normalized state.", append(clientLabels, "state"), nil)
+	downloadSpeedDesc = prometheus.NewDesc(namespace+"_download_speed_bytes",
+		"Sum of torrent download speeds in bytes per second.", clientLabels, nil)
+	uploadSpeedDesc = prometheus.NewDesc(namespace+"_upload_speed_bytes",
+		"Sum of torrent upload speeds in bytes per second.", clientLabels, nil)
+	sizeDesc = prometheus.NewDesc(namespace+"_torrents_size_bytes",
+		"Total size of all torrents in bytes.", clientLabels, nil)
+	downloadedDesc = prometheus.NewDesc(namespace+"_torrents_downloaded_bytes",
+		"Total bytes downloaded by all torrents.", clientLabels, nil)
+	uploadedDesc = prometheus.NewDesc(namespace+"_torrents_uploaded_bytes",
+		"Total bytes uploaded by all torrents.", clientLabels, nil)
+
+	torrentDownloadSpeedDesc = prometheus.NewDesc(namespace+"_torrent_download_speed_bytes",
+		"Download speed of a single torrent in bytes per second.", torrentLabels, nil)
+	torrentUploadSpeedDesc = prometheus.NewDesc(namespace+"_torrent_upload_speed_bytes",
+		"Upload speed of a single torrent in bytes per second.", torrentLabels, nil)
+	torrentSizeDesc = prometheus.NewDesc(namespace+"_torrent_size_bytes",
+		"Size of a single torrent in bytes.", torrentLabels, nil)
+	torrentProgressDesc = prometheus.NewDesc(namespace+"_torrent_progress_ratio",
+		"Download progress of a single torrent between 0 and 1.", torrentLabels, nil)
+	torrentRatioDesc = prometheus.NewDesc(namespace+"_torrent_share_ratio",
+		"Share ratio of a single torrent.", torrentLabels, nil)
+	torrentDroppedDesc = prometheus.NewDesc(namespace+"_torrent_series_dropped",
+		"Number of torrents omitted from per-torrent metrics due to the series limit.", nil, nil)
+)
+
+// RegisterTorrentCollector 注册种子指标收集器，指标在每次抓取时从种子快照计算
+func (m *Metrics) RegisterTorrentCollector(service *core.TorrentService) {
+	m.registry.MustRegister(&torrentCollector{metrics: m, service: service})
+}
+
+type torrentCollector struct {
+	metrics *Metrics
+	service *core.TorrentService
+}
+
+type clientTotals struct {
+	states        map[string]int
+	downloadSpeed int64
+	uploadSpeed   int64
+	size          int64
+	downloaded    int64
+	uploaded      int64
+}
+
+func (tc *torrentCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clientUpDesc
+	ch <- clientLastSyncDesc
+	ch <- torrentsDesc
+	ch <- downloadSpeedDesc
+	ch <- uploadSpeedDesc
+	ch <- sizeDesc
+	ch <- downloadedDesc
+	ch <- uploadedDesc
+	if tc.metrics.options.PerTorrent {
+		ch <- torrentDownloadSpeedDesc
+		ch <- torrentUploadSpeedDesc
+		ch <- torrentSizeDesc
+		ch <- torrentProgressDesc
+		ch <- torrentRatioDesc
+		ch <- torrentDroppedDesc
+	}
+}
+
+func (tc *torrentCollector) Collect(ch chan<- prometheus.Metric) {
+	torrents, freshness := tc.service.GetTorrentsWithFreshness()
+	clientTypes := tc.metrics.clientIDs()
+
+	// 未启动后台同步时没有新鲜度信息，不导出可达性指标
+	for _, status := range freshness {
+		clientType := clientTypes[status.ClientID]
+		up := 0.0
+		if status.UpdatedAt != nil && status.Error == "" {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(clientUpDesc, prometheus.GaugeValue, up, status.ClientID, clientType)
+		if status.UpdatedAt != nil {
+			ch <- prometheus.MustNewConstMetric(clientLastSyncDesc, prometheus.GaugeValue,
+				float64(status.UpdatedAt.Unix()), status.ClientID, clientType)
+		}
+	}
+
+	// 没有种子的客户端也导出 0 值，保证序列稳定
+	totals := make(map[string]*clientTotals, len(clientTypes))
+	for clientID := range clientTypes {
+		totals[clientID] = &clientTotals{states: make(map[string]int)}
+	}
+	for _, torrent := range torrents {
+		t, ok := totals[torrent.ClientID]
+		if !ok {
+			t = &clientTotals{states: make(map[string]int)}
+			totals[torrent.ClientID] = t
+		}
+		t.states[models.NormalizeState(torrent.State)]++
+		t.downloadSpeed += torrent.DownloadSpeed
+		t.uploadSpeed += torrent.UploadSpeed
+		t.size += torrent.Size
+		t.downloaded += torrent.Downloaded
+		t.uploaded += torrent.Uploaded
+	}
+
+	for clientID, t := range totals {
+		clientType := clientTypes[clientID]
+		for _, state := range models.NormalizedStates {
+			ch <- prometheus.MustNewConstMetric(torrentsDesc, prometheus.GaugeValue,
+				float64(t.states[state]), clientID, clientType, state)
+		}
+		ch <- prometheus.MustNewConstMetric(downloadSpeedDesc, prometheus.GaugeValue, float64(t.downloadSpeed), clientID, clientType)
+		ch <- prometheus.MustNewConstMetric(uploadSpeedDesc, prometheus.GaugeValue, float64(t.uploadSpeed), clientID, clientType)
+		ch <- prometheus.MustNewConstMetric(sizeDesc, prometheus.GaugeValue, float64(t.size), clientID, clientType)
+		ch <- prometheus.MustNewConstMetric(downloadedDesc, prometheus.GaugeValue, float64(t.downloaded), clientID, clientType)
+		ch <- prometheus.MustNewConstMetric(uploadedDesc, prometheus.GaugeValue, float64(t.uploaded), clientID, clientType)
+	}
+
+	if tc.metrics.options.PerTorrent {
+		tc.collectPerTorrent(ch, torrents, clientTypes)
+	}
+}
+
+// collectPerTorrent 导出单个种子的指标
+// 按客户端和哈希排序后截取前 MaxTorrentSeries 个，保证每次抓取选中的种子稳定
+func (tc *torrentCollector) collectPerTorrent(ch chan<- prometheus.Metric, torrents []models.UnifiedTorrent, clientTypes map[string]string) {
+	sort.Slice(torrents, func(i, j int) bool {
+		if torrents[i].ClientID != torrents[j].ClientID {
+			return torrents[i].ClientID < torrents[j].ClientID
+		}
+		return torrents[i].Hash < torrents[j].Hash
+	})
+
+	dropped := 0
+	if limit := tc.metrics.options.MaxTorrentSeries; limit > 0 && len(torrents) > limit {
+		dropped = len(torrents) - limit
+		torrents = torrents[:limit]
+	}
+
+	for _, torrent := range torrents {
+		labels := []string{torrent.ClientID, clientTypes[torrent.ClientID], torrent.Hash}
+		ch <- prometheus.MustNewConstMetric(torrentDownloadSpeedDesc, prometheus.GaugeValue, float64(torrent.DownloadSpeed), labels...)
+		ch <- prometheus.MustNewConstMetric(torrentUploadSpeedDesc, prometheus.GaugeValue, float64(torrent.UploadSpeed), labels...)
+		ch <- prometheus.MustNewConstMetric(torrentSizeDesc, prometheus.GaugeValue, float64(torrent.Size), labels...)
+		ch <- prometheus.MustNewConstMetric(torrentProgressDesc, prometheus.GaugeValue, torrent.Progress, labels...)
+		ch <- prometheus.MustNewConstMetric(torrentRatioDesc, prometheus.GaugeValue, torrent.Ratio, labels...)
+	}
+	ch <- prometheus.MustNewConstMetric(torrentDroppedDesc, prometheus.GaugeValue, float64(dropped))
+}
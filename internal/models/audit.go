@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// 审计日志记录的操作类型
+const (
+	AuditActionAdd         = "add"
+	AuditActionPause       = "pause"
+	AuditActionResume      = "resume"
+	AuditActionDelete      = "delete"
+	AuditActionRename      = "rename"
+	AuditActionSetCategory = "set_category"
+	AuditActionTags        = "tags"
+	AuditActionLimits      = "limits"
+	AuditActionConfig      = "config"
+	AuditActionMigrate     = "migrate"
+	AuditActionMove        = "move"
+	AuditActionUser        = "user"
+)
+
+// AuditActorSystem 内部调用（定时任务、分享限制等）的操作者名称
+const AuditActorSystem = "system"
+
+// AuditLog 修改操作的审计记录，只追加不修改
+// Params 为操作参数的 JSON 文本，Error 为空表示操作成功
+type AuditLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index;not null" json:"created_at"`
+	// UserID 为 0 表示内部调用
+	UserID   uint   `gorm:"index" json:"user_id,omitempty"`
+	Username string `gorm:"not null" json:"username"`
+	SourceIP string `json:"source_ip,omitempty"`
+	Action   string `gorm:"index;not null" json:"action"`
+	ClientID string `gorm:"index:idx_audit_target" json:"client_id,omitempty"`
+	Hash     string `gorm:"index:idx_audit_target" json:"hash,omitempty"`
+	Params   string `gorm:"type:text" json:"params,omitempty"`
+	Success  bool   `gorm:"not null" json:"success"`
+	Error    string `gorm:"type:text" json:"error,omitempty"`
+}
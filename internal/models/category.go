@@ -0,0 +1,33 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Category Down-Nexus 层面的分类定义
+// 在 qBittorrent 上映射为分类，在 Transmission 上映射为带前缀的标签
+type Category struct {
+	gorm.Model
+	// Name 分类名称，全局唯一
+	Name string `gorm:"uniqueIndex;not null" json:"name"`
+	// SavePaths 每个客户端的默认保存路径
+	SavePaths []CategorySavePath `gorm:"constraint:OnDelete:CASCADE" json:"save_paths"`
+}
+
+// CategorySavePath 分类在某个客户端上的默认保存路径
+type CategorySavePath struct {
+	ID         uint   `gorm:"primarykey" json:"-"`
+	CategoryID uint   `gorm:"uniqueIndex:idx_category_client;not null" json:"-"`
+	ClientID   string `gorm:"uniqueIndex:idx_category_client;not null" json:"client_id"`
+	SavePath   string `gorm:"not null" json:"save_path"`
+}
+
+// SavePathFor 返回分类在指定客户端上的默认保存路径，未配置时返回空字符串
+func (c *Category) SavePathFor(clientID string) string {
+	for _, p := range c.SavePaths {
+		if p.ClientID == clientID {
+			return p.SavePath
+		}
+	}
+	return ""
+}
@@ -16,8 +16,8 @@ type ClientConfig struct {
 	Host string `gorm:"not null" json:"host"`
 	// Username 登录用户名
 	Username string `gorm:"not null" json:"username"`
-	// Password 登录密码
-	Password string `gorm:"not null" json:"password"`
+	// Password 登录密码或 API 令牌，配置主密钥后以加密形式存储，任何接口都不返回
+	Password string `gorm:"not null" json:"-"`
 	// Enabled 是否启用该客户端配置
 	Enabled bool `gorm:"default:true" json:"enabled"`
 }
\ No newline at end of file
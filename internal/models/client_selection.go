@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ClientSelectionStrategy 未指定客户端时选择目标客户端的策略
+type ClientSelectionStrategy string
+
+const (
+	// SelectionRoundRobin 在可添加的客户端之间轮流选择
+	SelectionRoundRobin ClientSelectionStrategy = "round_robin"
+	// SelectionLeastActive 选择下载中种子最少的客户端
+	SelectionLeastActive ClientSelectionStrategy = "least_active"
+	// SelectionMostFreeSpace 选择默认保存路径剩余空间最多的客户端
+	SelectionMostFreeSpace ClientSelectionStrategy = "most_free_space"
+	// SelectionCategory 按分类名称匹配规则
+	SelectionCategory ClientSelectionStrategy = "category"
+	// SelectionTracker 按 tracker 域名匹配规则
+	SelectionTracker ClientSelectionStrategy = "tracker"
+)
+
+// IsRuleBased 是否按规则匹配，规则未命中时使用回退策略
+func (s ClientSelectionStrategy) IsRuleBased() bool {
+	return s == SelectionCategory || s == SelectionTracker
+}
+
+func (s ClientSelectionStrategy) valid() bool {
+	switch s {
+	case SelectionRoundRobin, SelectionLeastActive, SelectionMostFreeSpace, SelectionCategory, SelectionTracker:
+		return true
+	}
+	return false
+}
+
+// ClientSelectionPolicy 目标客户端选择策略，全局仅一条，未配置时使用轮询
+type ClientSelectionPolicy struct {
+	ID       uint                    `gorm:"primarykey" json:"-"`
+	Strategy ClientSelectionStrategy `gorm:"not null" json:"strategy"`
+	// Fallback 规则未命中时使用的策略，只能是轮询、最少下载或最多剩余空间，为空时使用轮询
+	Fallback ClientSelectionStrategy `json:"fallback,omitempty"`
+	// Rules 按顺序匹配的规则，仅 category 与 tracker 策略使用
+	Rules     []ClientSelectionRule `gorm:"foreignKey:PolicyID;constraint:OnDelete:CASCADE" json:"rules"`
+	UpdatedAt time.Time             `json:"updated_at"`
+}
+
+// ClientSelectionRule 选择规则，Match 为分类名称或 tracker 域名（同时匹配其子域名）
+type ClientSelectionRule struct {
+	ID       uint   `gorm:"primarykey" json:"-"`
+	PolicyID uint   `gorm:"index;not null" json:"-"`
+	Match    string `gorm:"not null" json:"match"`
+	ClientID string `gorm:"not null" json:"client_id"`
+}
+
+// DefaultClientSelectionPolicy 未配置时的默认策略
+func DefaultClientSelectionPolicy() ClientSelectionPolicy {
+	return ClientSelectionPolicy{Strategy: SelectionRoundRobin, Rules: []ClientSelectionRule{}}
+}
+
+// Validate 校验策略与规则，tracker 规则的域名统一为小写
+func (p *ClientSelectionPolicy) Validate() error {
+	if !p.Strategy.valid() {
+		return fmt.Errorf("invalid strategy: %q", p.Strategy)
+	}
+	if p.Fallback != "" && (!p.Fallback.valid() || p.Fallback.IsRuleBased()) {
+		return fmt.Errorf("invalid fallback strategy: %q", p.Fallback)
+	}
+	if !p.Strategy.IsRuleBased() {
+		if len(p.Rules) > 0 {
+			return fmt.Errorf("rules are only supported by the category and tracker strategies")
+		}
+		return nil
+	}
+
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		rule.Match = strings.TrimSpace(rule.Match)
+		if p.Strategy == SelectionTracker {
+			rule.Match = strings.TrimPrefix(strings.ToLower(rule.Match), ".")
+		}
+		if rule.Match == "" || rule.ClientID == "" {
+			return fmt.Errorf("rule %d: match and client_id are required", i)
+		}
+	}
+	return nil
+}
+
+// FallbackStrategy 返回规则未命中时使用的策略
+func (p *ClientSelectionPolicy) FallbackStrategy() ClientSelectionStrategy {
+	if p.Fallback == "" {
+		return SelectionRoundRobin
+	}
+	return p.Fallback
+}
@@ -0,0 +1,32 @@
+package models
+
+import "testing"
+
+func TestClientSelectionPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    ClientSelectionPolicy
+		wantMatch string
+		wantErr   bool
+	}{
+		{name: "round robin", policy: ClientSelectionPolicy{Strategy: SelectionRoundRobin}},
+		{name: "unknown strategy", policy: ClientSelectionPolicy{Strategy: "random"}, wantErr: true},
+		{name: "rule based fallback", policy: ClientSelectionPolicy{Strategy: SelectionCategory, Fallback: SelectionTracker}, wantErr: true},
+		{name: "rules without rule strategy", policy: ClientSelectionPolicy{Strategy: SelectionLeastActive, Rules: []ClientSelectionRule{{Match: "tv", ClientID: "qb-1"}}}, wantErr: true},
+		{name: "category rule", policy: ClientSelectionPolicy{Strategy: SelectionCategory, Rules: []ClientSelectionRule{{Match: " tv ", ClientID: "qb-1"}}}, wantMatch: "tv"},
+		{name: "tracker rule is normalized", policy: ClientSelectionPolicy{Strategy: SelectionTracker, Fallback: SelectionMostFreeSpace, Rules: []ClientSelectionRule{{Match: ".Tracker.Example.ORG", ClientID: "qb-1"}}}, wantMatch: "tracker.example.org"},
+		{name: "rule without client", policy: ClientSelectionPolicy{Strategy: SelectionTracker, Rules: []ClientSelectionRule{{Match: "example.org"}}}, wantErr: true},
+		{name: "empty match", policy: ClientSelectionPolicy{Strategy: SelectionCategory, Rules: []ClientSelectionRule{{Match: " ", ClientID: "qb-1"}}}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.policy.Validate()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %t", err, test.wantErr)
+			}
+			if test.wantMatch != "" && test.policy.Rules[0].Match != test.wantMatch {
+				t.Errorf("Match = %q, want %q", test.policy.Rules[0].Match, test.wantMatch)
+			}
+		})
+	}
+}
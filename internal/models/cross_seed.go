@@ -0,0 +1,40 @@
+package models
+
+// CrossSeedStatus 单个种子文件的辅种结果
+type CrossSeedStatus string
+
+const (
+	// CrossSeedInjected 已暂停添加到持有数据的客户端并开始校验
+	CrossSeedInjected CrossSeedStatus = "injected"
+	// CrossSeedMatched 预演模式下找到匹配，未添加
+	CrossSeedMatched CrossSeedStatus = "matched"
+	// CrossSeedSkipped 没有匹配的种子或种子已存在
+	CrossSeedSkipped CrossSeedStatus = "skipped"
+	// CrossSeedFailed 文件无法解析或添加失败
+	CrossSeedFailed CrossSeedStatus = "failed"
+)
+
+// CrossSeedResult 单个种子文件的匹配与注入结果
+type CrossSeedResult struct {
+	File   string          `json:"file"`
+	Name   string          `json:"name,omitempty"`
+	Hash   string          `json:"hash,omitempty"`
+	Size   int64           `json:"size,omitempty"`
+	Status CrossSeedStatus `json:"status"`
+	Reason string          `json:"reason,omitempty"`
+	// 匹配到的已有种子，新种子添加到同一客户端的同一保存路径
+	ClientID    string `json:"client_id,omitempty"`
+	MatchedHash string `json:"matched_hash,omitempty"`
+	MatchedName string `json:"matched_name,omitempty"`
+	SavePath    string `json:"save_path,omitempty"`
+}
+
+// CrossSeedReport 一次辅种扫描的报告
+type CrossSeedReport struct {
+	DryRun   bool              `json:"dry_run"`
+	Injected int               `json:"injected"`
+	Matched  int               `json:"matched"`
+	Skipped  int               `json:"skipped"`
+	Failed   int               `json:"failed"`
+	Results  []CrossSeedResult `json:"results"`
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HousekeepingAction 整理规则命中种子后执行的动作
+type HousekeepingAction string
+
+const (
+	// HousekeepingPause 暂停种子
+	HousekeepingPause HousekeepingAction = "pause"
+	// HousekeepingResume 恢复种子
+	HousekeepingResume HousekeepingAction = "resume"
+	// HousekeepingRemove 删除种子，保留数据
+	HousekeepingRemove HousekeepingAction = "remove"
+	// HousekeepingRemoveWithData 删除种子及数据
+	HousekeepingRemoveWithData HousekeepingAction = "remove_with_data"
+	// HousekeepingMove 将数据移动到 Location
+	HousekeepingMove HousekeepingAction = "move"
+	// HousekeepingSetCategory 设置分类为 TargetCategory
+	HousekeepingSetCategory HousekeepingAction = "set_category"
+	// HousekeepingSetLimits 设置种子的上传/下载速度限制
+	HousekeepingSetLimits HousekeepingAction = "set_limits"
+	// HousekeepingNotify 向 WebhookURL 发送通知
+	HousekeepingNotify HousekeepingAction = "notify"
+)
+
+// HousekeepingRule 种子整理规则
+// 定期对所有客户端的种子求值，满足全部已设置条件的种子执行动作；
+// 每个种子在规则的同一版本下只成功执行一次，修改规则后重新计算
+type HousekeepingRule struct {
+	gorm.Model
+	// Name 规则名称
+	Name string `gorm:"not null" json:"name"`
+
+	// ClientID 只作用于该客户端，为空表示所有客户端
+	ClientID string `gorm:"index" json:"client_id"`
+	// MinRatio 分享率不低于该值
+	MinRatio *float64 `json:"min_ratio"`
+	// MinSeedingTime 做种时间不少于该值（分钟）
+	MinSeedingTime *int64 `json:"min_seeding_time"`
+	// States 统一状态之一，逗号分隔，为空表示不限制
+	States string `json:"states"`
+	// Category 分类等于该值，空字符串表示未分类，为空表示不限制
+	Category *string `json:"category"`
+	// Tracker 当前 tracker 的域名，同时匹配子域名
+	Tracker string `json:"tracker"`
+	// MinSize 与 MaxSize 为种子大小的范围（字节）
+	MinSize *int64 `json:"min_size"`
+	MaxSize *int64 `json:"max_size"`
+	// MinAge 添加到客户端的时间不少于该值（分钟）
+	MinAge *int64 `json:"min_age"`
+	// MaxFreeSpace 客户端默认保存路径的剩余空间低于该值（字节）
+	MaxFreeSpace *int64 `json:"max_free_space"`
+
+	// Action 满足条件后执行的动作
+	Action HousekeepingAction `gorm:"not null" json:"action"`
+	// Location move 动作的目标路径
+	Location string `json:"location,omitempty"`
+	// TargetCategory set_category 动作设置的分类，为空表示取消分类
+	TargetCategory string `json:"target_category,omitempty"`
+	// DownloadLimit 与 UploadLimit 为 set_limits 动作设置的速度限制（字节/秒），为空或 0 表示不限速
+	DownloadLimit *int64 `json:"download_limit,omitempty"`
+	UploadLimit   *int64 `json:"upload_limit,omitempty"`
+	// WebhookURL notify 动作以 POST 发送 JSON 的地址
+	WebhookURL string `json:"webhook_url,omitempty"`
+
+	// DryRun 只记录会执行的动作，不实际执行
+	DryRun bool `json:"dry_run"`
+	// Enabled 是否启用该规则
+	Enabled bool `json:"enabled"`
+}
+
+// Validate 校验规则字段是否合法
+func (r *HousekeepingRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.MinRatio == nil && r.MinSeedingTime == nil && r.States == "" && r.Category == nil && r.Tracker == "" &&
+		r.MinSize == nil && r.MaxSize == nil && r.MinAge == nil && r.MaxFreeSpace == nil {
+		return fmt.Errorf("at least one condition is required")
+	}
+	for _, value := range []*int64{r.MinSeedingTime, r.MinSize, r.MaxSize, r.MinAge, r.MaxFreeSpace, r.DownloadLimit, r.UploadLimit} {
+		if value != nil && *value < 0 {
+			return fmt.Errorf("conditions and limits must not be negative")
+		}
+	}
+	if r.MinRatio != nil && *r.MinRatio < 0 {
+		return fmt.Errorf("min_ratio must not be negative")
+	}
+	if r.MinSize != nil && r.MaxSize != nil && *r.MinSize > *r.MaxSize {
+		return fmt.Errorf("min_size must not exceed max_size")
+	}
+	for _, state := range r.StateList() {
+		if NormalizeState(state) != state {
+			return fmt.Errorf("invalid state: %q", state)
+		}
+	}
+	r.Tracker = strings.ToLower(strings.TrimSpace(r.Tracker))
+
+	switch r.Action {
+	case HousekeepingPause, HousekeepingResume, HousekeepingRemove, HousekeepingRemoveWithData, HousekeepingSetCategory:
+	case HousekeepingMove:
+		if !path.IsAbs(r.Location) {
+			return fmt.Errorf("location must be an absolute path")
+		}
+	case HousekeepingSetLimits:
+		if r.DownloadLimit == nil && r.UploadLimit == nil {
+			return fmt.Errorf("at least one of download_limit or upload_limit is required")
+		}
+	case HousekeepingNotify:
+		parsed, err := url.Parse(r.WebhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("webhook_url must be an absolute http or https URL")
+		}
+	default:
+		return fmt.Errorf("invalid action: %q", r.Action)
+	}
+	return nil
+}
+
+// StateList 返回 States 中的状态
+func (r *HousekeepingRule) StateList() []string {
+	var states []string
+	for _, state := range strings.Split(r.States, ",") {
+		if state = strings.TrimSpace(state); state != "" {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// HousekeepingMatch 规则命中的种子及将要执行的动作
+type HousekeepingMatch struct {
+	RuleID   uint               `json:"rule_id"`
+	RuleName string             `json:"rule_name"`
+	ClientID string             `json:"client_id"`
+	Hash     string             `json:"hash"`
+	Name     string             `json:"name"`
+	Action   HousekeepingAction `json:"action"`
+}
+
+// HousekeepingExecution 整理规则的一次执行记录，只追加不修改
+type HousekeepingExecution struct {
+	ID        uint               `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time          `gorm:"index;not null" json:"created_at"`
+	RuleID    uint               `gorm:"index;not null" json:"rule_id"`
+	RuleName  string             `gorm:"not null" json:"rule_name"`
+	ClientID  string             `gorm:"index:idx_housekeeping_target;not null" json:"client_id"`
+	Hash      string             `gorm:"index:idx_housekeeping_target;not null" json:"hash"`
+	Name      string             `json:"name"`
+	Action    HousekeepingAction `gorm:"not null" json:"action"`
+	// DryRun 为 true 表示规则处于预演模式，动作未实际执行
+	DryRun  bool   `gorm:"not null" json:"dry_run"`
+	Success bool   `gorm:"not null" json:"success"`
+	Error   string `gorm:"type:text" json:"error,omitempty"`
+}
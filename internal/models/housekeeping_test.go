@@ -0,0 +1,42 @@
+package models
+
+import "testing"
+
+func TestHousekeepingRuleValidate(t *testing.T) {
+	ratio := 2.0
+	negative := int64(-1)
+	small, large := int64(10), int64(20)
+	limit := int64(1024)
+	tests := []struct {
+		name        string
+		rule        HousekeepingRule
+		wantTracker string
+		wantErr     bool
+	}{
+		{name: "ratio and remove", rule: HousekeepingRule{Name: "r", MinRatio: &ratio, Action: HousekeepingRemove}},
+		{name: "missing name", rule: HousekeepingRule{MinRatio: &ratio, Action: HousekeepingRemove}, wantErr: true},
+		{name: "no condition", rule: HousekeepingRule{Name: "r", Action: HousekeepingPause}, wantErr: true},
+		{name: "negative condition", rule: HousekeepingRule{Name: "r", MinAge: &negative, Action: HousekeepingPause}, wantErr: true},
+		{name: "size range reversed", rule: HousekeepingRule{Name: "r", MinSize: &large, MaxSize: &small, Action: HousekeepingPause}, wantErr: true},
+		{name: "unknown state", rule: HousekeepingRule{Name: "r", States: "seeding,frozen", Action: HousekeepingPause}, wantErr: true},
+		{name: "tracker is normalized", rule: HousekeepingRule{Name: "r", Tracker: " Tracker.Example.ORG ", Action: HousekeepingPause}, wantTracker: "tracker.example.org"},
+		{name: "relative move location", rule: HousekeepingRule{Name: "r", MinRatio: &ratio, Action: HousekeepingMove, Location: "archive"}, wantErr: true},
+		{name: "absolute move location", rule: HousekeepingRule{Name: "r", MinRatio: &ratio, Action: HousekeepingMove, Location: "/archive"}},
+		{name: "limits without values", rule: HousekeepingRule{Name: "r", MinRatio: &ratio, Action: HousekeepingSetLimits}, wantErr: true},
+		{name: "upload limit", rule: HousekeepingRule{Name: "r", MinRatio: &ratio, Action: HousekeepingSetLimits, UploadLimit: &limit}},
+		{name: "webhook without scheme", rule: HousekeepingRule{Name: "r", MinRatio: &ratio, Action: HousekeepingNotify, WebhookURL: "example.org/hook"}, wantErr: true},
+		{name: "https webhook", rule: HousekeepingRule{Name: "r", MinRatio: &ratio, Action: HousekeepingNotify, WebhookURL: "https://example.org/hook"}},
+		{name: "unknown action", rule: HousekeepingRule{Name: "r", MinRatio: &ratio, Action: "archive"}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.rule.Validate()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %t", err, test.wantErr)
+			}
+			if test.wantTracker != "" && test.rule.Tracker != test.wantTracker {
+				t.Errorf("Tracker = %q, want %q", test.rule.Tracker, test.wantTracker)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package models
+
+// TransferLimits 客户端全局传输限制
+// 速度单位统一为字节/秒，0 表示不限速
+type TransferLimits struct {
+	// DownloadLimit 全局下载速度上限
+	DownloadLimit int64 `json:"download_limit"`
+	// UploadLimit 全局上传速度上限
+	UploadLimit int64 `json:"upload_limit"`
+	// AltSpeedEnabled 是否启用备用速度（"乌龟"模式）
+	AltSpeedEnabled bool `json:"alt_speed_enabled"`
+}
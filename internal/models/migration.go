@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+)
+
+// MigrationStep 迁移中单个种子所处的步骤
+type MigrationStep string
+
+const (
+	// MigrationPending 等待迁移
+	MigrationPending MigrationStep = "pending"
+	// MigrationPlanned 预演模式下可以迁移，未执行任何操作
+	MigrationPlanned MigrationStep = "planned"
+	// MigrationExporting 从源客户端导出 .torrent 文件
+	MigrationExporting MigrationStep = "exporting"
+	// MigrationAdding 添加到目标客户端
+	MigrationAdding MigrationStep = "adding"
+	// MigrationVerifying 等待目标客户端校验完成并开始做种
+	MigrationVerifying MigrationStep = "verifying"
+	// MigrationRemoving 从源客户端移除种子（保留数据）
+	MigrationRemoving MigrationStep = "removing"
+	// MigrationDone 迁移完成
+	MigrationDone MigrationStep = "done"
+	// MigrationFailed 迁移失败，源客户端上的种子保持不变
+	MigrationFailed MigrationStep = "failed"
+)
+
+// Finished 是否为终止步骤
+func (s MigrationStep) Finished() bool {
+	return s == MigrationPlanned || s == MigrationDone || s == MigrationFailed
+}
+
+// MigrationJob 一次迁移任务，保存在内存中，服务重启后丢失
+type MigrationJob struct {
+	ID            uint       `json:"id"`
+	SourceClient  string     `json:"source_client"`
+	TargetClient  string     `json:"target_client"`
+	DryRun        bool       `json:"dry_run"`
+	SkipHashCheck bool       `json:"skip_hash_check"`
+	CreatedBy     string     `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+	// Total 为种子总数，Done 与 Failed 为已完成与失败的数量
+	Total  int             `json:"total"`
+	Done   int             `json:"done"`
+	Failed int             `json:"failed"`
+	Items  []MigrationItem `json:"items"`
+}
+
+// MigrationItem 迁移任务中单个种子的进度
+type MigrationItem struct {
+	Hash string `json:"hash"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	// SourcePath 源客户端中的保存路径，TargetPath 为映射后在目标客户端中使用的路径
+	SourcePath string        `json:"source_path,omitempty"`
+	TargetPath string        `json:"target_path,omitempty"`
+	Step       MigrationStep `json:"step"`
+	// Progress 校验阶段目标客户端上的完成度
+	Progress   float64    `json:"progress"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
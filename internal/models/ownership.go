@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// 种子的添加来源
+const (
+	SourceAPI       = "api"
+	SourceCrossSeed = "cross_seed"
+)
+
+// TorrentOwnership 记录种子由哪个用户添加
+// 同一客户端上的同一种子只保留最近一次添加的记录
+type TorrentOwnership struct {
+	ID       uint   `gorm:"primarykey" json:"-"`
+	UserID   uint   `gorm:"index;not null" json:"user_id"`
+	ClientID string `gorm:"uniqueIndex:idx_ownership_torrent;not null" json:"client_id"`
+	// Hash 小写的 info-hash
+	Hash    string    `gorm:"uniqueIndex:idx_ownership_torrent;not null" json:"hash"`
+	Source  string    `gorm:"not null" json:"source"`
+	AddedAt time.Time `gorm:"not null" json:"added_at"`
+}
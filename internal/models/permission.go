@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 用户角色
+const (
+	RoleAdmin    = "admin"
+	RoleOperator = "operator"
+	RoleViewer   = "viewer"
+)
+
+// 针对客户端的操作权限
+const (
+	ActionView        = "view"         // 查看种子、速度与统计
+	ActionAdd         = "add"          // 添加种子
+	ActionPause       = "pause"        // 暂停与恢复种子
+	ActionDelete      = "delete"       // 删除种子（保留文件）
+	ActionDeleteFiles = "delete_files" // 删除种子及其文件
+	ActionEdit        = "edit"         // 修改种子的分类、标签、名称、限速与分享目标
+	ActionManage      = "manage"       // 修改客户端的全局设置，如速度限制
+)
+
+// AllActions 所有操作权限
+var AllActions = []string{
+	ActionView,
+	ActionAdd,
+	ActionPause,
+	ActionDelete,
+	ActionDeleteFiles,
+	ActionEdit,
+	ActionManage,
+}
+
+// RoleActions 每个角色默认拥有的操作权限
+// 管理员拥有全部权限且不受授权范围限制
+var RoleActions = map[string][]string{
+	RoleAdmin:    AllActions,
+	RoleOperator: {ActionView, ActionAdd, ActionPause, ActionDelete, ActionEdit},
+	RoleViewer:   {ActionView},
+}
+
+// GrantAllClients 授权作用于所有客户端时使用的 ClientID
+const GrantAllClients = "*"
+
+// UserGrant 用户在某个客户端上的授权
+// 用户存在授权时只能访问被授权的客户端，且在每个客户端上的权限为角色权限与授权动作的交集
+type UserGrant struct {
+	ID       uint   `gorm:"primarykey" json:"-"`
+	UserID   uint   `gorm:"uniqueIndex:idx_user_grant;not null" json:"-"`
+	ClientID string `gorm:"uniqueIndex:idx_user_grant;not null" json:"client_id"`
+	// Actions 逗号分隔的操作权限
+	Actions string `gorm:"not null" json:"actions"`
+}
+
+// ActionList 返回授权的操作权限列表
+func (g *UserGrant) ActionList() []string {
+	var actions []string
+	for _, action := range strings.Split(g.Actions, ",") {
+		if action = strings.TrimSpace(action); action != "" {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// Validate 校验授权的客户端与操作权限
+func (g *UserGrant) Validate() error {
+	if g.ClientID == "" {
+		return fmt.Errorf("client_id is required, use %q for all clients", GrantAllClients)
+	}
+	actions := g.ActionList()
+	if len(actions) == 0 {
+		return fmt.Errorf("at least one action is required")
+	}
+	for _, action := range actions {
+		if !IsValidAction(action) {
+			return fmt.Errorf("unknown action: %s", action)
+		}
+	}
+	return nil
+}
+
+// IsValidRole 判断角色是否有效
+func IsValidRole(role string) bool {
+	_, ok := RoleActions[role]
+	return ok
+}
+
+// IsValidAction 判断操作权限是否有效
+func IsValidAction(action string) bool {
+	for _, a := range AllActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUserGrantValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		grant       UserGrant
+		wantActions []string
+		wantErr     bool
+	}{
+		{"single action", UserGrant{ClientID: "qb-1", Actions: "view"}, []string{"view"}, false},
+		{"spaces and empty entries", UserGrant{ClientID: "*", Actions: " view, ,pause "}, []string{"view", "pause"}, false},
+		{"missing client", UserGrant{Actions: "view"}, []string{"view"}, true},
+		{"no actions", UserGrant{ClientID: "qb-1", Actions: " , "}, nil, true},
+		{"unknown action", UserGrant{ClientID: "qb-1", Actions: "view,root"}, []string{"view", "root"}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actions := test.grant.ActionList(); !reflect.DeepEqual(actions, test.wantActions) {
+				t.Errorf("ActionList() = %v, want %v", actions, test.wantActions)
+			}
+			if err := test.grant.Validate(); (err != nil) != test.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %t", err, test.wantErr)
+			}
+		})
+	}
+}
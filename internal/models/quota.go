@@ -0,0 +1,48 @@
+package models
+
+import (
+	"fmt"
+)
+
+// UserQuota 用户配额，字段为空表示不限制
+// 管理员不受配额限制
+type UserQuota struct {
+	ID     uint `gorm:"primarykey" json:"-"`
+	UserID uint `gorm:"uniqueIndex;not null" json:"-"`
+	// MaxActiveDownloads 同时下载中（未完成且未暂停）的种子数上限
+	MaxActiveDownloads *int `json:"max_active_downloads"`
+	// MaxTotalSize 名下种子的总大小上限（字节）
+	MaxTotalSize *int64 `json:"max_total_size"`
+	// MaxAddsPerDay 过去 24 小时内添加种子的次数上限
+	MaxAddsPerDay *int `json:"max_adds_per_day"`
+}
+
+// QuotaUsage 用户当前的配额使用情况
+type QuotaUsage struct {
+	ActiveDownloads int   `json:"active_downloads"`
+	TotalSize       int64 `json:"total_size"`
+	AddsLastDay     int   `json:"adds_last_day"`
+}
+
+// QuotaStatus 配额与使用情况
+type QuotaStatus struct {
+	Limits UserQuota  `json:"limits"`
+	Usage  QuotaUsage `json:"usage"`
+	// Exempt 为 true 表示不受配额限制（管理员）
+	Exempt bool `json:"exempt"`
+}
+
+// Limited 是否设置了任一配额
+func (q *UserQuota) Limited() bool {
+	return q.MaxActiveDownloads != nil || q.MaxTotalSize != nil || q.MaxAddsPerDay != nil
+}
+
+// Validate 校验配额不为负数
+func (q *UserQuota) Validate() error {
+	if (q.MaxActiveDownloads != nil && *q.MaxActiveDownloads < 0) ||
+		(q.MaxTotalSize != nil && *q.MaxTotalSize < 0) ||
+		(q.MaxAddsPerDay != nil && *q.MaxAddsPerDay < 0) {
+		return fmt.Errorf("quota limits must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RSSFeed RSS/Atom 订阅源，按间隔定期抓取并用过滤规则匹配条目
+type RSSFeed struct {
+	gorm.Model
+	// Name 订阅源名称
+	Name string `gorm:"not null" json:"name"`
+	// URL 订阅地址，仅支持 http 与 https
+	URL string `gorm:"uniqueIndex;not null" json:"url"`
+	// Interval 抓取间隔（分钟），为 0 时使用 RSS_INTERVAL
+	Interval int `json:"interval"`
+	// Enabled 是否启用该订阅源
+	Enabled bool `json:"enabled"`
+	// LastFetchedAt 与 LastError 为最近一次抓取的时间与错误信息
+	LastFetchedAt *time.Time `json:"last_fetched_at,omitempty"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+}
+
+// Validate 校验订阅源字段是否合法
+func (f *RSSFeed) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	parsed, err := url.Parse(f.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if f.Interval < 0 {
+		return fmt.Errorf("interval must not be negative")
+	}
+	return nil
+}
+
+// RSSRule RSS 过滤规则，匹配的条目添加到指定客户端并设置分类
+// 正则表达式不区分大小写；设置了大小或剧集条件时，无法得知大小或剧集的条目不匹配
+type RSSRule struct {
+	gorm.Model
+	// Name 规则名称
+	Name string `gorm:"not null" json:"name"`
+	// FeedID 生效的订阅源，为 0 表示作用于所有订阅源
+	FeedID uint `gorm:"index" json:"feed_id"`
+	// Include 标题必须匹配的正则表达式，为空表示全部匹配
+	Include string `json:"include"`
+	// Exclude 标题不能匹配的正则表达式，为空表示不排除
+	Exclude string `json:"exclude"`
+	// MinSize 与 MaxSize 为条目大小的范围（字节），为 0 表示不限制
+	MinSize int64 `json:"min_size"`
+	MaxSize int64 `json:"max_size"`
+	// Season 只匹配该季的剧集，为 0 表示不限制
+	Season int `json:"season"`
+	// MinEpisode 与 MaxEpisode 为集数范围，为 0 表示不限制
+	MinEpisode int `json:"min_episode"`
+	MaxEpisode int `json:"max_episode"`
+	// SkipGrabbedEpisodes 同一剧集只添加一次，不同发布组或分辨率的重复版本将被跳过
+	SkipGrabbedEpisodes bool `json:"skip_grabbed_episodes"`
+	// ClientID 目标客户端，为空时按目标客户端选择策略选择
+	ClientID string `json:"client_id"`
+	// Category 添加后设置的分类，为空表示不设置
+	Category string `json:"category"`
+	// Enabled 是否启用该规则
+	Enabled bool `json:"enabled"`
+}
+
+// Validate 校验规则字段是否合法
+func (r *RSSRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if _, _, err := r.Patterns(); err != nil {
+		return err
+	}
+	if r.MinSize < 0 || r.MaxSize < 0 {
+		return fmt.Errorf("sizes must not be negative")
+	}
+	if r.MaxSize > 0 && r.MinSize > r.MaxSize {
+		return fmt.Errorf("min_size must not exceed max_size")
+	}
+	if r.Season < 0 || r.MinEpisode < 0 || r.MaxEpisode < 0 {
+		return fmt.Errorf("season and episodes must not be negative")
+	}
+	if r.MaxEpisode > 0 && r.MinEpisode > r.MaxEpisode {
+		return fmt.Errorf("min_episode must not exceed max_episode")
+	}
+	return nil
+}
+
+// Patterns 编译 Include 与 Exclude，未设置的返回 nil
+func (r *RSSRule) Patterns() (include, exclude *regexp.Regexp, err error) {
+	if r.Include != "" {
+		if include, err = regexp.Compile("(?i)" + r.Include); err != nil {
+			return nil, nil, fmt.Errorf("invalid include: %w", err)
+		}
+	}
+	if r.Exclude != "" {
+		if exclude, err = regexp.Compile("(?i)" + r.Exclude); err != nil {
+			return nil, nil, fmt.Errorf("invalid exclude: %w", err)
+		}
+	}
+	return include, exclude, nil
+}
+
+// RSSItem 已处理的订阅条目，同一订阅源中的同一条目只处理一次，添加失败的条目按退避间隔重试
+type RSSItem struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index;not null" json:"created_at"`
+	FeedID    uint      `gorm:"uniqueIndex:idx_rss_item;not null" json:"feed_id"`
+	// GUID 条目的唯一标识，条目未提供时使用下载地址
+	GUID   string `gorm:"uniqueIndex:idx_rss_item;not null" json:"guid"`
+	RuleID uint   `gorm:"index;not null" json:"rule_id"`
+	Title  string `gorm:"not null" json:"title"`
+	// Link 种子的下载地址或磁力链接
+	Link string `gorm:"type:text;not null" json:"link"`
+	Size int64  `json:"size,omitempty"`
+	// Episode 从标题识别的剧集，格式为 S01E02
+	Episode  string `gorm:"index" json:"episode,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+	Success  bool   `gorm:"not null" json:"success"`
+	Error    string `gorm:"type:text" json:"error,omitempty"`
+	// Attempts 已尝试添加的次数
+	Attempts int `gorm:"not null;default:0" json:"attempts"`
+	// NextRetryAt 添加失败后下次重试的时间，成功或不再重试时为空
+	NextRetryAt *time.Time `gorm:"index" json:"next_retry_at,omitempty"`
+}
+
+// RSSFetchResult 一次抓取的结果
+type RSSFetchResult struct {
+	FeedID uint `json:"feed_id"`
+	// Items 订阅源中的条目数，Grabbed 为本次新处理的条目
+	Items   int       `json:"items"`
+	Grabbed []RSSItem `json:"grabbed"`
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScheduleRule 带宽调度规则
+// 在每周指定的时间窗口内为客户端应用全局速度限制或备用速度模式
+type ScheduleRule struct {
+	gorm.Model
+	// Name 规则名称
+	Name string `gorm:"not null" json:"name"`
+	// ClientID 目标客户端，为空表示作用于所有客户端
+	ClientID string `gorm:"index" json:"client_id"`
+	// Days 生效的星期（0=周日 ... 6=周六），逗号分隔，为空表示每天
+	Days string `json:"days"`
+	// StartTime 窗口开始时间，格式 HH:MM
+	StartTime string `gorm:"not null" json:"start_time"`
+	// EndTime 窗口结束时间，格式 HH:MM；早于开始时间表示跨越午夜
+	EndTime string `gorm:"not null" json:"end_time"`
+	// DownloadLimit 窗口内的全局下载限速（字节/秒），为空表示不修改
+	DownloadLimit *int64 `json:"download_limit"`
+	// UploadLimit 窗口内的全局上传限速（字节/秒），为空表示不修改
+	UploadLimit *int64 `json:"upload_limit"`
+	// AltSpeedEnabled 窗口内是否启用备用速度，为空表示不修改
+	AltSpeedEnabled *bool `json:"alt_speed_enabled"`
+	// Enabled 是否启用该规则
+	Enabled bool `json:"enabled"`
+}
+
+// ScheduleBaseline 客户端进入调度窗口前的全局限制，窗口结束后恢复
+// 保存在数据库中，服务在窗口内重启后不会把规则下发的限制误当作原有限制
+type ScheduleBaseline struct {
+	ClientID       string `gorm:"primaryKey"`
+	TransferLimits `gorm:"embedded"`
+}
+
+// Validate 校验规则字段是否合法
+func (r *ScheduleRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if _, err := parseClock(r.StartTime); err != nil {
+		return fmt.Errorf("invalid start_time: %w", err)
+	}
+	if _, err := parseClock(r.EndTime); err != nil {
+		return fmt.Errorf("invalid end_time: %w", err)
+	}
+	if r.StartTime == r.EndTime {
+		return fmt.Errorf("start_time and end_time must differ")
+	}
+	if _, err := r.weekdays(); err != nil {
+		return err
+	}
+	if r.DownloadLimit == nil && r.UploadLimit == nil && r.AltSpeedEnabled == nil {
+		return fmt.Errorf("at least one of download_limit, upload_limit or alt_speed_enabled is required")
+	}
+	if (r.DownloadLimit != nil && *r.DownloadLimit < 0) || (r.UploadLimit != nil && *r.UploadLimit < 0) {
+		return fmt.Errorf("limits must not be negative")
+	}
+	return nil
+}
+
+// Matches 判断给定时间是否落在规则的时间窗口内
+func (r *ScheduleRule) Matches(t time.Time) bool {
+	start, err := parseClock(r.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(r.EndTime)
+	if err != nil {
+		return false
+	}
+	days, err := r.weekdays()
+	if err != nil {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	today := t.Weekday()
+
+	if start < end {
+		return days[today] && minute >= start && minute < end
+	}
+
+	// 跨越午夜的窗口：午夜之后的部分属于前一天的规则
+	yesterday := (today + 6) % 7
+	return (days[today] && minute >= start) || (days[yesterday] && minute < end)
+}
+
+// Apply 将规则中设置的字段覆盖到给定的限制上
+func (r *ScheduleRule) Apply(limits TransferLimits) TransferLimits {
+	if r.DownloadLimit != nil {
+		limits.DownloadLimit = *r.DownloadLimit
+	}
+	if r.UploadLimit != nil {
+		limits.UploadLimit = *r.UploadLimit
+	}
+	if r.AltSpeedEnabled != nil {
+		limits.AltSpeedEnabled = *r.AltSpeedEnabled
+	}
+	return limits
+}
+
+// weekdays 解析 Days 字段，返回每个星期是否生效
+func (r *ScheduleRule) weekdays() ([7]bool, error) {
+	var days [7]bool
+	if strings.TrimSpace(r.Days) == "" {
+		for i := range days {
+			days[i] = true
+		}
+		return days, nil
+	}
+
+	for _, part := range strings.Split(r.Days, ",") {
+		day, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || day < 0 || day > 6 {
+			return days, fmt.Errorf("invalid days: %q", r.Days)
+		}
+		days[day] = true
+	}
+	return days, nil
+}
+
+// parseClock 解析 HH:MM 格式的时间，返回自午夜起的分钟数
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleRuleMatches(t *testing.T) {
+	// 2026-10-19 为周一
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 19, hour, minute, 0, 0, time.Local)
+	}
+	tests := []struct {
+		name  string
+		rule  ScheduleRule
+		time  time.Time
+		match bool
+	}{
+		{"inside window", ScheduleRule{StartTime: "09:00", EndTime: "17:00"}, monday(12, 0), true},
+		{"at start", ScheduleRule{StartTime: "09:00", EndTime: "17:00"}, monday(9, 0), true},
+		{"at end", ScheduleRule{StartTime: "09:00", EndTime: "17:00"}, monday(17, 0), false},
+		{"before start", ScheduleRule{StartTime: "09:00", EndTime: "17:00"}, monday(8, 59), false},
+		{"listed day", ScheduleRule{Days: "1, 3", StartTime: "09:00", EndTime: "17:00"}, monday(12, 0), true},
+		{"other day", ScheduleRule{Days: "0,6", StartTime: "09:00", EndTime: "17:00"}, monday(12, 0), false},
+		{"overnight before midnight", ScheduleRule{Days: "1", StartTime: "23:00", EndTime: "06:00"}, monday(23, 30), true},
+		{"overnight after midnight belongs to the previous day", ScheduleRule{Days: "0", StartTime: "23:00", EndTime: "06:00"}, monday(5, 0), true},
+		{"overnight after midnight of an unlisted day", ScheduleRule{Days: "1", StartTime: "23:00", EndTime: "06:00"}, monday(5, 0), false},
+		{"overnight outside", ScheduleRule{StartTime: "23:00", EndTime: "06:00"}, monday(12, 0), false},
+		{"invalid days", ScheduleRule{Days: "7", StartTime: "09:00", EndTime: "17:00"}, monday(12, 0), false},
+		{"invalid time", ScheduleRule{StartTime: "9am", EndTime: "17:00"}, monday(12, 0), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.rule.Matches(test.time); got != test.match {
+				t.Errorf("Matches(%s) = %t, want %t", test.time, got, test.match)
+			}
+		})
+	}
+}
+
+func TestScheduleRuleValidate(t *testing.T) {
+	limit := int64(100)
+	negative := int64(-1)
+	alt := true
+	tests := []struct {
+		name    string
+		rule    ScheduleRule
+		wantErr bool
+	}{
+		{"valid", ScheduleRule{Name: "night", StartTime: "23:00", EndTime: "06:00", DownloadLimit: &limit}, false},
+		{"alt speed only", ScheduleRule{Name: "night", Days: "0,6", StartTime: "23:00", EndTime: "06:00", AltSpeedEnabled: &alt}, false},
+		{"missing name", ScheduleRule{StartTime: "23:00", EndTime: "06:00", DownloadLimit: &limit}, true},
+		{"invalid start", ScheduleRule{Name: "night", StartTime: "25:00", EndTime: "06:00", DownloadLimit: &limit}, true},
+		{"same start and end", ScheduleRule{Name: "night", StartTime: "06:00", EndTime: "06:00", DownloadLimit: &limit}, true},
+		{"invalid days", ScheduleRule{Name: "night", Days: "mon", StartTime: "23:00", EndTime: "06:00", DownloadLimit: &limit}, true},
+		{"nothing to change", ScheduleRule{Name: "night", StartTime: "23:00", EndTime: "06:00"}, true},
+		{"negative limit", ScheduleRule{Name: "night", StartTime: "23:00", EndTime: "06:00", UploadLimit: &negative}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.rule.Validate(); (err != nil) != test.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %t", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleRuleApply(t *testing.T) {
+	download := int64(100)
+	alt := true
+	base := TransferLimits{DownloadLimit: 1000, UploadLimit: 2000}
+	tests := []struct {
+		name string
+		rule ScheduleRule
+		want TransferLimits
+	}{
+		{"download only", ScheduleRule{DownloadLimit: &download}, TransferLimits{DownloadLimit: 100, UploadLimit: 2000}},
+		{"alt speed only", ScheduleRule{AltSpeedEnabled: &alt}, TransferLimits{DownloadLimit: 1000, UploadLimit: 2000, AltSpeedEnabled: true}},
+		{"nothing set", ScheduleRule{}, base},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.rule.Apply(base); got != test.want {
+				t.Errorf("Apply() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
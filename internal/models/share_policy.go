@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ShareLimitAction 达到分享目标后执行的动作
+type ShareLimitAction string
+
+const (
+	// ShareLimitActionPause 暂停种子
+	ShareLimitActionPause ShareLimitAction = "pause"
+	// ShareLimitActionRemove 删除种子，保留数据
+	ShareLimitActionRemove ShareLimitAction = "remove"
+	// ShareLimitActionRemoveWithData 删除种子及数据
+	ShareLimitActionRemoveWithData ShareLimitAction = "remove_with_data"
+)
+
+// ShareLimits 分享率与做种时间限制
+// 两个限制都为空表示使用客户端自身的全局设置
+type ShareLimits struct {
+	// RatioLimit 分享率上限
+	RatioLimit *float64 `json:"ratio_limit"`
+	// SeedingTimeLimit 做种时间上限（分钟）
+	SeedingTimeLimit *int64 `json:"seeding_time_limit"`
+	// Action 达到任一限制后执行的动作
+	Action ShareLimitAction `json:"action"`
+}
+
+// IsEmpty 是否未设置任何限制
+func (l ShareLimits) IsEmpty() bool {
+	return l.RatioLimit == nil && l.SeedingTimeLimit == nil
+}
+
+// Validate 校验限制是否合法
+func (l ShareLimits) Validate() error {
+	if l.IsEmpty() {
+		return fmt.Errorf("at least one of ratio_limit or seeding_time_limit is required")
+	}
+	if l.RatioLimit != nil && *l.RatioLimit < 0 {
+		return fmt.Errorf("ratio_limit must not be negative")
+	}
+	if l.SeedingTimeLimit != nil && *l.SeedingTimeLimit < 0 {
+		return fmt.Errorf("seeding_time_limit must not be negative")
+	}
+	switch l.Action {
+	case ShareLimitActionPause, ShareLimitActionRemove, ShareLimitActionRemoveWithData:
+		return nil
+	default:
+		return fmt.Errorf("invalid action: %q", l.Action)
+	}
+}
+
+// Reached 判断已完成的种子是否达到限制
+func (l ShareLimits) Reached(torrent UnifiedTorrent) bool {
+	if torrent.Progress < 1 {
+		return false
+	}
+	if l.RatioLimit != nil && torrent.Ratio >= *l.RatioLimit {
+		return true
+	}
+	if l.SeedingTimeLimit != nil && torrent.SeedingTime >= *l.SeedingTimeLimit*60 {
+		return true
+	}
+	return false
+}
+
+// SharePolicy 分享目标策略
+// 作用于单个种子（ClientID + Hash）或某个分类（Category），种子级策略优先
+type SharePolicy struct {
+	gorm.Model
+	ShareLimits
+	// ClientID 与 Hash 一起指定单个种子
+	ClientID string `gorm:"uniqueIndex:idx_share_policy_scope" json:"client_id,omitempty"`
+	// Hash 种子哈希
+	Hash string `gorm:"uniqueIndex:idx_share_policy_scope" json:"hash,omitempty"`
+	// Category 分类名称
+	Category string `gorm:"uniqueIndex:idx_share_policy_scope" json:"category,omitempty"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "testing"
+
+func TestShareLimitsValidate(t *testing.T) {
+	ratio := 2.0
+	negativeRatio := -1.0
+	minutes := int64(60)
+	tests := []struct {
+		name    string
+		limits  ShareLimits
+		wantErr bool
+	}{
+		{"ratio", ShareLimits{RatioLimit: &ratio, Action: ShareLimitActionPause}, false},
+		{"seeding time", ShareLimits{SeedingTimeLimit: &minutes, Action: ShareLimitActionRemoveWithData}, false},
+		{"no limits", ShareLimits{Action: ShareLimitActionPause}, true},
+		{"negative ratio", ShareLimits{RatioLimit: &negativeRatio, Action: ShareLimitActionPause}, true},
+		{"missing action", ShareLimits{RatioLimit: &ratio}, true},
+		{"unknown action", ShareLimits{RatioLimit: &ratio, Action: "stop"}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.limits.Validate(); (err != nil) != test.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %t", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestShareLimitsReached(t *testing.T) {
+	ratio := 2.0
+	minutes := int64(60)
+	limits := ShareLimits{RatioLimit: &ratio, SeedingTimeLimit: &minutes}
+	tests := []struct {
+		name    string
+		torrent UnifiedTorrent
+		want    bool
+	}{
+		{"ratio reached", UnifiedTorrent{Progress: 1, Ratio: 2}, true},
+		{"seeding time reached", UnifiedTorrent{Progress: 1, SeedingTime: 3600}, true},
+		{"neither reached", UnifiedTorrent{Progress: 1, Ratio: 1.9, SeedingTime: 3599}, false},
+		{"incomplete torrent", UnifiedTorrent{Progress: 0.5, Ratio: 3, SeedingTime: 7200}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := limits.Reached(test.torrent); got != test.want {
+				t.Errorf("Reached() = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
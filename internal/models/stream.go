@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+)
+
+// 种子更新消息类型
+const (
+	// TorrentUpdateSnapshot 全量快照，订阅者应以 added 替换本地全部数据
+	TorrentUpdateSnapshot = "snapshot"
+	// TorrentUpdateDiff 增量变更
+	TorrentUpdateDiff = "diff"
+)
+
+// TorrentUpdate 推送给订阅者的种子更新
+type TorrentUpdate struct {
+	Type      string           `json:"type"`
+	Timestamp time.Time        `json:"timestamp"`
+	Added     []UnifiedTorrent `json:"added"`
+	Removed   []TorrentRef     `json:"removed"`
+	Changed   []TorrentChange  `json:"changed"`
+}
+
+// IsEmpty 增量更新中是否没有任何变化
+func (u TorrentUpdate) IsEmpty() bool {
+	return len(u.Added) == 0 && len(u.Removed) == 0 && len(u.Changed) == 0
+}
+
+// TorrentChange 单个种子发生变化的字段，键为 JSON 字段名
+type TorrentChange struct {
+	ClientID string                 `json:"client_id"`
+	Hash     string                 `json:"hash"`
+	Fields   map[string]interface{} `json:"fields"`
+}
+
+// TorrentFilter 种子过滤条件，空字段表示不过滤
+type TorrentFilter struct {
+	ClientIDs []string
+	Category  string
+	State     string
+	AddedBy   string
+}
+
+// Match 判断种子是否满足过滤条件
+func (f TorrentFilter) Match(torrent UnifiedTorrent) bool {
+	if len(f.ClientIDs) > 0 {
+		found := false
+		for _, clientID := range f.ClientIDs {
+			if clientID == torrent.ClientID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Category != "" && f.Category != torrent.Category {
+		return false
+	}
+	if f.State != "" && f.State != torrent.State {
+		return false
+	}
+	if f.AddedBy != "" && f.AddedBy != torrent.AddedBy {
+		return false
+	}
+	return true
+}
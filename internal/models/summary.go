@@ -0,0 +1,77 @@
+package models
+
+import (
+	"strings"
+)
+
+// 归一化后的种子状态
+const (
+	StateDownloading = "downloading"
+	StateSeeding     = "seeding"
+	StatePaused      = "paused"
+	StateQueued      = "queued"
+	StateChecking    = "checking"
+	StateStalled     = "stalled"
+	StateError       = "error"
+	StateUnknown     = "unknown"
+)
+
+// NormalizedStates 所有归一化状态
+var NormalizedStates = []string{
+	StateDownloading,
+	StateSeeding,
+	StatePaused,
+	StateQueued,
+	StateChecking,
+	StateStalled,
+	StateError,
+	StateUnknown,
+}
+
+// NormalizeState 将各客户端的原始状态映射为统一状态
+// 兼容 qBittorrent 的状态名（如 stalledUP、pausedDL）与 Transmission 的状态描述（如 waiting to seed）
+func NormalizeState(state string) string {
+	s := strings.ToLower(state)
+	switch {
+	case strings.HasPrefix(s, "paused"), strings.HasPrefix(s, "stopped"):
+		return StatePaused
+	// queuedForChecking 是等待校验而非排队下载，需先于 queued 前缀判断
+	case strings.HasPrefix(s, "checking"), strings.HasPrefix(s, "waiting to check"), s == "queuedforchecking", s == "allocating", s == "moving":
+		return StateChecking
+	case strings.HasPrefix(s, "queued"), strings.HasPrefix(s, "waiting to download"), strings.HasPrefix(s, "waiting to seed"):
+		return StateQueued
+	case strings.HasPrefix(s, "stalled"):
+		return StateStalled
+	case s == "error", s == "missingfiles":
+		return StateError
+	case s == "downloading", s == "forceddl", s == "metadl", s == "forcedmetadl":
+		return StateDownloading
+	case s == "seeding", s == "uploading", s == "forcedup":
+		return StateSeeding
+	default:
+		return StateUnknown
+	}
+}
+
+// ClientSummary 单个客户端（或全部客户端合计）的汇总信息
+// FreeSpace 与 AltSpeedEnabled 在客户端查询失败时为空
+type ClientSummary struct {
+	ClientID        string         `json:"client_id,omitempty"`
+	DownloadSpeed   int64          `json:"download_speed"`
+	UploadSpeed     int64          `json:"upload_speed"`
+	TorrentCount    int            `json:"torrent_count"`
+	StateCounts     map[string]int `json:"state_counts"`
+	TotalSize       int64          `json:"total_size"`
+	Downloaded      int64          `json:"downloaded"`
+	Uploaded        int64          `json:"uploaded"`
+	Ratio           float64        `json:"ratio"`
+	FreeSpace       *int64         `json:"free_space,omitempty"`
+	AltSpeedEnabled *bool          `json:"alt_speed_enabled,omitempty"`
+	Errors          []string       `json:"errors,omitempty"`
+}
+
+// StatsSummary 仪表盘汇总
+type StatsSummary struct {
+	Total   ClientSummary   `json:"total"`
+	Clients []ClientSummary `json:"clients"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "testing"
+
+func TestNormalizeState(t *testing.T) {
+	tests := []struct {
+		state string
+		want  string
+	}{
+		// qBittorrent
+		{"downloading", StateDownloading},
+		{"forcedDL", StateDownloading},
+		{"metaDL", StateDownloading},
+		{"forcedMetaDL", StateDownloading},
+		{"uploading", StateSeeding},
+		{"forcedUP", StateSeeding},
+		{"pausedDL", StatePaused},
+		{"pausedUP", StatePaused},
+		{"stoppedDL", StatePaused},
+		{"stoppedUP", StatePaused},
+		{"queuedDL", StateQueued},
+		{"queuedUP", StateQueued},
+		{"queuedForChecking", StateChecking},
+		{"checkingDL", StateChecking},
+		{"checkingUP", StateChecking},
+		{"checkingResumeData", StateChecking},
+		{"allocating", StateChecking},
+		{"moving", StateChecking},
+		{"stalledDL", StateStalled},
+		{"stalledUP", StateStalled},
+		{"error", StateError},
+		{"missingFiles", StateError},
+		// Transmission
+		{"stopped", StatePaused},
+		{"waiting to check files", StateChecking},
+		{"checking files", StateChecking},
+		{"waiting to download", StateQueued},
+		{"downloading", StateDownloading},
+		{"waiting to seed", StateQueued},
+		{"seeding", StateSeeding},
+		// 未知状态
+		{"", StateUnknown},
+		{"something else", StateUnknown},
+	}
+	for _, test := range tests {
+		t.Run(test.state, func(t *testing.T) {
+			if got := NormalizeState(test.state); got != test.want {
+				t.Errorf("NormalizeState(%q) = %q, want %q", test.state, got, test.want)
+			}
+		})
+	}
+}
@@ -1,16 +1,106 @@
 package models
 
+import (
+	"time"
+)
+
 // UnifiedTorrent 统一种子模型
 type UnifiedTorrent struct {
-	ClientID      string  `json:"client_id"`
-	Name          string  `json:"name"`
-	Hash          string  `json:"hash"`
-	Size          int64   `json:"size"`
-	State         string  `json:"state"`
-	Progress      float64 `json:"progress"`
-	DownloadSpeed int64   `json:"download_speed"`
-	UploadSpeed   int64   `json:"upload_speed"`
-	Downloaded    int64   `json:"downloaded"`
-	Uploaded      int64   `json:"uploaded"`
-	ETA           int64   `json:"eta"`
-}
\ No newline at end of file
+	ClientID      string   `json:"client_id"`
+	Name          string   `json:"name"`
+	Hash          string   `json:"hash"`
+	Size          int64    `json:"size"`
+	State         string   `json:"state"`
+	Progress      float64  `json:"progress"`
+	DownloadSpeed int64    `json:"download_speed"`
+	UploadSpeed   int64    `json:"upload_speed"`
+	Downloaded    int64    `json:"downloaded"`
+	Uploaded      int64    `json:"uploaded"`
+	ETA           int64    `json:"eta"`
+	Category      string   `json:"category"`
+	Tags          []string `json:"tags"`
+	Ratio         float64  `json:"ratio"`
+	SeedingTime   int64    `json:"seeding_time"`
+	// Tracker 当前使用的 tracker 地址，未知时为空
+	Tracker string `json:"tracker,omitempty"`
+	// SavePath 客户端上的实际保存目录，ContentPath 为种子内容（单文件或根目录）的完整路径
+	SavePath    string `json:"save_path,omitempty"`
+	ContentPath string `json:"content_path,omitempty"`
+	// AddedOn 添加到客户端的时间（Unix 秒）
+	AddedOn int64 `json:"added_on"`
+	// AddedBy 通过 Down-Nexus 添加该种子的用户名，未知时为空
+	AddedBy string `json:"added_by,omitempty"`
+}
+
+// TorrentRef 通过客户端 ID 和哈希定位一个种子
+type TorrentRef struct {
+	ClientID string `json:"client_id"`
+	Hash     string `json:"hash"`
+}
+
+// TorrentSync 一次同步的结果
+// Full 为 true 时 Torrents 为客户端的全部种子，否则只包含发生变化的种子，Removed 为已删除种子的哈希
+type TorrentSync struct {
+	Full     bool
+	Torrents []UnifiedTorrent
+	Removed  []string
+}
+
+// ClientFreshness 客户端快照的新鲜度
+type ClientFreshness struct {
+	ClientID string `json:"client_id"`
+	// UpdatedAt 最近一次成功同步的时间，从未成功时为空
+	UpdatedAt *time.Time `json:"updated_at"`
+	// Error 最近一次同步的错误，此时返回的是过期数据
+	Error string `json:"error,omitempty"`
+}
+
+// DuplicatePolicy 添加已存在于其他客户端的种子时的处理方式
+type DuplicatePolicy string
+
+const (
+	// DuplicateAllow 不检查重复
+	DuplicateAllow DuplicatePolicy = "allow"
+	// DuplicateWarn 照常添加，响应中给出已存在的副本
+	DuplicateWarn DuplicatePolicy = "warn"
+	// DuplicateRefuse 拒绝添加
+	DuplicateRefuse DuplicatePolicy = "refuse"
+)
+
+// DuplicateTorrent 同一 info-hash 存在于多个客户端上的种子
+type DuplicateTorrent struct {
+	Hash string `json:"hash"`
+	Name string `json:"name"`
+	// Copies 各客户端上的副本及其状态
+	Copies []UnifiedTorrent `json:"copies"`
+}
+
+// TorrentExport 从客户端导出的种子
+type TorrentExport struct {
+	// MetaInfo .torrent 文件内容
+	MetaInfo []byte
+	// SavePath 种子数据所在目录，即客户端中的保存路径
+	SavePath string
+}
+
+// ImportOptions 导入 .torrent 文件时的选项，用于迁移和辅种已有数据
+type ImportOptions struct {
+	// SavePath 保存路径，为空时使用客户端的默认路径
+	SavePath string
+	// SkipChecking 跳过哈希校验，直接视为已完成；不支持的客户端会照常校验
+	SkipChecking bool
+	// Paused 添加后保持暂停
+	Paused bool
+}
+
+// TorrentFile 种子中的一个文件，Path 以 / 分隔并包含种子的根目录
+type TorrentFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// TorrentContent 客户端中种子的保存路径与文件列表
+type TorrentContent struct {
+	SavePath string        `json:"save_path"`
+	Files    []TorrentFile `json:"files"`
+}
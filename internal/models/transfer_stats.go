@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+// TransferStats 客户端当前的传输计数器与速度
+// 计数器为客户端本次会话的累计字节数，客户端重启后会归零
+type TransferStats struct {
+	Downloaded    int64 `json:"downloaded"`
+	Uploaded      int64 `json:"uploaded"`
+	DownloadSpeed int64 `json:"download_speed"`
+	UploadSpeed   int64 `json:"upload_speed"`
+}
+
+// 统计粒度
+const (
+	StatGranularityHour = "hour"
+	StatGranularityDay  = "day"
+)
+
+// TransferSample 传输计数器原始采样
+// Hash 为空表示客户端级别的计数器；Delta 为与上一次采样相比的增量，已处理计数器归零
+type TransferSample struct {
+	ID              uint      `gorm:"primarykey" json:"-"`
+	ClientID        string    `gorm:"index:idx_sample_series;not null" json:"client_id"`
+	Hash            string    `gorm:"index:idx_sample_series;not null;default:''" json:"hash,omitempty"`
+	SampledAt       time.Time `gorm:"index;not null" json:"sampled_at"`
+	Downloaded      int64     `json:"downloaded"`
+	Uploaded        int64     `json:"uploaded"`
+	DownloadedDelta int64     `json:"downloaded_delta"`
+	UploadedDelta   int64     `json:"uploaded_delta"`
+}
+
+// TransferStat 按小时或天汇总的传输量
+type TransferStat struct {
+	ID          uint      `gorm:"primarykey" json:"-"`
+	ClientID    string    `gorm:"uniqueIndex:idx_stat_bucket;not null" json:"client_id"`
+	Hash        string    `gorm:"uniqueIndex:idx_stat_bucket;not null;default:''" json:"hash,omitempty"`
+	Granularity string    `gorm:"uniqueIndex:idx_stat_bucket;not null" json:"granularity"`
+	BucketStart time.Time `gorm:"uniqueIndex:idx_stat_bucket;not null" json:"bucket_start"`
+	Downloaded  int64     `json:"downloaded"`
+	Uploaded    int64     `json:"uploaded"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// User API 用户
+type User struct {
+	gorm.Model
+	// Username 登录用户名，全局唯一
+	Username string `gorm:"uniqueIndex;not null" json:"username"`
+	// PasswordHash bcrypt 密码哈希
+	PasswordHash string `gorm:"not null" json:"-"`
+	// Role 角色：admin、operator 或 viewer
+	Role string `gorm:"not null;default:viewer" json:"role"`
+	// Grants 按客户端的授权，为空表示角色权限作用于所有客户端
+	Grants []UserGrant `gorm:"constraint:OnDelete:CASCADE" json:"grants"`
+}
+
+// APIKey 供脚本使用的长期访问密钥
+// 只保存密钥的 SHA-256 哈希，明文仅在创建时返回一次
+type APIKey struct {
+	gorm.Model
+	UserID uint   `gorm:"index;not null" json:"user_id"`
+	Name   string `gorm:"not null" json:"name"`
+	// Prefix 密钥开头的若干字符，便于用户辨认
+	Prefix     string     `gorm:"not null" json:"prefix"`
+	KeyHash    string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// RefreshToken 刷新令牌
+// 每次刷新都会吊销旧令牌并签发新令牌，只保存令牌的 SHA-256 哈希
+type RefreshToken struct {
+	ID        uint      `gorm:"primarykey"`
+	UserID    uint      `gorm:"index;not null"`
+	TokenHash string    `gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// TokenPair 登录或刷新后签发的令牌
+type TokenPair struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 监视目录中已处理文件移入的子目录
+const (
+	WatchDoneDir   = "done"
+	WatchFailedDir = "failed"
+)
+
+// WatchFolder 监视目录，放入其中的 .torrent 与 .magnet 文件会被添加到指定客户端
+type WatchFolder struct {
+	gorm.Model
+	// Path 监视的目录，必须为绝对路径
+	Path string `gorm:"uniqueIndex;not null" json:"path"`
+	// ClientID 目标客户端，为空时按目标客户端选择策略选择
+	ClientID string `json:"client_id"`
+	// Category 添加后设置的分类，为空表示不设置
+	Category string `json:"category"`
+	// Poll 使用轮询代替文件系统事件，用于 NFS、SMB 等不支持 inotify 的网络挂载
+	Poll bool `json:"poll"`
+	// Enabled 是否启用该目录
+	Enabled bool `json:"enabled"`
+}
+
+// Validate 校验监视目录字段是否合法
+func (f *WatchFolder) Validate() error {
+	if f.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if !filepath.IsAbs(f.Path) {
+		return fmt.Errorf("path must be absolute")
+	}
+	f.Path = filepath.Clean(f.Path)
+	return nil
+}
+
+// WatchIngestion 监视目录中一个文件的处理结果，只追加不修改
+type WatchIngestion struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index;not null" json:"created_at"`
+	FolderID  uint      `gorm:"index;not null" json:"folder_id"`
+	// File 原始文件名，MovedTo 为处理后移入 done 或 failed 子目录的路径
+	File     string `gorm:"not null" json:"file"`
+	MovedTo  string `json:"moved_to,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+	Success  bool   `gorm:"not null" json:"success"`
+	Error    string `gorm:"type:text" json:"error,omitempty"`
+}
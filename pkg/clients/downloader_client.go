@@ -1,12 +1,61 @@
 package clients
 
-import "down-nexus-api/internal/models"
+import (
+	"errors"
+
+	"down-nexus-api/internal/models"
+)
+
+// ErrCrossDirectoryRename 客户端只支持在同一目录内重命名文件
+var ErrCrossDirectoryRename = errors.New("client can only rename within the same directory")
 
 type DownloaderClient interface {
 	GetTorrents() ([]models.UnifiedTorrent, error)
-	AddTorrent(magnetURL string) error
+	// AddTorrent 添加种子，返回种子的 info-hash，客户端无法确定时返回空字符串
+	AddTorrent(magnetURL string) (string, error)
+	// AddTorrentFile 通过 .torrent 文件内容添加种子，返回种子的 info-hash
+	AddTorrentFile(data []byte) (string, error)
 	PauseTorrent(hash string) error
 	ResumeTorrent(hash string) error
 	DeleteTorrent(hash string, deleteFiles bool) error
 	GetClientID() string
+
+	// SyncTorrents 增量同步种子列表，full 为 true 时强制全量同步
+	SyncTorrents(full bool) (models.TorrentSync, error)
+
+	// GetTransferStats 获取客户端本次会话的传输计数器与当前速度
+	GetTransferStats() (models.TransferStats, error)
+
+	// GetFreeSpace 获取默认保存路径所在磁盘的剩余空间（字节）
+	GetFreeSpace() (int64, error)
+
+	// 速度限制，单位为字节/秒，0 表示不限速
+	GetTransferLimits() (models.TransferLimits, error)
+	SetTransferLimits(limits models.TransferLimits) error
+	SetTorrentLimits(hash string, downloadLimit, uploadLimit int64) error
+
+	// 分类与标签，category 为空表示取消分类
+	EnsureCategory(name, savePath string) error
+	SetCategory(hashes []string, category string) error
+	AddTags(hashes []string, tags []string) error
+	RemoveTags(hashes []string, tags []string) error
+
+	// 分享目标，返回客户端是否会自行执行该限制
+	// 空限制表示恢复使用客户端的全局设置
+	SetShareLimits(hashes []string, limits models.ShareLimits) (bool, error)
+
+	// 重命名，路径均相对于种子根目录，以 / 分隔
+	RenameTorrent(hash, name string) error
+	RenameFile(hash, oldPath, newPath string) error
+
+	// 导出与导入 .torrent 文件，用于在客户端之间迁移种子
+	ExportTorrent(hash string) (models.TorrentExport, error)
+	ImportTorrent(data []byte, options models.ImportOptions) (string, error)
+	// RecheckTorrent 重新校验种子数据
+	RecheckTorrent(hash string) error
+	// MoveTorrent 将种子数据移动到 location 并更新保存路径
+	MoveTorrent(hash, location string) error
+
+	// GetTorrentFiles 获取种子的保存路径与文件列表，用于辅种匹配
+	GetTorrentFiles(hash string) (models.TorrentContent, error)
 }
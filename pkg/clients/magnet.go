@@ -0,0 +1,44 @@
+package clients
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// MagnetInfoHash 从磁力链接中解析 v1 info-hash，返回小写十六进制
+// 支持 40 位十六进制与 32 位 base32 两种编码，无法解析时返回空字符串
+func MagnetInfoHash(magnetURL string) string {
+	u, err := url.Parse(magnetURL)
+	if err != nil || u.Scheme != "magnet" {
+		return ""
+	}
+
+	for _, xt := range u.Query()["xt"] {
+		value, ok := strings.CutPrefix(strings.ToLower(xt), "urn:btih:")
+		if !ok {
+			continue
+		}
+		switch len(value) {
+		case 40:
+			if _, err := hex.DecodeString(value); err == nil {
+				return value
+			}
+		case 32:
+			if decoded, err := base32.StdEncoding.DecodeString(strings.ToUpper(value)); err == nil {
+				return hex.EncodeToString(decoded)
+			}
+		}
+	}
+	return ""
+}
+
+// MagnetTrackers 返回磁力链接中 tr 参数指定的 tracker 地址，不是磁力链接时返回空
+func MagnetTrackers(magnetURL string) []string {
+	u, err := url.Parse(magnetURL)
+	if err != nil || u.Scheme != "magnet" {
+		return nil
+	}
+	return u.Query()["tr"]
+}
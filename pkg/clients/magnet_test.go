@@ -0,0 +1,50 @@
+package clients
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMagnetInfoHash(t *testing.T) {
+	const hash = "1111111111111111111111111111111111111111"
+	tests := []struct {
+		name      string
+		magnetURL string
+		want      string
+	}{
+		{"hex", "magnet:?xt=urn:btih:" + hash + "&dn=test", hash},
+		{"uppercase hex", "magnet:?xt=urn:btih:ABCDEFABCDEFABCDEFABCDEFABCDEFABCDEFABCD", "abcdefabcdefabcdefabcdefabcdefabcdefabcd"},
+		{"base32", "magnet:?xt=urn:btih:CEIRCEIRCEIRCEIRCEIRCEIRCEIRCEIR", hash},
+		{"v1 after v2", "magnet:?xt=urn:btmh:1220abcd&xt=urn:btih:" + hash, hash},
+		{"v2 only", "magnet:?xt=urn:btmh:1220abcd", ""},
+		{"invalid hex", "magnet:?xt=urn:btih:" + "zz" + hash[2:], ""},
+		{"wrong length", "magnet:?xt=urn:btih:1234", ""},
+		{"not a magnet link", "https://example.com/file.torrent", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := MagnetInfoHash(test.magnetURL); got != test.want {
+				t.Errorf("MagnetInfoHash(%q) = %q, want %q", test.magnetURL, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMagnetTrackers(t *testing.T) {
+	tests := []struct {
+		name      string
+		magnetURL string
+		want      []string
+	}{
+		{"trackers", "magnet:?xt=urn:btih:abc&tr=udp%3A%2F%2Fa%3A80&tr=http://b/announce", []string{"udp://a:80", "http://b/announce"}},
+		{"no trackers", "magnet:?xt=urn:btih:abc", nil},
+		{"not a magnet link", "https://example.com/file.torrent?tr=http://a", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := MagnetTrackers(test.magnetURL); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("MagnetTrackers(%q) = %v, want %v", test.magnetURL, got, test.want)
+			}
+		})
+	}
+}
@@ -1,14 +1,31 @@
 package qbittorrent
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
 	qb "github.com/autobrr/go-qbittorrent"
 )
 
 type QbitClient struct {
 	client   *qb.Client
 	clientID string
+
+	// 与 go-qbittorrent 共享 Cookie 的 HTTP 客户端，用于库未覆盖的请求
+	host       string
+	httpClient *http.Client
+
+	mutex sync.Mutex
+	// rid 与 syncTorrents 为 sync/maindata 增量同步的状态
+	rid          int64
+	syncTorrents map[string]qb.Torrent
 }
 
 func NewQbitClient(host, username, password, clientID string) (*QbitClient, error) {
@@ -18,7 +35,8 @@ func NewQbitClient(host, username, password, clientID string) (*QbitClient, erro
 		Username: username,
 		Password: password,
 	}
-	qbClient := qb.NewClient(cfg)
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	qbClient := qb.NewClient(cfg).WithHTTPClient(httpClient)
 	
 	// Login to qBittorrent
 	err := qbClient.Login()
@@ -27,8 +45,10 @@ func NewQbitClient(host, username, password, clientID string) (*QbitClient, erro
 	}
 	
 	return &QbitClient{
-		client:   qbClient,
-		clientID: clientID,
+		client:     qbClient,
+		clientID:   clientID,
+		host:       host,
+		httpClient: httpClient,
 	}, nil
 }
 
@@ -40,30 +60,57 @@ func (qc *QbitClient) GetTorrents() ([]models.UnifiedTorrent, error) {
 	
 	var unifiedTorrents []models.UnifiedTorrent
 	for _, torrent := range torrents {
-		unifiedTorrent := models.UnifiedTorrent{
-			ClientID:      qc.clientID,
-			Name:          torrent.Name,
-			Hash:          torrent.Hash,
-			Size:          torrent.Size,
-			State:         string(torrent.State),
-			Progress:      torrent.Progress,
-			DownloadSpeed: torrent.DlSpeed,
-			UploadSpeed:   torrent.UpSpeed,
-			Downloaded:    torrent.Downloaded,
-			Uploaded:      torrent.Uploaded,
-			ETA:           torrent.ETA,
-		}
-		unifiedTorrents = append(unifiedTorrents, unifiedTorrent)
+		unifiedTorrents = append(unifiedTorrents, qc.toUnified(torrent))
 	}
 	
 	return unifiedTorrents, nil
 }
 
-func (qc *QbitClient) AddTorrent(magnetURL string) error {
+// toUnified 将 qBittorrent 种子转换为统一模型
+func (qc *QbitClient) toUnified(torrent qb.Torrent) models.UnifiedTorrent {
+	return models.UnifiedTorrent{
+		ClientID:      qc.clientID,
+		Name:          torrent.Name,
+		Hash:          torrent.Hash,
+		Size:          torrent.Size,
+		State:         string(torrent.State),
+		Progress:      torrent.Progress,
+		DownloadSpeed: torrent.DlSpeed,
+		UploadSpeed:   torrent.UpSpeed,
+		Downloaded:    torrent.Downloaded,
+		Uploaded:      torrent.Uploaded,
+		ETA:           torrent.ETA,
+		Category:      torrent.Category,
+		Tags:          splitTags(torrent.Tags),
+		Ratio:         torrent.Ratio,
+		SeedingTime:   torrent.SeedingTime,
+		Tracker:       torrent.Tracker,
+		SavePath:      torrent.SavePath,
+		ContentPath:   torrent.ContentPath,
+		AddedOn:       torrent.AddedOn,
+	}
+}
+
+func (qc *QbitClient) AddTorrent(magnetURL string) (string, error) {
 	// Use qBittorrent's AddTorrentFromUrl method
 	options := map[string]string{}
 	
-	return qc.client.AddTorrentFromUrl(magnetURL, options)
+	// qBittorrent 不返回新种子的哈希，只能从磁力链接中解析
+	if err := qc.client.AddTorrentFromUrl(magnetURL, options); err != nil {
+		return "", err
+	}
+	return clients.MagnetInfoHash(magnetURL), nil
+}
+
+func (qc *QbitClient) AddTorrentFile(data []byte) (string, error) {
+	hash, err := clients.TorrentFileInfoHash(data)
+	if err != nil {
+		return "", err
+	}
+	if err := qc.client.AddTorrentFromMemory(data, map[string]string{}); err != nil {
+		return "", err
+	}
+	return hash, nil
 }
 
 func (qc *QbitClient) PauseTorrent(hash string) error {
@@ -80,4 +127,342 @@ func (qc *QbitClient) DeleteTorrent(hash string, deleteFiles bool) error {
 
 func (qc *QbitClient) GetClientID() string {
 	return qc.clientID
+}
+
+func (qc *QbitClient) GetTransferStats() (models.TransferStats, error) {
+	info, err := qc.client.GetTransferInfo()
+	if err != nil {
+		return models.TransferStats{}, err
+	}
+
+	return models.TransferStats{
+		Downloaded:    info.DlInfoData,
+		Uploaded:      info.UpInfoData,
+		DownloadSpeed: info.DlInfoSpeed,
+		UploadSpeed:   info.UpInfoSpeed,
+	}, nil
+}
+
+func (qc *QbitClient) GetFreeSpace() (int64, error) {
+	return qc.client.GetFreeSpaceOnDisk()
+}
+
+func (qc *QbitClient) GetTransferLimits() (models.TransferLimits, error) {
+	info, err := qc.client.GetTransferInfo()
+	if err != nil {
+		return models.TransferLimits{}, err
+	}
+
+	altEnabled, err := qc.client.GetAlternativeSpeedLimitsMode()
+	if err != nil {
+		return models.TransferLimits{}, err
+	}
+
+	return models.TransferLimits{
+		DownloadLimit:   info.DlRateLimit,
+		UploadLimit:     info.UpRateLimit,
+		AltSpeedEnabled: altEnabled,
+	}, nil
+}
+
+func (qc *QbitClient) SetTransferLimits(limits models.TransferLimits) error {
+	if err := qc.client.SetGlobalDownloadLimit(limits.DownloadLimit); err != nil {
+		return err
+	}
+	if err := qc.client.SetGlobalUploadLimit(limits.UploadLimit); err != nil {
+		return err
+	}
+
+	// qBittorrent only exposes a toggle, so flip it only when the mode differs
+	altEnabled, err := qc.client.GetAlternativeSpeedLimitsMode()
+	if err != nil {
+		return err
+	}
+	if altEnabled != limits.AltSpeedEnabled {
+		return qc.client.ToggleAlternativeSpeedLimits()
+	}
+
+	return nil
+}
+
+func (qc *QbitClient) SetTorrentLimits(hash string, downloadLimit, uploadLimit int64) error {
+	if err := qc.client.SetTorrentDownloadLimit([]string{hash}, downloadLimit); err != nil {
+		return err
+	}
+	return qc.client.SetTorrentUploadLimit([]string{hash}, uploadLimit)
+}
+
+
+// EnsureCategory 创建分类，已存在时更新其保存路径
+func (qc *QbitClient) EnsureCategory(name, savePath string) error {
+	categories, err := qc.client.GetCategories()
+	if err != nil {
+		return err
+	}
+
+	if existing, ok := categories[name]; ok {
+		if existing.SavePath == savePath {
+			return nil
+		}
+		return qc.client.EditCategory(name, savePath)
+	}
+	return qc.client.CreateCategory(name, savePath)
+}
+
+func (qc *QbitClient) SetCategory(hashes []string, category string) error {
+	return qc.client.SetCategory(hashes, category)
+}
+
+func (qc *QbitClient) AddTags(hashes []string, tags []string) error {
+	return qc.client.AddTags(hashes, strings.Join(tags, ","))
+}
+
+func (qc *QbitClient) RemoveTags(hashes []string, tags []string) error {
+	return qc.client.RemoveTags(hashes, strings.Join(tags, ","))
+}
+
+// splitTags 将 qBittorrent 逗号分隔的标签字符串拆分为列表
+func splitTags(tags string) []string {
+	var result []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// qBittorrent 分享限制的特殊取值：-2 使用全局设置，-1 不限制
+const (
+	shareLimitGlobal    = -2
+	shareLimitUnlimited = -1
+)
+
+// qBittorrent 的 max_ratio_act 取值
+var shareLimitActions = map[models.ShareLimitAction]int{
+	models.ShareLimitActionPause:          0,
+	models.ShareLimitActionRemove:         1,
+	models.ShareLimitActionRemoveWithData: 3,
+}
+
+// SetShareLimits 设置种子的分享限制
+// qBittorrent 达到限制后的动作是全局设置，只有与其一致时才由客户端原生执行，
+// 否则取消该种子的限制，交由 Down-Nexus 执行
+func (qc *QbitClient) SetShareLimits(hashes []string, limits models.ShareLimits) (bool, error) {
+	if limits.IsEmpty() {
+		return false, qc.client.SetTorrentShareLimit(hashes, shareLimitGlobal, shareLimitGlobal, shareLimitGlobal)
+	}
+
+	prefs, err := qc.client.GetAppPreferences()
+	if err != nil {
+		return false, err
+	}
+
+	if act, ok := shareLimitActions[limits.Action]; !ok || act != prefs.MaxRatioAct {
+		return false, qc.client.SetTorrentShareLimit(hashes, shareLimitUnlimited, shareLimitUnlimited, shareLimitGlobal)
+	}
+
+	ratio := float64(shareLimitUnlimited)
+	if limits.RatioLimit != nil {
+		ratio = *limits.RatioLimit
+	}
+	seedingTime := int64(shareLimitUnlimited)
+	if limits.SeedingTimeLimit != nil {
+		seedingTime = *limits.SeedingTimeLimit
+	}
+
+	if err := qc.client.SetTorrentShareLimit(hashes, ratio, seedingTime, shareLimitGlobal); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (qc *QbitClient) RenameTorrent(hash, name string) error {
+	return qc.client.SetTorrentName(hash, name)
+}
+
+// RenameFile 重命名种子内的文件或文件夹
+// qBittorrent 对文件和文件夹使用不同的接口，需要先根据文件列表判断
+func (qc *QbitClient) RenameFile(hash, oldPath, newPath string) error {
+	files, err := qc.client.GetFilesInformation(hash)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range *files {
+		if file.Name == oldPath {
+			return qc.client.RenameFile(hash, oldPath, newPath)
+		}
+	}
+	for _, file := range *files {
+		if strings.HasPrefix(file.Name, oldPath+"/") {
+			return qc.client.RenameFolder(hash, oldPath, newPath)
+		}
+	}
+
+	return fmt.Errorf("path %s not found in torrent %s", oldPath, hash)
+}
+
+// ExportTorrent 导出种子的 .torrent 文件及保存路径
+func (qc *QbitClient) ExportTorrent(hash string) (models.TorrentExport, error) {
+	torrents, err := qc.client.GetTorrents(qb.TorrentFilterOptions{Hashes: []string{hash}})
+	if err != nil {
+		return models.TorrentExport{}, err
+	}
+	if len(torrents) == 0 {
+		return models.TorrentExport{}, fmt.Errorf("torrent with hash %s not found", hash)
+	}
+
+	data, err := qc.client.ExportTorrent(hash)
+	if err != nil {
+		return models.TorrentExport{}, err
+	}
+	// 导出接口出错时也可能返回 200 与错误文本，校验内容确实是该种子
+	if exported, err := clients.TorrentFileInfoHash(data); err != nil || !strings.EqualFold(exported, hash) {
+		return models.TorrentExport{}, fmt.Errorf("qBittorrent returned an invalid .torrent file for %s", hash)
+	}
+
+	return models.TorrentExport{MetaInfo: data, SavePath: torrents[0].SavePath}, nil
+}
+
+// ImportTorrent 按指定保存路径添加 .torrent 文件
+// 指定保存路径时关闭自动种子管理，否则 qBittorrent 会按分类路径覆盖；
+// 暂停参数在 qBittorrent 5.0 起更名为 stopped，两个都传以兼容新旧版本
+func (qc *QbitClient) ImportTorrent(data []byte, options models.ImportOptions) (string, error) {
+	hash, err := clients.TorrentFileInfoHash(data)
+	if err != nil {
+		return "", err
+	}
+
+	params := map[string]string{}
+	if options.SavePath != "" {
+		params["savepath"] = options.SavePath
+		params["autoTMM"] = "false"
+	}
+	if options.SkipChecking {
+		params["skip_checking"] = "true"
+	}
+	if options.Paused {
+		params["paused"] = "true"
+		params["stopped"] = "true"
+	}
+	if err := qc.client.AddTorrentFromMemory(data, params); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+func (qc *QbitClient) RecheckTorrent(hash string) error {
+	return qc.client.Recheck([]string{hash})
+}
+
+// MoveTorrent 移动种子数据，种子使用自动管理模式时 qBittorrent 会将其切换为手动模式
+func (qc *QbitClient) MoveTorrent(hash, location string) error {
+	return qc.client.SetLocation([]string{hash}, location)
+}
+
+// GetTorrentFiles 获取种子的保存路径与文件列表
+func (qc *QbitClient) GetTorrentFiles(hash string) (models.TorrentContent, error) {
+	torrents, err := qc.client.GetTorrents(qb.TorrentFilterOptions{Hashes: []string{hash}})
+	if err != nil {
+		return models.TorrentContent{}, err
+	}
+	if len(torrents) == 0 {
+		return models.TorrentContent{}, fmt.Errorf("torrent with hash %s not found", hash)
+	}
+
+	files, err := qc.client.GetFilesInformation(hash)
+	if err != nil {
+		return models.TorrentContent{}, err
+	}
+
+	content := models.TorrentContent{SavePath: torrents[0].SavePath}
+	for _, file := range *files {
+		content.Files = append(content.Files, models.TorrentFile{Path: file.Name, Size: file.Size})
+	}
+	return content, nil
+}
+
+// SyncTorrents 通过 sync/maindata 增量同步种子列表
+// 增量响应中的种子只包含变化的字段，需要在上一次的数据上合并，
+// go-qbittorrent 的 MainData.Update 会整体替换种子，因此这里直接解析原始 JSON
+func (qc *QbitClient) SyncTorrents(full bool) (models.TorrentSync, error) {
+	qc.mutex.Lock()
+	defer qc.mutex.Unlock()
+
+	rid := qc.rid
+	if full || qc.syncTorrents == nil {
+		rid = 0
+	}
+
+	var data struct {
+		Rid             int64                      `json:"rid"`
+		FullUpdate      bool                       `json:"full_update"`
+		Torrents        map[string]json.RawMessage `json:"torrents"`
+		TorrentsRemoved []string                   `json:"torrents_removed"`
+	}
+	if err := qc.fetchMainData(rid, &data); err != nil {
+		return models.TorrentSync{}, err
+	}
+
+	if data.FullUpdate || qc.syncTorrents == nil {
+		qc.syncTorrents = make(map[string]qb.Torrent, len(data.Torrents))
+	}
+
+	result := models.TorrentSync{Full: data.FullUpdate, Removed: data.TorrentsRemoved}
+	for hash, raw := range data.Torrents {
+		torrent := qc.syncTorrents[hash]
+		if err := json.Unmarshal(raw, &torrent); err != nil {
+			return models.TorrentSync{}, err
+		}
+		torrent.Hash = hash
+		qc.syncTorrents[hash] = torrent
+		if !data.FullUpdate {
+			result.Torrents = append(result.Torrents, qc.toUnified(torrent))
+		}
+	}
+	for _, hash := range data.TorrentsRemoved {
+		delete(qc.syncTorrents, hash)
+	}
+
+	if data.FullUpdate {
+		for _, torrent := range qc.syncTorrents {
+			result.Torrents = append(result.Torrents, qc.toUnified(torrent))
+		}
+	}
+
+	qc.rid = data.Rid
+	return result, nil
+}
+
+// fetchMainData 请求 sync/maindata，会话过期时重新登录并重试一次
+func (qc *QbitClient) fetchMainData(rid int64, result interface{}) error {
+	endpoint, err := url.JoinPath(qc.host, "/api/v2/sync/maindata")
+	if err != nil {
+		return err
+	}
+	endpoint += "?rid=" + strconv.FormatInt(rid, 10)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		resp, err := qc.httpClient.Get(endpoint)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusForbidden {
+			resp.Body.Close()
+			if err := qc.client.Login(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("sync/maindata: unexpected status %d", resp.StatusCode)
+		}
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+
+	return fmt.Errorf("sync/maindata: authentication failed")
 }
\ No newline at end of file
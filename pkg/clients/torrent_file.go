@@ -0,0 +1,269 @@
+package clients
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+
+	"down-nexus-api/internal/models"
+)
+
+// ErrInvalidTorrentFile 种子文件不是合法的 bencode 字典或缺少 info 字段
+var ErrInvalidTorrentFile = errors.New("invalid torrent file")
+
+// maxBencodeDepth 列表与字典的最大嵌套层数，防止恶意构造的文件耗尽栈空间
+const maxBencodeDepth = 64
+
+// TorrentFileInfoHash 计算 .torrent 文件的 v1 info-hash，返回小写十六进制
+// info-hash 为 info 字典原始 bencode 编码的 SHA-1，因此直接定位原始字节而不重新编码
+func TorrentFileInfoHash(data []byte) (string, error) {
+	hash := ""
+	err := bencodeDictEach(data, func(key []byte, start, end int) bool {
+		if !bytes.Equal(key, []byte("info")) {
+			return true
+		}
+		if data[start] == 'd' {
+			sum := sha1.Sum(data[start:end])
+			hash = hex.EncodeToString(sum[:])
+		}
+		return false
+	})
+	if err != nil {
+		return "", err
+	}
+	if hash == "" {
+		return "", ErrInvalidTorrentFile
+	}
+	return hash, nil
+}
+
+// TorrentFileTrackers 返回 .torrent 文件中 announce 与 announce-list 的 tracker 地址，按出现顺序去重
+func TorrentFileTrackers(data []byte) ([]string, error) {
+	var trackers []string
+	seen := make(map[string]bool)
+	add := func(value []byte) {
+		if tracker := string(value); tracker != "" && !seen[tracker] {
+			seen[tracker] = true
+			trackers = append(trackers, tracker)
+		}
+	}
+
+	err := bencodeDictEach(data, func(key []byte, start, end int) bool {
+		switch string(key) {
+		case "announce":
+			if value, _, err := bencodeString(data, start); err == nil {
+				add(value)
+			}
+		case "announce-list":
+			// announce-list 为分层的列表：[[tier1...], [tier2...]]
+			bencodeListEach(data, start, func(tier int) {
+				bencodeListEach(data, tier, func(item int) {
+					if value, _, err := bencodeString(data, item); err == nil {
+						add(value)
+					}
+				})
+			})
+		}
+		return true
+	})
+	return trackers, err
+}
+
+// TorrentFileContents 返回 .torrent 文件的名称与文件列表
+// 单文件种子的路径即名称，多文件种子的路径为 名称/子路径，与客户端返回的文件列表一致；不支持仅含 v2 文件树的种子
+func TorrentFileContents(data []byte) (string, []models.TorrentFile, error) {
+	var name string
+	var files []models.TorrentFile
+	var length int64 = -1
+
+	err := bencodeDictEach(data, func(key []byte, start, end int) bool {
+		if !bytes.Equal(key, []byte("info")) {
+			return true
+		}
+		bencodeDictEachAt(data, start, func(key []byte, start, end int) bool {
+			switch string(key) {
+			case "name":
+				if value, _, err := bencodeString(data, start); err == nil {
+					name = string(value)
+				}
+			case "length":
+				if value, err := bencodeInt(data, start); err == nil {
+					length = value
+				}
+			case "files":
+				bencodeListEach(data, start, func(item int) {
+					file := models.TorrentFile{Size: -1}
+					var segments []string
+					bencodeDictEachAt(data, item, func(key []byte, start, end int) bool {
+						switch string(key) {
+						case "length":
+							if value, err := bencodeInt(data, start); err == nil {
+								file.Size = value
+							}
+						case "path":
+							bencodeListEach(data, start, func(segment int) {
+								if value, _, err := bencodeString(data, segment); err == nil {
+									segments = append(segments, string(value))
+								}
+							})
+						}
+						return true
+					})
+					if file.Size >= 0 && len(segments) > 0 {
+						file.Path = strings.Join(segments, "/")
+						files = append(files, file)
+					}
+				})
+			}
+			return true
+		})
+		return false
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if name == "" {
+		return "", nil, ErrInvalidTorrentFile
+	}
+
+	if len(files) > 0 {
+		for i := range files {
+			files[i].Path = name + "/" + files[i].Path
+		}
+		return name, files, nil
+	}
+	if length < 0 {
+		return "", nil, ErrInvalidTorrentFile
+	}
+	return name, []models.TorrentFile{{Path: name, Size: length}}, nil
+}
+
+// bencodeDictEach 遍历顶层字典的键，fn 接收键与值的起止位置，返回 false 时停止遍历
+func bencodeDictEach(data []byte, fn func(key []byte, start, end int) bool) error {
+	return bencodeDictEachAt(data, 0, fn)
+}
+
+// bencodeDictEachAt 遍历 pos 处字典的键，用法同 bencodeDictEach
+func bencodeDictEachAt(data []byte, pos int, fn func(key []byte, start, end int) bool) error {
+	if pos >= len(data) || data[pos] != 'd' {
+		return ErrInvalidTorrentFile
+	}
+
+	pos++
+	for pos < len(data) && data[pos] != 'e' {
+		key, next, err := bencodeString(data, pos)
+		if err != nil {
+			return err
+		}
+		end, err := bencodeSkip(data, next)
+		if err != nil {
+			return err
+		}
+		if !fn(key, next, end) {
+			return nil
+		}
+		pos = end
+	}
+	if pos >= len(data) {
+		return ErrInvalidTorrentFile
+	}
+	return nil
+}
+
+// bencodeListEach 对 pos 处列表的每个元素调用 fn，pos 处不是列表时不做任何处理
+func bencodeListEach(data []byte, pos int, fn func(item int)) {
+	if pos >= len(data) || data[pos] != 'l' {
+		return
+	}
+	pos++
+	for pos < len(data) && data[pos] != 'e' {
+		next, err := bencodeSkip(data, pos)
+		if err != nil {
+			return
+		}
+		fn(pos)
+		pos = next
+	}
+}
+
+// bencodeString 解析 pos 处的字节串，返回内容与之后的位置
+func bencodeString(data []byte, pos int) ([]byte, int, error) {
+	if pos >= len(data) {
+		return nil, 0, ErrInvalidTorrentFile
+	}
+	colon := bytes.IndexByte(data[pos:], ':')
+	if colon <= 0 {
+		return nil, 0, ErrInvalidTorrentFile
+	}
+	length, err := strconv.Atoi(string(data[pos : pos+colon]))
+	if err != nil || length < 0 {
+		return nil, 0, ErrInvalidTorrentFile
+	}
+	// 与剩余长度比较，避免超大的长度前缀使 start+length 溢出
+	start := pos + colon + 1
+	if length > len(data)-start {
+		return nil, 0, ErrInvalidTorrentFile
+	}
+	return data[start : start+length], start + length, nil
+}
+
+// bencodeInt 解析 pos 处的整数
+func bencodeInt(data []byte, pos int) (int64, error) {
+	if pos >= len(data) || data[pos] != 'i' {
+		return 0, ErrInvalidTorrentFile
+	}
+	end := bytes.IndexByte(data[pos:], 'e')
+	if end < 0 {
+		return 0, ErrInvalidTorrentFile
+	}
+	value, err := strconv.ParseInt(string(data[pos+1:pos+end]), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidTorrentFile
+	}
+	return value, nil
+}
+
+// bencodeSkip 跳过 pos 处的一个值，返回之后的位置
+func bencodeSkip(data []byte, pos int) (int, error) {
+	return bencodeSkipDepth(data, pos, 0)
+}
+
+// bencodeSkipDepth 跳过 pos 处的一个值，depth 为当前的嵌套层数，超过 maxBencodeDepth 时视为非法文件
+func bencodeSkipDepth(data []byte, pos, depth int) (int, error) {
+	if pos >= len(data) {
+		return 0, ErrInvalidTorrentFile
+	}
+
+	switch c := data[pos]; {
+	case c == 'i':
+		end := bytes.IndexByte(data[pos:], 'e')
+		if end < 0 {
+			return 0, ErrInvalidTorrentFile
+		}
+		return pos + end + 1, nil
+	case c == 'l' || c == 'd':
+		if depth >= maxBencodeDepth {
+			return 0, ErrInvalidTorrentFile
+		}
+		pos++
+		for pos < len(data) && data[pos] != 'e' {
+			next, err := bencodeSkipDepth(data, pos, depth+1)
+			if err != nil {
+				return 0, err
+			}
+			pos = next
+		}
+		if pos >= len(data) {
+			return 0, ErrInvalidTorrentFile
+		}
+		return pos + 1, nil
+	case c >= '0' && c <= '9':
+		_, next, err := bencodeString(data, pos)
+		return next, err
+	default:
+		return 0, ErrInvalidTorrentFile
+	}
+}
@@ -0,0 +1,126 @@
+package clients
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"down-nexus-api/internal/models"
+)
+
+const (
+	testSingleInfo = "d6:lengthi1024e4:name8:show.mkv12:piece lengthi16384e6:pieces0:e"
+	testMultiInfo  = "d5:filesld6:lengthi10e4:pathl3:sub5:a.mkveed6:lengthi20e4:pathl5:b.nfoeee4:name4:pack12:piece lengthi16384e6:pieces0:e"
+)
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestTorrentFileInfoHash(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"single file", "d8:announce20:http://tracker/annou4:info" + testSingleInfo + "e", sha1Hex(testSingleInfo)},
+		{"info first", "d4:info" + testMultiInfo + "7:comment2:hie", sha1Hex(testMultiInfo)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := TorrentFileInfoHash([]byte(test.data))
+			if err != nil {
+				t.Fatalf("TorrentFileInfoHash() error = %v", err)
+			}
+			if got != test.want {
+				t.Errorf("TorrentFileInfoHash() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTorrentFileInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"not a dict", "l4:infoe"},
+		{"no info", "d7:comment2:hie"},
+		{"info not a dict", "d4:info4:teste"},
+		{"unterminated dict", "d4:infod4:name1:a"},
+		{"truncated string", "d4:info10:abce"},
+		{"negative length", "d-1:xe"},
+		{"length overflow", "d9223372036854775807:xe"},
+		{"value length overflow", "d4:info9223372036854775807:xe"},
+		{"length beyond int64", "d99999999999999999999:xe"},
+		{"missing colon", "d4info"},
+		{"unterminated int", "d1:ai12"},
+		{"bad value", "d1:ax1e"},
+		{"deep nesting", "d1:a" + strings.Repeat("l", maxBencodeDepth+1) + strings.Repeat("e", maxBencodeDepth+1) + "e"},
+		{"very deep nesting", "d1:a" + strings.Repeat("l", 1<<20)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := []byte(test.data)
+			if _, err := TorrentFileInfoHash(data); !errors.Is(err, ErrInvalidTorrentFile) {
+				t.Errorf("TorrentFileInfoHash() error = %v, want %v", err, ErrInvalidTorrentFile)
+			}
+			// 以下函数对非法文件同样不能 panic
+			TorrentFileTrackers(data)
+			TorrentFileContents(data)
+		})
+	}
+}
+
+func TestTorrentFileTrackers(t *testing.T) {
+	data := "d8:announce15:http://a/announ13:announce-listll15:http://a/announel15:http://b/announ15:http://c/announee4:info" + testSingleInfo + "e"
+	got, err := TorrentFileTrackers([]byte(data))
+	if err != nil {
+		t.Fatalf("TorrentFileTrackers() error = %v", err)
+	}
+	want := []string{"http://a/announ", "http://b/announ", "http://c/announ"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TorrentFileTrackers() = %v, want %v", got, want)
+	}
+}
+
+func TestTorrentFileContents(t *testing.T) {
+	tests := []struct {
+		name      string
+		info      string
+		wantName  string
+		wantFiles []models.TorrentFile
+	}{
+		{"single file", testSingleInfo, "show.mkv", []models.TorrentFile{{Path: "show.mkv", Size: 1024}}},
+		{"multiple files", testMultiInfo, "pack", []models.TorrentFile{{Path: "pack/sub/a.mkv", Size: 10}, {Path: "pack/b.nfo", Size: 20}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name, files, err := TorrentFileContents([]byte("d4:info" + test.info + "e"))
+			if err != nil {
+				t.Fatalf("TorrentFileContents() error = %v", err)
+			}
+			if name != test.wantName || !reflect.DeepEqual(files, test.wantFiles) {
+				t.Errorf("TorrentFileContents() = %s, %v, want %s, %v", name, files, test.wantName, test.wantFiles)
+			}
+		})
+	}
+}
+
+func FuzzTorrentFile(f *testing.F) {
+	f.Add([]byte("d4:info" + testSingleInfo + "e"))
+	f.Add([]byte("d4:info" + testMultiInfo + "e"))
+	f.Add([]byte("d8:announce15:http://a/announ13:announce-listll15:http://b/announeee"))
+	f.Add([]byte("d9223372036854775807:xe"))
+	f.Add([]byte("d1:a" + strings.Repeat("l", 100)))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		TorrentFileInfoHash(data)
+		TorrentFileTrackers(data)
+		TorrentFileContents(data)
+	})
+}
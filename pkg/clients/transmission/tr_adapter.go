@@ -1,30 +1,60 @@
 package transmission
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
 	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
 	tr "github.com/hekmon/transmissionrpc/v2"
 )
 
+// rpcPort Transmission RPC 端口
+const rpcPort = 10002
+
 type TransmissionClient struct {
 	client   *tr.Client
 	clientID string
+
+	// 直接调用 RPC 所需的连接信息，用于库未覆盖的请求
+	rpcURL     string
+	username   string
+	password   string
+	httpClient *http.Client
+
+	mutex     sync.Mutex
+	sessionID string
+	// syncIDs 增量同步时记录数字 ID 到哈希的映射，用于解析 removed 列表
+	syncIDs map[int64]string
+	// categoryPaths EnsureCategory 记录的分类保存路径，设置分类时据此移动数据
+	categoryPaths map[string]string
 }
 
 func NewTransmissionClient(host, username, password, clientID string) (*TransmissionClient, error) {
 	// Create Transmission client with explicit port configuration
 	client, err := tr.New(host, username, password, &tr.AdvancedConfig{
 		HTTPS: false,
-		Port:  10002, // Explicitly set the port
+		Port:  rpcPort, // Explicitly set the port
 	})
 	if err != nil {
 		return nil, fmt.Errorf("Transmission 连接失败: %w", err)
 	}
 	
 	return &TransmissionClient{
-		client:   client,
-		clientID: clientID,
+		client:     client,
+		clientID:   clientID,
+		rpcURL:     fmt.Sprintf("http://%s:%d/transmission/rpc", host, rpcPort),
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
 	}, nil
 }
 
@@ -36,84 +66,146 @@ func (tc *TransmissionClient) GetTorrents() ([]models.UnifiedTorrent, error) {
 	
 	var unifiedTorrents []models.UnifiedTorrent
 	for _, torrent := range torrents {
-		// Handle nil pointers safely
-		var name string
-		if torrent.Name != nil {
-			name = *torrent.Name
-		}
-		
-		var hash string
-		if torrent.HashString != nil {
-			hash = *torrent.HashString
-		}
-		
-		var size int64
-		if torrent.TotalSize != nil {
-			size = int64(*torrent.TotalSize)
-		}
-		
-		var progress float64
-		if torrent.PercentDone != nil {
-			progress = *torrent.PercentDone
-		}
-		
-		var downloadSpeed int64
-		if torrent.RateDownload != nil {
-			downloadSpeed = *torrent.RateDownload
-		}
-		
-		var uploadSpeed int64
-		if torrent.RateUpload != nil {
-			uploadSpeed = *torrent.RateUpload
-		}
-		
-		var downloaded int64
-		if torrent.DownloadedEver != nil {
-			downloaded = *torrent.DownloadedEver
-		}
-		
-		var uploaded int64
-		if torrent.UploadedEver != nil {
-			uploaded = *torrent.UploadedEver
-		}
-		
-		var eta int64
-		if torrent.Eta != nil {
-			eta = *torrent.Eta
-		}
-		
-		// Convert status to string
-		var state string
-		if torrent.Status != nil {
-			state = torrent.Status.String()
-		}
-		
-		unifiedTorrent := models.UnifiedTorrent{
-			ClientID:      tc.clientID,
-			Name:          name,
-			Hash:          hash,
-			Size:          size,
-			State:         state,
-			Progress:      progress,
-			DownloadSpeed: downloadSpeed,
-			UploadSpeed:   uploadSpeed,
-			Downloaded:    downloaded,
-			Uploaded:      uploaded,
-			ETA:           eta,
-		}
-		unifiedTorrents = append(unifiedTorrents, unifiedTorrent)
+		unifiedTorrents = append(unifiedTorrents, tc.toUnified(torrent))
 	}
 	
 	return unifiedTorrents, nil
 }
 
-func (tc *TransmissionClient) AddTorrent(magnetURL string) error {
+// toUnified 将 Transmission 种子转换为统一模型
+func (tc *TransmissionClient) toUnified(torrent tr.Torrent) models.UnifiedTorrent {
+	// Handle nil pointers safely
+	var name string
+	if torrent.Name != nil {
+		name = *torrent.Name
+	}
+	
+	var hash string
+	if torrent.HashString != nil {
+		hash = *torrent.HashString
+	}
+	
+	var size int64
+	if torrent.TotalSize != nil {
+		size = int64(torrent.TotalSize.Byte())
+	}
+	
+	var progress float64
+	if torrent.PercentDone != nil {
+		progress = *torrent.PercentDone
+	}
+	
+	var downloadSpeed int64
+	if torrent.RateDownload != nil {
+		downloadSpeed = *torrent.RateDownload
+	}
+	
+	var uploadSpeed int64
+	if torrent.RateUpload != nil {
+		uploadSpeed = *torrent.RateUpload
+	}
+	
+	var downloaded int64
+	if torrent.DownloadedEver != nil {
+		downloaded = *torrent.DownloadedEver
+	}
+	
+	var uploaded int64
+	if torrent.UploadedEver != nil {
+		uploaded = *torrent.UploadedEver
+	}
+	
+	var eta int64
+	if torrent.Eta != nil {
+		eta = *torrent.Eta
+	}
+	
+	// Convert status to string
+	var state string
+	if torrent.Status != nil {
+		state = torrent.Status.String()
+	}
+	
+	var ratio float64
+	if torrent.UploadRatio != nil && *torrent.UploadRatio > 0 {
+		ratio = *torrent.UploadRatio
+	}
+	
+	var seedingTime int64
+	if torrent.SecondsSeeding != nil {
+		seedingTime = int64(*torrent.SecondsSeeding / time.Second)
+	}
+	
+	// 使用第一层级的第一个 tracker
+	var tracker string
+	if len(torrent.Trackers) > 0 && torrent.Trackers[0] != nil {
+		tracker = torrent.Trackers[0].Announce
+	}
+
+	var savePath, contentPath string
+	if torrent.DownloadDir != nil {
+		savePath = *torrent.DownloadDir
+		contentPath = strings.TrimRight(savePath, "/") + "/" + name
+	}
+
+	var addedOn int64
+	if torrent.AddedDate != nil {
+		addedOn = torrent.AddedDate.Unix()
+	}
+
+	category, tags := splitLabels(torrent.Labels)
+	
+	return models.UnifiedTorrent{
+		ClientID:      tc.clientID,
+		Name:          name,
+		Hash:          hash,
+		Size:          size,
+		State:         state,
+		Progress:      progress,
+		DownloadSpeed: downloadSpeed,
+		UploadSpeed:   uploadSpeed,
+		Downloaded:    downloaded,
+		Uploaded:      uploaded,
+		ETA:           eta,
+		Category:      category,
+		Tags:          tags,
+		Ratio:         ratio,
+		SeedingTime:   seedingTime,
+		Tracker:       tracker,
+		SavePath:      savePath,
+		ContentPath:   contentPath,
+		AddedOn:       addedOn,
+	}
+}
+
+func (tc *TransmissionClient) AddTorrent(magnetURL string) (string, error) {
 	// Use Transmission's TorrentAdd method
-	_, err := tc.client.TorrentAdd(context.Background(), tr.TorrentAddPayload{
+	torrent, err := tc.client.TorrentAdd(context.Background(), tr.TorrentAddPayload{
 		Filename: &magnetURL,
 	})
+	if err != nil {
+		return "", err
+	}
 	
-	return err
+	if torrent.HashString == nil {
+		return "", nil
+	}
+	return strings.ToLower(*torrent.HashString), nil
+}
+
+func (tc *TransmissionClient) AddTorrentFile(data []byte) (string, error) {
+	hash, err := clients.TorrentFileInfoHash(data)
+	if err != nil {
+		return "", err
+	}
+
+	metainfo := base64.StdEncoding.EncodeToString(data)
+	if _, err := tc.client.TorrentAdd(context.Background(), tr.TorrentAddPayload{
+		MetaInfo: &metainfo,
+	}); err != nil {
+		return "", err
+	}
+	return hash, nil
 }
 
 func (tc *TransmissionClient) PauseTorrent(hash string) error {
@@ -178,4 +270,535 @@ func (tc *TransmissionClient) DeleteTorrent(hash string, deleteFiles bool) error
 
 func (tc *TransmissionClient) GetClientID() string {
 	return tc.clientID
+}
+
+func (tc *TransmissionClient) GetTransferStats() (models.TransferStats, error) {
+	stats, err := tc.client.SessionStats(context.Background())
+	if err != nil {
+		return models.TransferStats{}, err
+	}
+
+	return models.TransferStats{
+		Downloaded:    stats.CurrentStats.DownloadedBytes,
+		Uploaded:      stats.CurrentStats.UploadedBytes,
+		DownloadSpeed: stats.DownloadSpeed,
+		UploadSpeed:   stats.UploadSpeed,
+	}, nil
+}
+
+func (tc *TransmissionClient) GetFreeSpace() (int64, error) {
+	session, err := tc.client.SessionArgumentsGet(context.Background(), []string{"download-dir"})
+	if err != nil {
+		return 0, err
+	}
+	if session.DownloadDir == nil {
+		return 0, fmt.Errorf("download-dir not reported by transmission")
+	}
+
+	space, err := tc.client.FreeSpace(context.Background(), *session.DownloadDir)
+	if err != nil {
+		return 0, err
+	}
+	return int64(space.Byte()), nil
+}
+
+// Transmission 的速度限制单位为 KB/s（1 KB = 1000 字节）
+const speedUnitBytes = 1000
+
+// toSpeedUnits 将字节/秒换算为 KB/s，不足 1 KB/s 的限制向上取整，避免启用限速后速度为 0
+func toSpeedUnits(limit int64) int64 {
+	return (limit + speedUnitBytes - 1) / speedUnitBytes
+}
+
+func (tc *TransmissionClient) GetTransferLimits() (models.TransferLimits, error) {
+	session, err := tc.client.SessionArgumentsGet(context.Background(), []string{
+		"speed-limit-down",
+		"speed-limit-down-enabled",
+		"speed-limit-up",
+		"speed-limit-up-enabled",
+		"alt-speed-enabled",
+	})
+	if err != nil {
+		return models.TransferLimits{}, err
+	}
+
+	var limits models.TransferLimits
+	if session.SpeedLimitDownEnabled != nil && *session.SpeedLimitDownEnabled && session.SpeedLimitDown != nil {
+		limits.DownloadLimit = *session.SpeedLimitDown * speedUnitBytes
+	}
+	if session.SpeedLimitUpEnabled != nil && *session.SpeedLimitUpEnabled && session.SpeedLimitUp != nil {
+		limits.UploadLimit = *session.SpeedLimitUp * speedUnitBytes
+	}
+	if session.AltSpeedEnabled != nil {
+		limits.AltSpeedEnabled = *session.AltSpeedEnabled
+	}
+
+	return limits, nil
+}
+
+func (tc *TransmissionClient) SetTransferLimits(limits models.TransferLimits) error {
+	downEnabled := limits.DownloadLimit > 0
+	downLimit := toSpeedUnits(limits.DownloadLimit)
+	upEnabled := limits.UploadLimit > 0
+	upLimit := toSpeedUnits(limits.UploadLimit)
+	altEnabled := limits.AltSpeedEnabled
+
+	return tc.client.SessionArgumentsSet(context.Background(), tr.SessionArguments{
+		SpeedLimitDown:        &downLimit,
+		SpeedLimitDownEnabled: &downEnabled,
+		SpeedLimitUp:          &upLimit,
+		SpeedLimitUpEnabled:   &upEnabled,
+		AltSpeedEnabled:       &altEnabled,
+	})
+}
+
+func (tc *TransmissionClient) SetTorrentLimits(hash string, downloadLimit, uploadLimit int64) error {
+	id, err := tc.getTorrentID(hash)
+	if err != nil {
+		return err
+	}
+
+	downLimited := downloadLimit > 0
+	downLimit := toSpeedUnits(downloadLimit)
+	upLimited := uploadLimit > 0
+	upLimit := toSpeedUnits(uploadLimit)
+
+	return tc.client.TorrentSet(context.Background(), tr.TorrentSetPayload{
+		IDs:             []int64{id},
+		DownloadLimit:   &downLimit,
+		DownloadLimited: &downLimited,
+		UploadLimit:     &upLimit,
+		UploadLimited:   &upLimited,
+	})
+}
+
+// getTorrentID 根据哈希查找 Transmission 的数字 ID
+func (tc *TransmissionClient) getTorrentID(hash string) (int64, error) {
+	torrents, err := tc.client.TorrentGetHashes(context.Background(), []string{"id", "hashString"}, []string{hash})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, torrent := range torrents {
+		if torrent.ID != nil {
+			return *torrent.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("torrent with hash %s not found", hash)
+}
+
+
+// Transmission 只有标签，分类以带前缀的标签表示
+const categoryLabelPrefix = "category:"
+
+// splitLabels 将 Transmission 标签拆分为分类和普通标签
+func splitLabels(labels []string) (string, []string) {
+	var category string
+	var tags []string
+	for _, label := range labels {
+		if strings.HasPrefix(label, categoryLabelPrefix) {
+			category = strings.TrimPrefix(label, categoryLabelPrefix)
+			continue
+		}
+		tags = append(tags, label)
+	}
+	return category, tags
+}
+
+// EnsureCategory Transmission 的标签无需预先创建，这里只记录分类的保存路径
+// Transmission 本身没有分类保存路径，由 SetCategory 在设置分类后移动种子数据
+func (tc *TransmissionClient) EnsureCategory(name, savePath string) error {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	if savePath == "" {
+		delete(tc.categoryPaths, name)
+		return nil
+	}
+	if tc.categoryPaths == nil {
+		tc.categoryPaths = make(map[string]string)
+	}
+	tc.categoryPaths[name] = savePath
+	return nil
+}
+
+// SetCategory 设置分类标签，分类配置了保存路径时将种子数据移动到该路径
+func (tc *TransmissionClient) SetCategory(hashes []string, category string) error {
+	err := tc.updateLabels(hashes, func(labels []string) []string {
+		var result []string
+		for _, label := range labels {
+			if !strings.HasPrefix(label, categoryLabelPrefix) {
+				result = append(result, label)
+			}
+		}
+		if category != "" {
+			result = append(result, categoryLabelPrefix+category)
+		}
+		return result
+	})
+	if err != nil || category == "" {
+		return err
+	}
+
+	tc.mutex.Lock()
+	savePath := tc.categoryPaths[category]
+	tc.mutex.Unlock()
+	if savePath == "" {
+		return nil
+	}
+	for _, hash := range hashes {
+		if err := tc.client.TorrentSetLocationHash(context.Background(), hash, savePath, true); err != nil {
+			return fmt.Errorf("move %s to category save path: %w", hash, err)
+		}
+	}
+	return nil
+}
+
+func (tc *TransmissionClient) AddTags(hashes []string, tags []string) error {
+	return tc.updateLabels(hashes, func(labels []string) []string {
+		for _, tag := range tags {
+			if !containsString(labels, tag) {
+				labels = append(labels, tag)
+			}
+		}
+		return labels
+	})
+}
+
+func (tc *TransmissionClient) RemoveTags(hashes []string, tags []string) error {
+	return tc.updateLabels(hashes, func(labels []string) []string {
+		var result []string
+		for _, label := range labels {
+			if !containsString(tags, label) {
+				result = append(result, label)
+			}
+		}
+		return result
+	})
+}
+
+// updateLabels 读取种子当前标签，经 modify 处理后写回
+// torrent-set 的 labels 参数会整体替换原有标签，因此需要逐个种子处理
+func (tc *TransmissionClient) updateLabels(hashes []string, modify func([]string) []string) error {
+	torrents, err := tc.client.TorrentGetHashes(context.Background(), []string{"id", "hashString", "labels"}, hashes)
+	if err != nil {
+		return err
+	}
+	if len(torrents) != len(hashes) {
+		return fmt.Errorf("some torrents were not found: %s", strings.Join(hashes, ", "))
+	}
+
+	for _, torrent := range torrents {
+		if torrent.ID == nil {
+			continue
+		}
+		labels := modify(append([]string(nil), torrent.Labels...))
+		if labels == nil {
+			labels = []string{}
+		}
+		err := tc.client.TorrentSet(context.Background(), tr.TorrentSetPayload{
+			IDs:    []int64{*torrent.ID},
+			Labels: labels,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SetShareLimits 设置种子的分享限制
+// Transmission 只能在达到分享率后暂停种子，其余情况关闭客户端的分享率限制，交由 Down-Nexus 执行
+func (tc *TransmissionClient) SetShareLimits(hashes []string, limits models.ShareLimits) (bool, error) {
+	ids, err := tc.getTorrentIDs(hashes)
+	if err != nil {
+		return false, err
+	}
+
+	payload := tr.TorrentSetPayload{IDs: ids}
+	native := false
+
+	switch {
+	case limits.IsEmpty():
+		mode := tr.SeedRatioModeGlobal
+		payload.SeedRatioMode = &mode
+	case limits.Action == models.ShareLimitActionPause && limits.SeedingTimeLimit == nil:
+		mode := tr.SeedRatioModeCustom
+		payload.SeedRatioMode = &mode
+		payload.SeedRatioLimit = limits.RatioLimit
+		native = true
+	default:
+		mode := tr.SeedRatioModeNoRatio
+		payload.SeedRatioMode = &mode
+	}
+
+	if err := tc.client.TorrentSet(context.Background(), payload); err != nil {
+		return false, err
+	}
+	return native, nil
+}
+
+// getTorrentIDs 根据哈希批量查找 Transmission 的数字 ID
+func (tc *TransmissionClient) getTorrentIDs(hashes []string) ([]int64, error) {
+	torrents, err := tc.client.TorrentGetHashes(context.Background(), []string{"id", "hashString"}, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for _, torrent := range torrents {
+		if torrent.ID != nil {
+			ids = append(ids, *torrent.ID)
+		}
+	}
+	if len(ids) != len(hashes) {
+		return nil, fmt.Errorf("some torrents were not found: %s", strings.Join(hashes, ", "))
+	}
+	return ids, nil
+}
+
+// RenameTorrent 重命名种子
+// Transmission 没有独立的显示名称，种子名称即根文件或根目录名，会同时重命名磁盘上的数据
+func (tc *TransmissionClient) RenameTorrent(hash, name string) error {
+	torrents, err := tc.client.TorrentGetHashes(context.Background(), []string{"id", "hashString", "name"}, []string{hash})
+	if err != nil {
+		return err
+	}
+	if len(torrents) == 0 || torrents[0].Name == nil {
+		return fmt.Errorf("torrent with hash %s not found", hash)
+	}
+
+	return tc.client.TorrentRenamePathHash(context.Background(), hash, *torrents[0].Name, name)
+}
+
+// RenameFile 重命名种子内的文件或文件夹
+// torrent-rename-path 只能修改最后一级名称，不支持移动到其他目录
+func (tc *TransmissionClient) RenameFile(hash, oldPath, newPath string) error {
+	if path.Dir(oldPath) != path.Dir(newPath) {
+		return fmt.Errorf("%w: %s -> %s", clients.ErrCrossDirectoryRename, oldPath, newPath)
+	}
+
+	return tc.client.TorrentRenamePathHash(context.Background(), hash, oldPath, path.Base(newPath))
+}
+
+// ExportTorrent 导出种子的 .torrent 文件及保存路径
+// Transmission RPC 不提供种子文件内容，只返回其在配置目录中的路径，
+// 因此需要将 Transmission 的配置目录以相同路径挂载到本服务
+func (tc *TransmissionClient) ExportTorrent(hash string) (models.TorrentExport, error) {
+	torrents, err := tc.client.TorrentGetHashes(context.Background(), []string{"id", "hashString", "torrentFile", "downloadDir"}, []string{hash})
+	if err != nil {
+		return models.TorrentExport{}, err
+	}
+	if len(torrents) == 0 || torrents[0].TorrentFile == nil {
+		return models.TorrentExport{}, fmt.Errorf("torrent with hash %s not found", hash)
+	}
+
+	data, err := os.ReadFile(*torrents[0].TorrentFile)
+	if err != nil {
+		return models.TorrentExport{}, fmt.Errorf("read .torrent file of %s (Transmission config directory must be mounted at the same path): %w", hash, err)
+	}
+
+	export := models.TorrentExport{MetaInfo: data}
+	if torrents[0].DownloadDir != nil {
+		export.SavePath = *torrents[0].DownloadDir
+	}
+	return export, nil
+}
+
+// ImportTorrent 按指定保存路径添加 .torrent 文件
+// Transmission 不支持跳过校验，已有数据会在添加后自动校验
+func (tc *TransmissionClient) ImportTorrent(data []byte, options models.ImportOptions) (string, error) {
+	hash, err := clients.TorrentFileInfoHash(data)
+	if err != nil {
+		return "", err
+	}
+
+	metainfo := base64.StdEncoding.EncodeToString(data)
+	payload := tr.TorrentAddPayload{
+		MetaInfo: &metainfo,
+		Paused:   &options.Paused,
+	}
+	if options.SavePath != "" {
+		payload.DownloadDir = &options.SavePath
+	}
+	if _, err := tc.client.TorrentAdd(context.Background(), payload); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+func (tc *TransmissionClient) RecheckTorrent(hash string) error {
+	id, err := tc.getTorrentID(hash)
+	if err != nil {
+		return err
+	}
+	return tc.client.TorrentVerifyIDs(context.Background(), []int64{id})
+}
+
+// MoveTorrent 移动种子数据并更新下载目录
+func (tc *TransmissionClient) MoveTorrent(hash, location string) error {
+	id, err := tc.getTorrentID(hash)
+	if err != nil {
+		return err
+	}
+	return tc.client.TorrentSetLocation(context.Background(), id, location, true)
+}
+
+// GetTorrentFiles 获取种子的保存路径与文件列表
+func (tc *TransmissionClient) GetTorrentFiles(hash string) (models.TorrentContent, error) {
+	torrents, err := tc.client.TorrentGetHashes(context.Background(), []string{"id", "hashString", "files", "downloadDir"}, []string{hash})
+	if err != nil {
+		return models.TorrentContent{}, err
+	}
+	if len(torrents) == 0 {
+		return models.TorrentContent{}, fmt.Errorf("torrent with hash %s not found", hash)
+	}
+
+	var content models.TorrentContent
+	if torrents[0].DownloadDir != nil {
+		content.SavePath = *torrents[0].DownloadDir
+	}
+	for _, file := range torrents[0].Files {
+		content.Files = append(content.Files, models.TorrentFile{Path: file.Name, Size: file.Length})
+	}
+	return content, nil
+}
+
+// syncFields 同步时请求的字段，覆盖 toUnified 所需的全部字段
+var syncFields = []string{
+	"id", "name", "hashString", "totalSize", "percentDone", "rateDownload", "rateUpload",
+	"downloadedEver", "uploadedEver", "eta", "status", "labels", "uploadRatio", "secondsSeeding",
+	"trackers", "addedDate", "downloadDir",
+}
+
+// SyncTorrents 同步种子列表
+// 增量模式下使用 recently-active 只获取最近活动的种子及已删除的种子 ID
+func (tc *TransmissionClient) SyncTorrents(full bool) (models.TorrentSync, error) {
+	tc.mutex.Lock()
+	incremental := !full && tc.syncIDs != nil
+	tc.mutex.Unlock()
+
+	if !incremental {
+		torrents, err := tc.client.TorrentGet(context.Background(), syncFields, nil)
+		if err != nil {
+			return models.TorrentSync{}, err
+		}
+
+		ids := make(map[int64]string, len(torrents))
+		result := models.TorrentSync{Full: true}
+		for _, torrent := range torrents {
+			if torrent.ID != nil && torrent.HashString != nil {
+				ids[*torrent.ID] = *torrent.HashString
+			}
+			result.Torrents = append(result.Torrents, tc.toUnified(torrent))
+		}
+
+		tc.mutex.Lock()
+		tc.syncIDs = ids
+		tc.mutex.Unlock()
+		return result, nil
+	}
+
+	var answer struct {
+		Torrents []tr.Torrent `json:"torrents"`
+		Removed  []int64      `json:"removed"`
+	}
+	err := tc.rpcRequest("torrent-get", map[string]interface{}{
+		"fields": syncFields,
+		"ids":    "recently-active",
+	}, &answer)
+	if err != nil {
+		return models.TorrentSync{}, err
+	}
+
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	var result models.TorrentSync
+	for _, torrent := range answer.Torrents {
+		if torrent.ID != nil && torrent.HashString != nil {
+			tc.syncIDs[*torrent.ID] = *torrent.HashString
+		}
+		result.Torrents = append(result.Torrents, tc.toUnified(torrent))
+	}
+	for _, id := range answer.Removed {
+		if hash, ok := tc.syncIDs[id]; ok {
+			result.Removed = append(result.Removed, hash)
+			delete(tc.syncIDs, id)
+		}
+	}
+
+	return result, nil
+}
+
+// rpcRequest 直接调用 Transmission RPC，处理 X-Transmission-Session-Id 握手
+func (tc *TransmissionClient) rpcRequest(method string, arguments interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"method":    method,
+		"arguments": arguments,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 第一次请求可能因会话 ID 过期返回 409，更新后重试一次
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, tc.rpcURL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if tc.username != "" {
+			req.SetBasicAuth(tc.username, tc.password)
+		}
+
+		tc.mutex.Lock()
+		sessionID := tc.sessionID
+		tc.mutex.Unlock()
+		if sessionID != "" {
+			req.Header.Set("X-Transmission-Session-Id", sessionID)
+		}
+
+		resp, err := tc.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusConflict {
+			resp.Body.Close()
+			tc.mutex.Lock()
+			tc.sessionID = resp.Header.Get("X-Transmission-Session-Id")
+			tc.mutex.Unlock()
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("transmission rpc %s: unexpected status %d", method, resp.StatusCode)
+		}
+
+		var answer struct {
+			Result    string          `json:"result"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+			return err
+		}
+		if answer.Result != "success" {
+			return fmt.Errorf("transmission rpc %s: %s", method, answer.Result)
+		}
+		return json.Unmarshal(answer.Arguments, result)
+	}
+
+	return fmt.Errorf("transmission rpc %s: session id handshake failed", method)
 }
\ No newline at end of file
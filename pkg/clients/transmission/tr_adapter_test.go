@@ -0,0 +1,305 @@
+package transmission
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/clients"
+	tr "github.com/hekmon/transmissionrpc/v2"
+)
+
+const testHash = "1111111111111111111111111111111111111111"
+
+// rpcRequest fakeRPC 收到的请求
+type rpcRequest struct {
+	Method    string
+	Arguments map[string]interface{}
+}
+
+// fakeRPC 模拟 Transmission RPC，按方法名返回预设的参数并记录收到的请求
+type fakeRPC struct {
+	mutex     sync.Mutex
+	responses map[string]interface{}
+	requests  []rpcRequest
+}
+
+func (f *fakeRPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Method    string                 `json:"method"`
+		Arguments map[string]interface{} `json:"arguments"`
+		Tag       int                    `json:"tag"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mutex.Lock()
+	f.requests = append(f.requests, rpcRequest{Method: request.Method, Arguments: request.Arguments})
+	arguments := f.responses[request.Method]
+	f.mutex.Unlock()
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"result": "success", "arguments": arguments, "tag": request.Tag})
+}
+
+// Requests 返回并清空已收到的请求
+func (f *fakeRPC) Requests() []rpcRequest {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	requests := f.requests
+	f.requests = nil
+	return requests
+}
+
+func newTestClient(t *testing.T, responses map[string]interface{}) (*TransmissionClient, *fakeRPC) {
+	t.Helper()
+	rpc := &fakeRPC{responses: responses}
+	server := httptest.NewServer(rpc)
+	t.Cleanup(server.Close)
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	portNumber, _ := strconv.ParseUint(port, 10, 16)
+	client, err := tr.New(host, "", "", &tr.AdvancedConfig{Port: uint16(portNumber)})
+	if err != nil {
+		t.Fatalf("tr.New() error = %v", err)
+	}
+	return &TransmissionClient{client: client, clientID: "tr-1"}, rpc
+}
+
+func TestToSpeedUnits(t *testing.T) {
+	tests := []struct {
+		limit int64
+		want  int64
+	}{
+		{0, 0},
+		{1, 1},
+		{999, 1},
+		{1000, 1},
+		{1001, 2},
+		{1500000, 1500},
+	}
+	for _, test := range tests {
+		if got := toSpeedUnits(test.limit); got != test.want {
+			t.Errorf("toSpeedUnits(%d) = %d, want %d", test.limit, got, test.want)
+		}
+	}
+}
+
+func TestGetTransferLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		session map[string]interface{}
+		want    models.TransferLimits
+	}{
+		{
+			name: "enabled limits",
+			session: map[string]interface{}{
+				"speed-limit-down": 100, "speed-limit-down-enabled": true,
+				"speed-limit-up": 20, "speed-limit-up-enabled": true,
+				"alt-speed-enabled": true,
+			},
+			want: models.TransferLimits{DownloadLimit: 100000, UploadLimit: 20000, AltSpeedEnabled: true},
+		},
+		{
+			name: "disabled limits are unlimited",
+			session: map[string]interface{}{
+				"speed-limit-down": 100, "speed-limit-down-enabled": false,
+				"speed-limit-up": 20, "speed-limit-up-enabled": false,
+				"alt-speed-enabled": false,
+			},
+			want: models.TransferLimits{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, _ := newTestClient(t, map[string]interface{}{"session-get": test.session})
+			got, err := client.GetTransferLimits()
+			if err != nil {
+				t.Fatalf("GetTransferLimits() error = %v", err)
+			}
+			if got != test.want {
+				t.Errorf("GetTransferLimits() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetTransferLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits models.TransferLimits
+		want   map[string]interface{}
+	}{
+		{
+			name:   "limits are rounded up to KB/s",
+			limits: models.TransferLimits{DownloadLimit: 1500, UploadLimit: 1, AltSpeedEnabled: true},
+			want: map[string]interface{}{
+				"speed-limit-down": 2.0, "speed-limit-down-enabled": true,
+				"speed-limit-up": 1.0, "speed-limit-up-enabled": true,
+				"alt-speed-enabled": true,
+			},
+		},
+		{
+			name:   "zero disables the limits",
+			limits: models.TransferLimits{},
+			want: map[string]interface{}{
+				"speed-limit-down": 0.0, "speed-limit-down-enabled": false,
+				"speed-limit-up": 0.0, "speed-limit-up-enabled": false,
+				"alt-speed-enabled": false,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, rpc := newTestClient(t, nil)
+			if err := client.SetTransferLimits(test.limits); err != nil {
+				t.Fatalf("SetTransferLimits() error = %v", err)
+			}
+			want := []rpcRequest{{Method: "session-set", Arguments: test.want}}
+			if requests := rpc.Requests(); !reflect.DeepEqual(requests, want) {
+				t.Errorf("requests = %+v, want %+v", requests, want)
+			}
+		})
+	}
+}
+
+func TestSplitLabels(t *testing.T) {
+	tests := []struct {
+		name         string
+		labels       []string
+		wantCategory string
+		wantTags     []string
+	}{
+		{"no labels", nil, "", nil},
+		{"tags only", []string{"a", "b"}, "", []string{"a", "b"}},
+		{"category and tags", []string{"a", "category:tv", "b"}, "tv", []string{"a", "b"}},
+		{"category only", []string{"category:tv"}, "tv", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			category, tags := splitLabels(test.labels)
+			if category != test.wantCategory || !reflect.DeepEqual(tags, test.wantTags) {
+				t.Errorf("splitLabels(%v) = %q, %v, want %q, %v", test.labels, category, tags, test.wantCategory, test.wantTags)
+			}
+		})
+	}
+}
+
+func TestUpdateLabels(t *testing.T) {
+	torrents := map[string]interface{}{
+		"torrents": []map[string]interface{}{{"id": 1, "hashString": testHash, "labels": []string{"keep", "old", "category:movies"}}},
+	}
+	tests := []struct {
+		name   string
+		update func(*TransmissionClient) error
+		want   []interface{}
+	}{
+		{"set category", func(c *TransmissionClient) error { return c.SetCategory([]string{testHash}, "tv") }, []interface{}{"keep", "old", "category:tv"}},
+		{"clear category", func(c *TransmissionClient) error { return c.SetCategory([]string{testHash}, "") }, []interface{}{"keep", "old"}},
+		{"add tags", func(c *TransmissionClient) error { return c.AddTags([]string{testHash}, []string{"new", "keep"}) }, []interface{}{"keep", "old", "category:movies", "new"}},
+		{"remove tags", func(c *TransmissionClient) error { return c.RemoveTags([]string{testHash}, []string{"old"}) }, []interface{}{"keep", "category:movies"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, rpc := newTestClient(t, map[string]interface{}{"torrent-get": torrents})
+			if err := test.update(client); err != nil {
+				t.Fatalf("update error = %v", err)
+			}
+			requests := rpc.Requests()
+			if len(requests) != 2 || requests[1].Method != "torrent-set" {
+				t.Fatalf("requests = %+v, want torrent-get and torrent-set", requests)
+			}
+			if labels := requests[1].Arguments["labels"]; !reflect.DeepEqual(labels, test.want) {
+				t.Errorf("labels = %v, want %v", labels, test.want)
+			}
+		})
+	}
+}
+
+func TestRenameFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldPath string
+		newPath string
+		want    []rpcRequest
+		wantErr error
+	}{
+		{
+			name:    "same directory",
+			oldPath: "dir/a.mkv",
+			newPath: "dir/b.mkv",
+			want: []rpcRequest{{Method: "torrent-rename-path", Arguments: map[string]interface{}{
+				"ids": []interface{}{testHash}, "path": "dir/a.mkv", "name": "b.mkv",
+			}}},
+		},
+		{
+			name:    "cross directory",
+			oldPath: "dir/a.mkv",
+			newPath: "other/a.mkv",
+			wantErr: clients.ErrCrossDirectoryRename,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, rpc := newTestClient(t, nil)
+			err := client.RenameFile(testHash, test.oldPath, test.newPath)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("RenameFile() error = %v, want %v", err, test.wantErr)
+			}
+			if requests := rpc.Requests(); !reflect.DeepEqual(requests, test.want) {
+				t.Errorf("requests = %+v, want %+v", requests, test.want)
+			}
+		})
+	}
+}
+
+func TestSetCategoryMovesToSavePath(t *testing.T) {
+	torrents := map[string]interface{}{
+		"torrents": []map[string]interface{}{{"id": 1, "hashString": testHash, "labels": []string{}}},
+	}
+	tests := []struct {
+		name     string
+		savePath string
+		category string
+		wantMove bool
+	}{
+		{"category with save path", "/data/tv", "tv", true},
+		{"category without save path", "", "tv", false},
+		{"other category", "/data/tv", "movies", false},
+		{"clear category", "/data/tv", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, rpc := newTestClient(t, map[string]interface{}{"torrent-get": torrents})
+			if err := client.EnsureCategory("tv", test.savePath); err != nil {
+				t.Fatalf("EnsureCategory() error = %v", err)
+			}
+			if err := client.SetCategory([]string{testHash}, test.category); err != nil {
+				t.Fatalf("SetCategory() error = %v", err)
+			}
+			requests := rpc.Requests()
+			var move *rpcRequest
+			for i := range requests {
+				if requests[i].Method == "torrent-set-location" {
+					move = &requests[i]
+				}
+			}
+			if (move != nil) != test.wantMove {
+				t.Fatalf("requests = %+v, want move %t", requests, test.wantMove)
+			}
+			if move != nil && (move.Arguments["location"] != test.savePath || move.Arguments["move"] != true) {
+				t.Errorf("torrent-set-location arguments = %v, want location %s with move", move.Arguments, test.savePath)
+			}
+		})
+	}
+}
@@ -18,7 +18,7 @@ func InitDB(dsn string) (*gorm.DB, error) {
 	}
 
 	// 自动迁移表结构
-	if err := db.AutoMigrate(&models.ClientConfig{}); err != nil {
+	if err := db.AutoMigrate(&models.ClientConfig{}, &models.ScheduleRule{}, &models.ScheduleBaseline{}, &models.Category{}, &models.CategorySavePath{}, &models.SharePolicy{}, &models.TransferSample{}, &models.TransferStat{}, &models.User{}, &models.APIKey{}, &models.RefreshToken{}, &models.UserGrant{}, &models.TorrentOwnership{}, &models.UserQuota{}, &models.AuditLog{}, &models.ClientSelectionPolicy{}, &models.ClientSelectionRule{}, &models.WatchFolder{}, &models.WatchIngestion{}, &models.RSSFeed{}, &models.RSSRule{}, &models.RSSItem{}, &models.HousekeepingRule{}, &models.HousekeepingExecution{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"fmt"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/secrets"
+	"gorm.io/gorm"
+)
+
+// ReencryptClientSecrets 用 from 解密并用 to 重新加密所有客户端配置的密码，返回更新的行数
+// from 与 to 为同一个加密器时只加密仍为明文的行；任一行解密失败时整个事务回滚
+func ReencryptClientSecrets(db *gorm.DB, from, to *secrets.Cipher) (int, error) {
+	updated := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var configs []models.ClientConfig
+		if err := tx.Unscoped().Find(&configs).Error; err != nil {
+			return err
+		}
+
+		for _, config := range configs {
+			if from == to && secrets.IsEncrypted(config.Password) {
+				continue
+			}
+
+			password, err := from.Decrypt(config.Password, config.ClientID)
+			if err != nil {
+				return fmt.Errorf("client %s: %w", config.ClientID, err)
+			}
+			encrypted, err := to.Encrypt(password, config.ClientID)
+			if err != nil {
+				return fmt.Errorf("client %s: %w", config.ClientID, err)
+			}
+			if encrypted == config.Password {
+				continue
+			}
+
+			if err := tx.Unscoped().Model(&config).Update("password", encrypted).Error; err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
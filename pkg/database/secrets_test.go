@@ -0,0 +1,77 @@
+package database
+
+import (
+	"bytes"
+	"testing"
+
+	"down-nexus-api/internal/models"
+	"down-nexus-api/pkg/secrets"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestCipher(t *testing.T, fill byte) *secrets.Cipher {
+	t.Helper()
+	c, err := secrets.NewCipher(bytes.Repeat([]byte{fill}, secrets.KeySize))
+	if err != nil {
+		t.Fatalf("NewCipher() error = %v", err)
+	}
+	return c
+}
+
+// passwords 按客户端 ID 返回数据库中保存的密码
+func passwords(t *testing.T, db *gorm.DB) map[string]string {
+	t.Helper()
+	var configs []models.ClientConfig
+	if err := db.Find(&configs).Error; err != nil {
+		t.Fatal(err)
+	}
+	result := make(map[string]string)
+	for _, config := range configs {
+		result[config.ClientID] = config.Password
+	}
+	return result
+}
+
+func TestReencryptClientSecrets(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.ClientConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	oldKey, newKey := newTestCipher(t, 1), newTestCipher(t, 2)
+	db.Create(&models.ClientConfig{ClientID: "qb-1", Type: "qbittorrent", Host: "qb", Username: "admin", Password: "plain"})
+	db.Create(&models.ClientConfig{ClientID: "tr-1", Type: "transmission", Host: "tr", Username: "admin", Password: ""})
+
+	steps := []struct {
+		name        string
+		from, to    *secrets.Cipher
+		wantUpdated int
+		wantErr     bool
+		// stored 执行后保护已保存密码的密钥
+		stored *secrets.Cipher
+	}{
+		{"encrypt plaintext", oldKey, oldKey, 1, false, oldKey},
+		{"already encrypted values are skipped", newKey, newKey, 0, false, oldKey},
+		{"rotate with the wrong old key", newKey, oldKey, 0, true, oldKey},
+		{"rotate", oldKey, newKey, 1, false, newKey},
+		{"decrypt", newKey, nil, 1, false, nil},
+	}
+	for _, step := range steps {
+		updated, err := ReencryptClientSecrets(db, step.from, step.to)
+		if (err != nil) != step.wantErr || updated != step.wantUpdated {
+			t.Fatalf("%s: ReencryptClientSecrets() = %d, %v, want %d, error %t", step.name, updated, err, step.wantUpdated, step.wantErr)
+		}
+		stored := passwords(t, db)
+		if stored["tr-1"] != "" {
+			t.Errorf("%s: empty password = %q, want it unchanged", step.name, stored["tr-1"])
+		}
+		password, err := step.stored.Decrypt(stored["qb-1"], "qb-1")
+		if err != nil || password != "plain" {
+			t.Errorf("%s: stored password decrypts to %q, %v", step.name, password, err)
+		}
+	}
+}
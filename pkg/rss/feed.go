@@ -0,0 +1,231 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnsupportedFeed 内容不是 RSS 或 Atom 订阅
+var ErrUnsupportedFeed = errors.New("unsupported feed format")
+
+// Item 订阅中的一个条目
+type Item struct {
+	// GUID 条目的唯一标识，未提供时使用 Link
+	GUID  string
+	Title string
+	// Link 种子的下载地址或磁力链接，优先使用 enclosure
+	Link string
+	// Size 条目大小（字节），未知时为 0
+	Size      int64
+	Published time.Time
+}
+
+// rssDocument 同时覆盖 RSS 2.0、RSS 1.0（RDF）与 Atom 的文档结构
+type rssDocument struct {
+	XMLName xml.Name
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// Items RSS 1.0 的条目位于根元素下
+	Items   []rssItem   `xml:"item"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// rssItem RSS 条目，contentLength、magnetURI 来自 ezRSS 的 torrent 命名空间，size、infoHash 来自 nyaa 等站点的扩展
+type rssItem struct {
+	Title     string `xml:"title"`
+	Link      string `xml:"link"`
+	GUID      string `xml:"guid"`
+	PubDate   string `xml:"pubDate"`
+	Enclosure struct {
+		URL    string `xml:"url,attr"`
+		Length string `xml:"length,attr"`
+	} `xml:"enclosure"`
+	ContentLength string `xml:"contentLength"`
+	MagnetURI     string `xml:"magnetURI"`
+	Size          string `xml:"size"`
+	InfoHash      string `xml:"infoHash"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	ID        string     `xml:"id"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+	Links     []atomLink `xml:"link"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// Parse 解析 RSS 或 Atom 订阅，跳过没有下载地址的条目
+func Parse(data []byte) ([]Item, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// 未声明编码或声明为 UTF-8 以外的编码时按原样读取，种子站点的订阅几乎都是 UTF-8
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	var document rssDocument
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	switch strings.ToLower(document.XMLName.Local) {
+	case "rss", "rdf":
+		for _, entry := range append(document.Channel.Items, document.Items...) {
+			if item, ok := entry.item(); ok {
+				items = append(items, item)
+			}
+		}
+	case "feed":
+		for _, entry := range document.Entries {
+			if item, ok := entry.item(); ok {
+				items = append(items, item)
+			}
+		}
+	default:
+		return nil, ErrUnsupportedFeed
+	}
+	return items, nil
+}
+
+func (i rssItem) item() (Item, bool) {
+	item := Item{
+		GUID:      strings.TrimSpace(i.GUID),
+		Title:     strings.TrimSpace(i.Title),
+		Published: parseTime(i.PubDate),
+	}
+
+	switch {
+	case strings.TrimSpace(i.Enclosure.URL) != "":
+		item.Link = strings.TrimSpace(i.Enclosure.URL)
+	case strings.TrimSpace(i.MagnetURI) != "":
+		item.Link = strings.TrimSpace(i.MagnetURI)
+	case strings.TrimSpace(i.Link) != "":
+		item.Link = strings.TrimSpace(i.Link)
+	case strings.TrimSpace(i.InfoHash) != "":
+		item.Link = "magnet:?xt=urn:btih:" + strings.TrimSpace(i.InfoHash)
+	default:
+		return item, false
+	}
+
+	for _, value := range []string{i.Enclosure.Length, i.ContentLength, i.Size} {
+		if size := ParseSize(value); size > 0 {
+			item.Size = size
+			break
+		}
+	}
+	if item.GUID == "" {
+		item.GUID = item.Link
+	}
+	return item, true
+}
+
+func (e atomEntry) item() (Item, bool) {
+	item := Item{
+		GUID:      strings.TrimSpace(e.ID),
+		Title:     strings.TrimSpace(e.Title),
+		Published: parseTime(e.Published),
+	}
+	if item.Published.IsZero() {
+		item.Published = parseTime(e.Updated)
+	}
+
+	// 优先使用 rel="enclosure" 的链接
+	for _, link := range e.Links {
+		if link.Href == "" {
+			continue
+		}
+		if link.Rel == "enclosure" {
+			item.Link = link.Href
+			item.Size = ParseSize(link.Length)
+			break
+		}
+		if item.Link == "" && (link.Rel == "" || link.Rel == "alternate") {
+			item.Link = link.Href
+		}
+	}
+	if item.Link == "" {
+		return item, false
+	}
+	if item.GUID == "" {
+		item.GUID = item.Link
+	}
+	return item, true
+}
+
+// sizePattern 匹配 "1.4 GiB"、"700MB" 等带单位的大小
+var sizePattern = regexp.MustCompile(`(?i)^([0-9]+(?:\.[0-9]+)?)\s*([KMGT]?)(i?)B?$`)
+
+// ParseSize 解析字节数或带单位的大小，KB 与 KiB 均按 1024 换算，无法解析时返回 0
+func ParseSize(value string) int64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if size, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return size
+	}
+
+	match := sizePattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0
+	}
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0
+	}
+	multiplier := 1.0
+	switch strings.ToUpper(match[2]) {
+	case "K":
+		multiplier = 1 << 10
+	case "M":
+		multiplier = 1 << 20
+	case "G":
+		multiplier = 1 << 30
+	case "T":
+		multiplier = 1 << 40
+	}
+	return int64(number * multiplier)
+}
+
+// parseTime 解析 RSS（RFC 1123）与 Atom（RFC 3339）的时间，无法解析时返回零值
+func parseTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+// episodePattern 匹配 S01E02 与 1x02 两种剧集编号
+var episodePattern = regexp.MustCompile(`(?i)\b(?:S(\d{1,3})[ ._-]?E(\d{1,4})|(\d{1,2})x(\d{2,3}))\b`)
+
+// Episode 从标题识别季与集，未识别时 ok 为 false
+func Episode(title string) (season, episode int, ok bool) {
+	match := episodePattern.FindStringSubmatch(title)
+	if match == nil {
+		return 0, 0, false
+	}
+	if match[1] != "" {
+		season, _ = strconv.Atoi(match[1])
+		episode, _ = strconv.Atoi(match[2])
+	} else {
+		season, _ = strconv.Atoi(match[3])
+		episode, _ = strconv.Atoi(match[4])
+	}
+	return season, episode, true
+}
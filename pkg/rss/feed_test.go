@@ -0,0 +1,144 @@
+package rss
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		feed string
+		want []Item
+	}{
+		{
+			name: "rss 2.0",
+			feed: `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:torrent="http://xmlns.ezrss.it/0.1/" xmlns:nyaa="https://nyaa.si/xmlns/nyaa">
+<channel>
+	<title>tracker</title>
+	<item>
+		<title>Show S01E02 1080p</title>
+		<guid>item-1</guid>
+		<link>https://example.com/details/1</link>
+		<enclosure url="https://example.com/download/1.torrent" length="1073741824" type="application/x-bittorrent"/>
+	</item>
+	<item>
+		<title>Magnet only</title>
+		<torrent:magnetURI>magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567</torrent:magnetURI>
+		<torrent:contentLength>2048</torrent:contentLength>
+	</item>
+	<item>
+		<title>Nyaa item</title>
+		<link>https://nyaa.example/download/3.torrent</link>
+		<nyaa:size>1.5 GiB</nyaa:size>
+	</item>
+	<item>
+		<title>No link</title>
+	</item>
+</channel>
+</rss>`,
+			want: []Item{
+				{GUID: "item-1", Title: "Show S01E02 1080p", Link: "https://example.com/download/1.torrent", Size: 1 << 30},
+				{
+					GUID:  "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567",
+					Title: "Magnet only",
+					Link:  "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567",
+					Size:  2048,
+				},
+				{GUID: "https://nyaa.example/download/3.torrent", Title: "Nyaa item", Link: "https://nyaa.example/download/3.torrent", Size: 3 << 29},
+			},
+		},
+		{
+			name: "rss 1.0",
+			feed: `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/">
+	<channel><title>tracker</title></channel>
+	<item>
+		<title>RDF item</title>
+		<link>https://example.com/rdf/1.torrent</link>
+	</item>
+</rdf:RDF>`,
+			want: []Item{
+				{GUID: "https://example.com/rdf/1.torrent", Title: "RDF item", Link: "https://example.com/rdf/1.torrent"},
+			},
+		},
+		{
+			name: "atom",
+			feed: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>tracker</title>
+	<entry>
+		<title>Atom item</title>
+		<id>urn:uuid:1</id>
+		<link rel="alternate" href="https://example.com/details/1"/>
+		<link rel="enclosure" href="https://example.com/atom/1.torrent" length="4096"/>
+	</entry>
+	<entry>
+		<title>Alternate only</title>
+		<link href="https://example.com/atom/2.torrent"/>
+	</entry>
+</feed>`,
+			want: []Item{
+				{GUID: "urn:uuid:1", Title: "Atom item", Link: "https://example.com/atom/1.torrent", Size: 4096},
+				{GUID: "https://example.com/atom/2.torrent", Title: "Alternate only", Link: "https://example.com/atom/2.torrent"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, err := Parse([]byte(test.feed))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(items) != len(test.want) {
+				t.Fatalf("Parse() returned %d items, want %d: %+v", len(items), len(test.want), items)
+			}
+			for i, want := range test.want {
+				got := items[i]
+				got.Published = want.Published
+				if got != want {
+					t.Errorf("item %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseUnsupported(t *testing.T) {
+	_, err := Parse([]byte(`<html><body>not a feed</body></html>`))
+	if !errors.Is(err, ErrUnsupportedFeed) {
+		t.Fatalf("Parse() error = %v, want %v", err, ErrUnsupportedFeed)
+	}
+}
+
+func TestParsePublished(t *testing.T) {
+	items, err := Parse([]byte(`<rss><channel><item><title>a</title><link>https://example.com/a</link>
+<pubDate>Mon, 02 Jan 2006 15:04:05 +0000</pubDate></item></channel></rss>`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(items) != 1 || items[0].Published.Unix() != 1136214245 {
+		t.Fatalf("Parse() = %+v, want one item published at 1136214245", items)
+	}
+}
+
+func TestEpisode(t *testing.T) {
+	tests := []struct {
+		title           string
+		season, episode int
+		ok              bool
+	}{
+		{"Show.S01E02.1080p", 1, 2, true},
+		{"Show s2e10", 2, 10, true},
+		{"Show 3x04 720p", 3, 4, true},
+		{"Movie 2021 1080p", 0, 0, false},
+	}
+	for _, test := range tests {
+		season, episode, ok := Episode(test.title)
+		if season != test.season || episode != test.episode || ok != test.ok {
+			t.Errorf("Episode(%q) = %d, %d, %v, want %d, %d, %v", test.title, season, episode, ok, test.season, test.episode, test.ok)
+		}
+	}
+}
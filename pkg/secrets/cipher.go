@@ -0,0 +1,125 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// encryptedPrefix 加密值的前缀，不带前缀的值视为明文
+const encryptedPrefix = "enc:v1:"
+
+// KeySize 主密钥长度（AES-256）
+const KeySize = 32
+
+// ErrNoKey 存在加密值但未配置主密钥
+var ErrNoKey = errors.New("value is encrypted but no master key is configured")
+
+// Cipher 使用主密钥对敏感字段进行 AES-256-GCM 认证加密
+// nil Cipher 表示未配置主密钥：加密时原样返回明文，解密加密值时返回 ErrNoKey
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher 使用 32 字节的主密钥创建加密器
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// LoadKey 从环境变量的值或密钥文件读取主密钥，两者都为空时返回 nil
+// 密钥为 base64 或十六进制编码的 32 字节，文件内容首尾的空白会被忽略
+func LoadKey(value, file string) ([]byte, error) {
+	if value != "" && file != "" {
+		return nil, errors.New("master key and master key file are both set")
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read master key file: %w", err)
+		}
+		value = string(data)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	return ParseKey(value)
+}
+
+// ParseKey 解析 base64 或十六进制编码的主密钥
+func ParseKey(value string) ([]byte, error) {
+	if len(value) == hex.EncodedLen(KeySize) {
+		if key, err := hex.DecodeString(value); err == nil {
+			return key, nil
+		}
+	}
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("master key must be base64 or hex encoded")
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// IsEncrypted 判断值是否为加密格式
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// Encrypt 加密明文，associated 为绑定的关联数据（如客户端 ID），解密时必须一致
+// 空字符串与已加密的值原样返回
+func (c *Cipher) Encrypt(plaintext, associated string) (string, error) {
+	if c == nil || plaintext == "" || IsEncrypted(plaintext) {
+		return plaintext, nil
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), []byte(associated))
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密 Encrypt 生成的值，明文值原样返回
+func (c *Cipher) Decrypt(value, associated string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if c == nil {
+		return "", ErrNoKey
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("malformed encrypted value: too short")
+	}
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(associated))
+	if err != nil {
+		return "", errors.New("failed to decrypt value: wrong master key or corrupted data")
+	}
+	return string(plaintext), nil
+}
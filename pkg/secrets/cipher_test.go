@@ -0,0 +1,152 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestCipher(t *testing.T, fill byte) *Cipher {
+	t.Helper()
+	c, err := NewCipher(bytes.Repeat([]byte{fill}, KeySize))
+	if err != nil {
+		t.Fatalf("NewCipher() error = %v", err)
+	}
+	return c
+}
+
+func TestCipherRoundTrip(t *testing.T) {
+	c := newTestCipher(t, 1)
+	tests := []struct {
+		name      string
+		plaintext string
+		encrypted bool
+	}{
+		{"password", "hunter2", true},
+		{"unicode", "密码 🔑", true},
+		{"empty stays empty", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := c.Encrypt(test.plaintext, "qb-1")
+			if err != nil {
+				t.Fatalf("Encrypt() error = %v", err)
+			}
+			if IsEncrypted(value) != test.encrypted {
+				t.Fatalf("Encrypt() = %q, want encrypted %t", value, test.encrypted)
+			}
+			if again, _ := c.Encrypt(value, "qb-1"); again != value {
+				t.Errorf("Encrypt(encrypted value) = %q, want it unchanged", again)
+			}
+			got, err := c.Decrypt(value, "qb-1")
+			if err != nil || got != test.plaintext {
+				t.Errorf("Decrypt() = %q, %v, want %q", got, err, test.plaintext)
+			}
+		})
+	}
+}
+
+func TestCipherDecryptFailures(t *testing.T) {
+	c := newTestCipher(t, 1)
+	value, err := c.Encrypt("hunter2", "qb-1")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	tampered := []byte(value)
+	tampered[len(tampered)-2] ^= 1
+
+	tests := []struct {
+		name       string
+		cipher     *Cipher
+		value      string
+		associated string
+		wantErr    error
+	}{
+		{"wrong client", c, value, "tr-1", nil},
+		{"wrong key", newTestCipher(t, 2), value, "qb-1", nil},
+		{"tampered", c, string(tampered), "qb-1", nil},
+		{"malformed", c, encryptedPrefix + "!!!", "qb-1", nil},
+		{"too short", c, encryptedPrefix + base64.StdEncoding.EncodeToString([]byte("x")), "qb-1", nil},
+		{"no key", nil, value, "qb-1", ErrNoKey},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.cipher.Decrypt(test.value, test.associated)
+			if err == nil {
+				t.Fatal("Decrypt() succeeded, want error")
+			}
+			if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+				t.Errorf("Decrypt() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestNilCipher(t *testing.T) {
+	var c *Cipher
+	if value, err := c.Encrypt("hunter2", "qb-1"); err != nil || value != "hunter2" {
+		t.Errorf("nil Encrypt() = %q, %v, want plaintext", value, err)
+	}
+	if value, err := c.Decrypt("hunter2", "qb-1"); err != nil || value != "hunter2" {
+		t.Errorf("nil Decrypt(plaintext) = %q, %v, want plaintext", value, err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key := bytes.Repeat([]byte{0xab}, KeySize)
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"hex", hex.EncodeToString(key), false},
+		{"base64", base64.StdEncoding.EncodeToString(key), false},
+		{"short base64", base64.StdEncoding.EncodeToString(key[:16]), true},
+		{"not encoded", "not a key", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseKey(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseKey() error = %v, wantErr %t", err, test.wantErr)
+			}
+			if !test.wantErr && !bytes.Equal(got, key) {
+				t.Errorf("ParseKey() = %x, want %x", got, key)
+			}
+		})
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	encoded := hex.EncodeToString(bytes.Repeat([]byte{0xab}, KeySize))
+	file := filepath.Join(t.TempDir(), "master.key")
+	if err := os.WriteFile(file, []byte(encoded+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		file    string
+		wantKey bool
+		wantErr bool
+	}{
+		{"not configured", "", "", false, false},
+		{"value", encoded, "", true, false},
+		{"file with trailing newline", "", file, true, false},
+		{"both set", encoded, file, false, true},
+		{"missing file", "", filepath.Join(t.TempDir(), "missing"), false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := LoadKey(test.value, test.file)
+			if (err != nil) != test.wantErr || (key != nil) != test.wantKey {
+				t.Errorf("LoadKey() = %x, %v, want key %t, error %t", key, err, test.wantKey, test.wantErr)
+			}
+		})
+	}
+}